@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// ToolCall is a call of a batch passed to CallToolsBatch
+type ToolCall struct {
+	Name      string
+	Arguments map[string]interface{}
+}
+
+// ToolResult is the outcome of a call of a batch, exactly one of Result and Err is set
+type ToolResult struct {
+	Result *protocol.CallToolResult
+	Err    error
+}
+
+// WithMaxBatchInFlight bounds the number of calls of a CallToolsBatch sent to the server at once,
+// the other calls wait for one of them to complete. 0 means no limit, the default is 10.
+func WithMaxBatchInFlight(n int) Option {
+	return func(s *Client) {
+		s.maxBatchInFlight = n
+	}
+}
+
+// CallToolsBatch calls the tools concurrently and returns their outcomes in the order of calls. A failing call
+// is reported in its result without failing the others, calls not yet sent when ctx is done fail with its error.
+// The batch itself only fails if the server doesn't support tools.
+func (client *Client) CallToolsBatch(ctx context.Context, calls []ToolCall) ([]ToolResult, error) {
+	if client.serverCapabilities.Tools == nil {
+		return nil, pkg.ErrServerNotSupport
+	}
+
+	var slots chan struct{}
+	if client.maxBatchInFlight > 0 {
+		slots = make(chan struct{}, client.maxBatchInFlight)
+	}
+
+	results := make([]ToolResult, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = ToolResult{Err: ctx.Err()}
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, call ToolCall) {
+			defer pkg.RecoverWithLogger(client.logger, nil)
+			defer wg.Done()
+			if slots != nil {
+				defer func() { <-slots }()
+			}
+
+			result, err := client.CallTool(ctx, protocol.NewCallToolRequest(call.Name, call.Arguments))
+			results[i] = ToolResult{Result: result, Err: err}
+		}(i, call)
+	}
+	wg.Wait()
+	return results, nil
+}
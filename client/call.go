@@ -5,8 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
-	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -58,6 +57,16 @@ func (client *Client) Ping(ctx context.Context, request *protocol.PingRequest) (
 	return &result, nil
 }
 
+// PingLatency pings the server and returns the round-trip time, e.g. for health dashboards.
+// Like Ping it can be used before the initialization handshake.
+func (client *Client) PingLatency(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if _, err := client.Ping(ctx, protocol.NewPingRequest()); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
 func (client *Client) ListPrompts(ctx context.Context) (*protocol.ListPromptsResult, error) {
 	if client.serverCapabilities.Prompts == nil {
 		return nil, pkg.ErrServerNotSupport
@@ -245,6 +254,42 @@ func (client *Client) CallToolWithProgressChan(ctx context.Context, request *pro
 	return client.CallTool(ctx, request)
 }
 
+// Complete asks the server for completions of a prompt argument or a resource template variable
+func (client *Client) Complete(ctx context.Context, request *protocol.CompleteRequest) (*protocol.CompleteResult, error) {
+	if client.serverCapabilities.Completions == nil {
+		return nil, pkg.ErrServerNotSupport
+	}
+
+	response, err := client.callServer(ctx, protocol.CompletionComplete, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var result protocol.CompleteResult
+	if err := pkg.JSONUnmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &result, nil
+}
+
+// SetRoots replaces the roots served by roots/list and notifies the server that they changed, so that it lists
+// them again. The roots capability must have been declared with WithRoots.
+func (client *Client) SetRoots(ctx context.Context, roots ...*protocol.Root) error {
+	if client.clientCapabilities.Roots == nil {
+		return fmt.Errorf("%w: declare the roots capability with WithRoots", pkg.ErrClientNotSupport)
+	}
+
+	client.rootsMu.Lock()
+	client.roots = roots
+	client.rootsMu.Unlock()
+
+	if !client.ready.Load() {
+		// the server lists the roots once initialized
+		return nil
+	}
+	return client.sendMsgWithNotification(ctx, protocol.NotificationRootsListChanged, protocol.NewRootsListChangedNotification())
+}
+
 func (client *Client) sendNotification4Initialized(ctx context.Context) error {
 	return client.sendMsgWithNotification(ctx, protocol.NotificationInitialized, protocol.NewInitializedNotification())
 }
@@ -259,10 +304,16 @@ func (client *Client) callServer(ctx context.Context, method protocol.Method, pa
 		return nil, errors.New("callServer: client not ready")
 	}
 
-	requestID := strconv.FormatInt(atomic.AddInt64(&client.requestID, 1), 10)
+	requestID := client.genRequestID()
+	key, ok := requestIDKey(requestID)
+	if !ok {
+		return nil, fmt.Errorf("callServer: request id %v of type %T is neither a string nor an integer", requestID, requestID)
+	}
 	respChan := make(chan *protocol.JSONRPCResponse, 1)
-	client.reqID2respChan.Set(requestID, respChan)
-	defer client.reqID2respChan.Remove(requestID)
+	if !client.reqID2respChan.SetIfAbsent(key, respChan) {
+		return nil, fmt.Errorf("callServer: request id %v is already in flight", requestID)
+	}
+	defer client.reqID2respChan.Remove(key)
 
 	if err := client.sendMsgWithRequest(ctx, requestID, method, params); err != nil {
 		return nil, fmt.Errorf("callServer: %w", err)
@@ -3,7 +3,9 @@ package client
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	cmap "github.com/orcaman/concurrent-map/v2"
@@ -27,6 +29,21 @@ func WithSamplingHandler(handler SamplingHandler) Option {
 	}
 }
 
+func WithElicitationHandler(handler ElicitationHandler) Option {
+	return func(s *Client) {
+		s.elicitationHandler = handler
+	}
+}
+
+// WithRoots declares the roots capability and serves roots/list with the given roots,
+// e.g. the directories filesystem tools may operate within. They can be replaced later with SetRoots.
+func WithRoots(roots ...*protocol.Root) Option {
+	return func(s *Client) {
+		s.roots = roots
+		s.clientCapabilities.Roots = &protocol.RootsCapability{ListChanged: true}
+	}
+}
+
 func WithClientInfo(info *protocol.Implementation) Option {
 	return func(s *Client) {
 		s.clientInfo = info
@@ -50,14 +67,28 @@ type Client struct {
 
 	reqID2respChan cmap.ConcurrentMap[string, chan *protocol.JSONRPCResponse]
 
+	serverReqID2cancelFunc cmap.ConcurrentMap[string, context.CancelFunc]
+
 	progressChanRW           sync.RWMutex
 	progressToken2notifyChan map[string]chan<- *protocol.ProgressNotification
 
 	samplingHandler SamplingHandler
 
+	elicitationHandler ElicitationHandler
+
+	rootsMu sync.RWMutex
+	roots   []*protocol.Root
+
 	notifyHandler NotifyHandler
 
-	requestID int64
+	notificationMu             sync.RWMutex
+	notificationHandlers       map[string]NotificationHandlerFunc
+	unknownNotificationHandler UnknownNotificationHandlerFunc
+	notificationWorkerOnce     sync.Once
+	notificationQueue          chan func() // handlers of received notifications, consumed by a single worker
+
+	requestID    int64
+	genRequestID func() interface{}
 
 	ready            *pkg.AtomicBool
 	initializationMu sync.Mutex
@@ -71,6 +102,8 @@ type Client struct {
 
 	initTimeout time.Duration
 
+	maxBatchInFlight int
+
 	closed chan struct{}
 
 	logger pkg.Logger
@@ -80,11 +113,14 @@ func NewClient(t transport.ClientTransport, opts ...Option) (*Client, error) {
 	client := &Client{
 		transport:                t,
 		reqID2respChan:           cmap.New[chan *protocol.JSONRPCResponse](),
+		serverReqID2cancelFunc:   cmap.New[context.CancelFunc](),
 		progressToken2notifyChan: make(map[string]chan<- *protocol.ProgressNotification),
+		notificationHandlers:     make(map[string]NotificationHandlerFunc),
 		ready:                    pkg.NewAtomicBool(),
 		clientInfo:               &protocol.Implementation{},
 		clientCapabilities:       &protocol.ClientCapabilities{},
 		initTimeout:              time.Second * 30,
+		maxBatchInFlight:         10,
 		closed:                   make(chan struct{}),
 		logger:                   pkg.DefaultLogger,
 	}
@@ -94,6 +130,12 @@ func NewClient(t transport.ClientTransport, opts ...Option) (*Client, error) {
 		opt(client)
 	}
 
+	if client.genRequestID == nil {
+		client.genRequestID = func() interface{} {
+			return strconv.FormatInt(atomic.AddInt64(&client.requestID, 1), 10)
+		}
+	}
+
 	if client.notifyHandler == nil {
 		h := NewBaseNotifyHandler()
 		h.Logger = client.logger
@@ -104,6 +146,10 @@ func NewClient(t transport.ClientTransport, opts ...Option) (*Client, error) {
 		client.clientCapabilities.Sampling = struct{}{}
 	}
 
+	if client.elicitationHandler != nil {
+		client.clientCapabilities.Elicitation = struct{}{}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), client.initTimeout)
 	defer cancel()
 
@@ -116,7 +162,7 @@ func NewClient(t transport.ClientTransport, opts ...Option) (*Client, error) {
 	}
 
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(client.logger, nil)
 
 		ticker := time.NewTicker(time.Minute)
 		defer ticker.Stop()
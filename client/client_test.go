@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
@@ -215,7 +217,7 @@ func TestClientCall(t *testing.T) {
 	}
 }
 
-func testClientInit(t *testing.T, in io.ReadWriteCloser, out io.ReadWriter, outScan *bufio.Scanner) *Client {
+func testClientInit(t *testing.T, in io.ReadWriteCloser, out io.ReadWriter, outScan *bufio.Scanner, opts ...Option) *Client {
 	req := protocol.InitializeRequest{
 		ClientInfo: &protocol.Implementation{
 			Name:    "test_client",
@@ -306,10 +308,88 @@ func testClientInit(t *testing.T, in io.ReadWriteCloser, out io.ReadWriter, outS
 		ch <- struct{}{}
 	}()
 
-	client, err := NewClient(transport.NewMockClientTransport(in, out), WithClientInfo(req.ClientInfo))
+	client, err := NewClient(transport.NewMockClientTransport(in, out), append([]Option{WithClientInfo(req.ClientInfo)}, opts...)...)
 	if err != nil {
 		t.Fatalf("NewServer: %+v", err)
 	}
 	<-ch
 	return client
 }
+
+func TestUnexpectedResponseID(t *testing.T) {
+	reader1, writer1 := io.Pipe()
+	reader2, writer2 := io.Pipe()
+
+	var (
+		in io.ReadWriteCloser = struct {
+			io.Reader
+			io.Writer
+			io.Closer
+		}{
+			Reader: reader1,
+			Writer: writer1,
+			Closer: reader1,
+		}
+
+		out io.ReadWriter = struct {
+			io.Reader
+			io.Writer
+		}{
+			Reader: reader2,
+			Writer: writer2,
+		}
+
+		outScan = bufio.NewScanner(out)
+	)
+
+	var nextID int64 = 100
+	client := testClientInit(t, in, out, outScan, WithIDGenerator(func() interface{} {
+		return atomic.AddInt64(&nextID, 1)
+	}))
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.Ping(context.Background(), protocol.NewPingRequest())
+		errCh <- err
+	}()
+
+	if !outScan.Scan() {
+		t.Fatalf("outScan: %+v", outScan.Err())
+	}
+	jsonrpcReq := &protocol.JSONRPCRequest{}
+	if err := pkg.JSONUnmarshal(outScan.Bytes(), &jsonrpcReq); err != nil {
+		t.Fatalf("Json Unmarshal: %+v", err)
+	}
+	if jsonrpcReq.ID != float64(102) {
+		t.Fatalf("expected the generated numeric id 102, got %v (%T)", jsonrpcReq.ID, jsonrpcReq.ID)
+	}
+
+	writeResponse := func(id protocol.RequestID) {
+		respBytes, err := json.Marshal(protocol.NewJSONRPCSuccessResponse(id, protocol.NewPingResult()))
+		if err != nil {
+			t.Fatalf("Json Marshal: %+v", err)
+		}
+		if _, err = in.Write(append(respBytes, "\n"...)); err != nil {
+			t.Fatalf("in Write: %+v", err)
+		}
+	}
+
+	// neither a string id formatted like the pending one nor an id never sent is delivered
+	writeResponse("102")
+	writeResponse(999)
+	select {
+	case err := <-errCh:
+		t.Fatalf("expected the ping to still wait for its response, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	writeResponse(102)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("Ping: %+v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the ping response")
+	}
+}
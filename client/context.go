@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+)
+
+type rootCallIDKey struct{}
+
+func setRootCallIDToCtx(ctx context.Context, rootCallID string) context.Context {
+	return context.WithValue(ctx, rootCallIDKey{}, rootCallID)
+}
+
+// RootCallID returns the root call id of the server request being handled, e.g. in a sampling handler.
+// Requests made to the server with the same ctx carry it in _meta, so the server correlates them with the chain.
+func RootCallID(ctx context.Context) (string, bool) {
+	rootCallID, ok := ctx.Value(rootCallIDKey{}).(string)
+	return rootCallID, ok
+}
@@ -14,6 +14,15 @@ func (client *Client) handleRequestWithPing() (*protocol.PingResult, error) {
 	return protocol.NewPingResult(), nil
 }
 
+func (client *Client) handleRequestWithListRoots() (*protocol.ListRootsResult, error) {
+	if client.clientCapabilities.Roots == nil {
+		return nil, pkg.ErrClientNotSupport
+	}
+	client.rootsMu.RLock()
+	defer client.rootsMu.RUnlock()
+	return protocol.NewListRootsResult(client.roots), nil
+}
+
 func (client *Client) handleRequestWithCreateMessagesSampling(ctx context.Context, rawParams json.RawMessage) (*protocol.CreateMessageResult, error) {
 	if client.clientCapabilities.Sampling == nil {
 		return nil, pkg.ErrClientNotSupport
@@ -27,6 +36,19 @@ func (client *Client) handleRequestWithCreateMessagesSampling(ctx context.Contex
 	return client.samplingHandler.CreateMessage(ctx, request)
 }
 
+func (client *Client) handleRequestWithElicit(ctx context.Context, rawParams json.RawMessage) (*protocol.ElicitResult, error) {
+	if client.clientCapabilities.Elicitation == nil {
+		return nil, pkg.ErrClientNotSupport
+	}
+
+	var request *protocol.ElicitRequest
+	if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
+		return nil, err
+	}
+
+	return client.elicitationHandler.Elicit(ctx, request)
+}
+
 func (client *Client) handleNotifyWithToolsListChanged(ctx context.Context, rawParams json.RawMessage) error {
 	notify := &protocol.ToolListChangedNotification{}
 	if len(rawParams) > 0 {
@@ -67,6 +89,30 @@ func (client *Client) handleNotifyWithResourcesUpdated(ctx context.Context, rawP
 	return client.notifyHandler.ResourcesUpdated(ctx, notify)
 }
 
+func (client *Client) handleNotifyWithClientConfig(ctx context.Context, rawParams json.RawMessage) error {
+	notify := &protocol.ClientConfigNotification{}
+	if len(rawParams) > 0 {
+		if err := pkg.JSONUnmarshal(rawParams, notify); err != nil {
+			return err
+		}
+	}
+	return client.notifyHandler.ClientConfig(ctx, notify)
+}
+
+func (client *Client) handleNotifyWithCancelled(rawParams json.RawMessage) error {
+	var params protocol.CancelledNotification
+	if err := pkg.JSONUnmarshal(rawParams, &params); err != nil {
+		return err
+	}
+
+	cancel, ok := client.serverReqID2cancelFunc.Get(fmt.Sprint(params.RequestID))
+	if !ok {
+		return nil
+	}
+	cancel()
+	return nil
+}
+
 func (client *Client) handleNotifyWithProgress(ctx context.Context, rawParams json.RawMessage) error {
 	notify := &protocol.ProgressNotification{}
 	if len(rawParams) > 0 {
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// notificationQueueSize bounds the notifications waiting for their handlers, further notifications are dropped
+const notificationQueueSize = 64
+
+// NotificationHandlerFunc handles the params of a server notification
+type NotificationHandlerFunc func(params json.RawMessage)
+
+// UnknownNotificationHandlerFunc handles a server notification no handler is registered for
+type UnknownNotificationHandlerFunc func(method string, params json.RawMessage)
+
+// OnNotification registers the handler of the server notifications with the given method, e.g. "notifications/message",
+// replacing the previous one. Handlers run one at a time in arrival order on a worker goroutine, so a slow handler
+// never blocks the read loop, but delays the following notifications. Registered handlers run in addition to the NotifyHandler.
+func (client *Client) OnNotification(method string, handler NotificationHandlerFunc) {
+	client.notificationMu.Lock()
+	defer client.notificationMu.Unlock()
+
+	client.notificationHandlers[method] = handler
+	client.startNotificationWorker()
+}
+
+// OnUnknownNotification registers the handler of the server notifications whose method neither the client nor
+// OnNotification handles, instead of dropping them with an error log
+func (client *Client) OnUnknownNotification(handler UnknownNotificationHandlerFunc) {
+	client.notificationMu.Lock()
+	defer client.notificationMu.Unlock()
+
+	client.unknownNotificationHandler = handler
+	client.startNotificationWorker()
+}
+
+func (client *Client) startNotificationWorker() {
+	client.notificationWorkerOnce.Do(func() {
+		client.notificationQueue = make(chan func(), notificationQueueSize)
+		go func() {
+			for {
+				select {
+				case <-client.closed:
+					return
+				case handle := <-client.notificationQueue:
+					func() {
+						defer pkg.RecoverWithLogger(client.logger, nil)
+						handle()
+					}()
+				}
+			}
+		}()
+	})
+}
+
+// dispatchNotification queues the registered handler of the notification and reports whether there was one
+func (client *Client) dispatchNotification(notify *protocol.JSONRPCNotification) bool {
+	method := string(notify.Method)
+
+	client.notificationMu.RLock()
+	handler, ok := client.notificationHandlers[method]
+	unknownHandler := client.unknownNotificationHandler
+	client.notificationMu.RUnlock()
+
+	var handle func()
+	switch {
+	case ok:
+		handle = func() { handler(notify.RawParams) }
+	case unknownHandler != nil && !isBuiltinNotification(notify.Method):
+		handle = func() { unknownHandler(method, notify.RawParams) }
+	default:
+		return false
+	}
+
+	select {
+	case client.notificationQueue <- handle:
+	default:
+		client.logger.Warnf("notification queue is full, drop notification: method=%s", method)
+	}
+	return true
+}
+
+func isBuiltinNotification(method protocol.Method) bool {
+	switch method {
+	case protocol.NotificationToolsListChanged,
+		protocol.NotificationPromptsListChanged,
+		protocol.NotificationResourcesListChanged,
+		protocol.NotificationResourcesUpdated,
+		protocol.NotificationProgress,
+		protocol.NotificationClientConfig,
+		protocol.NotificationCancelled:
+		return true
+	default:
+		return false
+	}
+}
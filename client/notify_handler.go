@@ -12,6 +12,10 @@ type SamplingHandler interface {
 	CreateMessage(ctx context.Context, request *protocol.CreateMessageRequest) (*protocol.CreateMessageResult, error)
 }
 
+type ElicitationHandler interface {
+	Elicit(ctx context.Context, request *protocol.ElicitRequest) (*protocol.ElicitResult, error)
+}
+
 // NotifyHandler
 // When implementing a custom NotifyHandler, you can combine it with BaseNotifyHandler to implement it on demand without implementing extra methods.
 type NotifyHandler interface {
@@ -19,6 +23,7 @@ type NotifyHandler interface {
 	PromptListChanged(ctx context.Context, request *protocol.PromptListChangedNotification) error
 	ResourceListChanged(ctx context.Context, request *protocol.ResourceListChangedNotification) error
 	ResourcesUpdated(ctx context.Context, request *protocol.ResourceUpdatedNotification) error
+	ClientConfig(ctx context.Context, request *protocol.ClientConfigNotification) error
 }
 
 type BaseNotifyHandler struct {
@@ -45,6 +50,10 @@ func (handler *BaseNotifyHandler) ResourcesUpdated(_ context.Context, request *p
 	return handler.defaultNotifyHandler(protocol.NotificationResourcesUpdated, request)
 }
 
+func (handler *BaseNotifyHandler) ClientConfig(_ context.Context, request *protocol.ClientConfigNotification) error {
+	return handler.defaultNotifyHandler(protocol.NotificationClientConfig, request)
+}
+
 func (handler *BaseNotifyHandler) defaultNotifyHandler(method protocol.Method, notify interface{}) error {
 	b, err := json.Marshal(notify)
 	if err != nil {
@@ -12,7 +12,7 @@ import (
 )
 
 func (client *Client) receive(ctx context.Context, msg []byte) error {
-	defer pkg.Recover()
+	defer pkg.RecoverWithLogger(client.logger, nil)
 
 	ctx = pkg.NewCancelShieldContext(ctx)
 
@@ -21,6 +21,9 @@ func (client *Client) receive(ctx context.Context, msg []byte) error {
 		if err := pkg.JSONUnmarshal(msg, &notify); err != nil {
 			return err
 		}
+		if client.dispatchNotification(notify) && !isBuiltinNotification(notify.Method) {
+			return nil
+		}
 		if notify.Method == protocol.NotificationProgress { // need sync handle
 			if err := client.receiveNotify(ctx, notify); err != nil {
 				notify.RawParams = nil // simplified log
@@ -30,7 +33,7 @@ func (client *Client) receive(ctx context.Context, msg []byte) error {
 			return nil
 		}
 		go func() {
-			defer pkg.Recover()
+			defer pkg.RecoverWithLogger(client.logger, nil)
 
 			if err := client.receiveNotify(ctx, notify); err != nil {
 				notify.RawParams = nil // simplified log
@@ -62,8 +65,16 @@ func (client *Client) receive(ctx context.Context, msg []byte) error {
 	if !req.IsValid() {
 		return pkg.ErrRequestInvalid
 	}
+	if r := gjson.GetBytes(req.RawParams, fmt.Sprintf("_meta.%s", protocol.RootCallIDKey)); r.Exists() {
+		ctx = setRootCallIDToCtx(ctx, r.String())
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	requestID := fmt.Sprint(req.ID)
+	client.serverReqID2cancelFunc.Set(requestID, cancel)
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(client.logger, nil)
+		defer client.serverReqID2cancelFunc.Remove(requestID)
+		defer cancel()
 
 		if err := client.receiveRequest(ctx, req); err != nil {
 			req.RawParams = nil // simplified log
@@ -83,14 +94,21 @@ func (client *Client) receiveRequest(ctx context.Context, request *protocol.JSON
 	switch request.Method {
 	case protocol.Ping:
 		result, err = client.handleRequestWithPing()
-	// case protocol.RootsList:
-	// 	result, err = client.handleRequestWithListRoots(ctx, request.RawParams)
+	case protocol.RootsList:
+		result, err = client.handleRequestWithListRoots()
 	case protocol.SamplingCreateMessage:
 		result, err = client.handleRequestWithCreateMessagesSampling(ctx, request.RawParams)
+	case protocol.ElicitationCreate:
+		result, err = client.handleRequestWithElicit(ctx, request.RawParams)
 	default:
 		err = fmt.Errorf("%w: method=%s", pkg.ErrMethodNotSupport, request.Method)
 	}
 
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// the server cancelled the request and no longer waits for the response
+		return nil
+	}
+
 	if err != nil {
 		switch {
 		case errors.Is(err, pkg.ErrMethodNotSupport):
@@ -118,15 +136,28 @@ func (client *Client) receiveNotify(ctx context.Context, notify *protocol.JSONRP
 		return client.handleNotifyWithResourcesUpdated(ctx, notify.RawParams)
 	case protocol.NotificationProgress:
 		return client.handleNotifyWithProgress(ctx, notify.RawParams)
+	case protocol.NotificationClientConfig:
+		return client.handleNotifyWithClientConfig(ctx, notify.RawParams)
+	case protocol.NotificationServerDraining:
+		// only of interest to clients reconnecting elsewhere, see Client.OnNotification
+		return nil
+	case protocol.NotificationCancelled:
+		return client.handleNotifyWithCancelled(notify.RawParams)
 	default:
 		return fmt.Errorf("%w: method=%s", pkg.ErrMethodNotSupport, notify.Method)
 	}
 }
 
 func (client *Client) receiveResponse(response *protocol.JSONRPCResponse) error {
-	respChan, ok := client.reqID2respChan.Get(fmt.Sprint(response.ID))
+	var respChan chan *protocol.JSONRPCResponse
+	key, ok := requestIDKey(response.ID)
+	if ok {
+		respChan, ok = client.reqID2respChan.Get(key)
+	}
 	if !ok {
-		return fmt.Errorf("%w: requestID=%+v", pkg.ErrLackResponseChan, response.ID)
+		// a response to a request the client never sent or that already returned, e.g. sent by a buggy server
+		client.logger.Warnf("ignore response with unknown request id %v (%T)", response.ID, response.ID)
+		return nil
 	}
 
 	select {
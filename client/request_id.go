@@ -0,0 +1,51 @@
+package client
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// WithIDGenerator sets the generator of the IDs of the requests the client sends, the spec allows string
+// and integer IDs. It is called concurrently and must not return the ID of a request still in flight,
+// a call with a repeated ID fails. IDs are sequential decimal strings by default.
+func WithIDGenerator(generate func() interface{}) Option {
+	return func(s *Client) {
+		s.genRequestID = generate
+	}
+}
+
+// requestIDKey returns the key a request with id waits for its response under, false if id is neither a string
+// nor an integer. String and numeric IDs are told apart, so that e.g. a response with the ID 1 is not delivered
+// to the request with the ID "1".
+func requestIDKey(id protocol.RequestID) (string, bool) {
+	switch v := id.(type) {
+	case string:
+		return "s:" + v, true
+	case int:
+		return "n:" + strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return "n:" + strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return "n:" + strconv.FormatInt(v, 10), true
+	case uint32:
+		return "n:" + strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return "n:" + strconv.FormatUint(v, 10), true
+	case float64: // numeric IDs of decoded responses
+		if v != math.Trunc(v) || math.IsInf(v, 0) {
+			return "", false
+		}
+		return "n:" + strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return "n:" + strconv.FormatInt(i, 10), true
+		}
+		if f, err := v.Float64(); err == nil {
+			return requestIDKey(f)
+		}
+	}
+	return "", false
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
 )
@@ -16,6 +18,19 @@ func (client *Client) sendMsgWithRequest(ctx context.Context, requestID protocol
 	}
 
 	req := protocol.NewJSONRPCRequest(requestID, method, params)
+	if rootCallID, ok := RootCallID(ctx); ok {
+		var err error
+		if req.Params, err = pkg.JSONWithMeta(req.Params, protocol.RootCallIDKey, rootCallID); err != nil {
+			return err
+		}
+	}
+	// the server span of the request links to the span of the caller
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		var err error
+		if req.Params, err = pkg.JSONWithMeta(req.Params, protocol.TraceParentKey, pkg.TraceParentFromSpanContext(sc).String()); err != nil {
+			return err
+		}
+	}
 
 	message, err := json.Marshal(req)
 	if err != nil {
@@ -71,19 +71,17 @@ func MetricsMiddleware() server.ToolMiddleware {
 	}
 }
 
-// PanicRecoveryMiddleware returns a panic recovery middleware
-func PanicRecoveryMiddleware() server.ToolMiddleware {
-	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
-		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Printf("[Middleware] Recovered from panic in tool %s: %v", req.Name, r)
-				}
-			}()
+// MetricsObserver observes every request the server dispatches, not just tool calls
+type MetricsObserver struct {
+	server.BaseObserver
+}
 
-			return next(ctx, req)
-		}
+func (MetricsObserver) OnRequestEnd(_ context.Context, method protocol.Method, _ protocol.RequestID, err error, duration time.Duration) {
+	status := "success"
+	if err != nil {
+		status = "error"
 	}
+	log.Printf("[Observer] Metric: method=%s, status=%s, duration=%v", method, status, duration)
 }
 
 // HelloWorldHandler is a sample tool handler
@@ -106,16 +104,15 @@ func HelloWorldHandler(_ context.Context, req *protocol.CallToolRequest) (*proto
 
 // CounterHandler is another sample tool handler
 func CounterHandler(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
-	count := 1
-	if req.Arguments != nil {
-		if c, ok := req.Arguments["count"]; ok {
-			if cInt, ok := c.(float64); ok { // JSON numbers are float64
-				count = int(cInt)
-			}
-		}
+	args := struct {
+		Count int `json:"count"`
+	}{Count: 1}
+	// numeric strings and integral floats are coerced to int
+	if err := req.BindArguments(&args); err != nil {
+		return nil, err
 	}
 
-	message := fmt.Sprintf("Counter value: %d", count)
+	message := fmt.Sprintf("Counter value: %d", args.Count)
 	return protocol.NewCallToolResult([]protocol.Content{
 		&protocol.TextContent{
 			Type: "text",
@@ -126,14 +123,23 @@ func CounterHandler(_ context.Context, req *protocol.CallToolRequest) (*protocol
 
 func main() {
 	stdio := transport.NewStdioServerTransport()
-	mcpServer, err := server.NewServer(stdio)
+	// WithPanicPolicy answers tool calls that panic in handlers and middlewares with an isError result
+	// WithObserver feeds every dispatched request to the metrics observer
+	mcpServer, err := server.NewServer(stdio,
+		server.WithPanicPolicy(server.PanicPolicy{
+			Mode: server.PanicModeToolErrorResult,
+			Report: func(recovered interface{}, _ []byte) {
+				log.Printf("tool panicked: %v", recovered)
+			},
+		}),
+		server.WithObserver(MetricsObserver{}))
 	if err != nil {
 		log.Fatal("Failed to create server:", err)
 	}
 
 	log.Println("Registering global middlewares...")
+	// the first registered middleware is the outermost: logging sees every call, auth runs before metrics and the handler
 	mcpServer.Use(
-		PanicRecoveryMiddleware(),
 		LoggingMiddleware(),
 		AuthMiddleware(),
 		MetricsMiddleware(),
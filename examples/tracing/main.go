@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+type echoReq struct {
+	Message string `json:"message" description:"message to echo"`
+}
+
+func main() {
+	// spans are printed to stderr, stdout carries the stdio transport
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	if err != nil {
+		log.Fatalf("Failed to create exporter: %v", err)
+	}
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			log.Printf("Failed to shutdown tracer provider: %v", err)
+		}
+	}()
+
+	// every request gets a span, a child of the traceparent the client sent in _meta
+	srv, err := server.NewServer(
+		transport.NewStdioServerTransport(),
+		server.WithServerInfo(protocol.Implementation{
+			Name:    "tracing-example-server",
+			Version: "1.0.0",
+		}),
+		server.WithTracer(tracerProvider.Tracer("github.com/hhfgeg/go-mcp/examples/tracing")),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool, err := protocol.NewTool("echo", "Echo the message", echoReq{})
+	if err != nil {
+		log.Fatalf("Failed to create tool: %v", err)
+	}
+	srv.RegisterTool(tool, echo)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- srv.Run()
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err = <-errCh:
+		log.Printf("server stopped: %v", err)
+	case <-sigChan:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = srv.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown error: %v", err)
+	}
+}
+
+func echo(ctx context.Context, request *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+	req := new(echoReq)
+	if err := protocol.VerifyAndUnmarshal(request.RawArguments, &req); err != nil {
+		return nil, err
+	}
+
+	// the handler context carries the span of the request, nested spans are children of it
+	_, span := trace.SpanFromContext(ctx).TracerProvider().Tracer("echo").Start(ctx, "echo")
+	defer span.End()
+
+	return &protocol.CallToolResult{
+		Content: []protocol.Content{&protocol.TextContent{Type: "text", Text: req.Message}},
+	}, nil
+}
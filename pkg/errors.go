@@ -6,18 +6,27 @@ import (
 )
 
 var (
-	ErrClientNotSupport          = errors.New("this feature client not support")
-	ErrServerNotSupport          = errors.New("this feature server not support")
-	ErrRequestInvalid            = errors.New("request invalid")
-	ErrLackResponseChan          = errors.New("lack response chan")
-	ErrDuplicateResponseReceived = errors.New("duplicate response received")
-	ErrMethodNotSupport          = errors.New("method not support")
-	ErrJSONUnmarshal             = errors.New("json unmarshal error")
-	ErrSessionHasNotInitialized  = errors.New("the session has not been initialized")
-	ErrLackSession               = errors.New("lack session")
-	ErrSessionClosed             = errors.New("session closed")
-	ErrSendEOF                   = errors.New("send EOF")
-	ErrRateLimitExceeded         = errors.New("rate limit exceeded")
+	ErrClientNotSupport           = errors.New("this feature client not support")
+	ErrServerNotSupport           = errors.New("this feature server not support")
+	ErrRequestInvalid             = errors.New("request invalid")
+	ErrLackResponseChan           = errors.New("lack response chan")
+	ErrDuplicateResponseReceived  = errors.New("duplicate response received")
+	ErrMethodNotSupport           = errors.New("method not support")
+	ErrJSONUnmarshal              = errors.New("json unmarshal error")
+	ErrSessionHasNotInitialized   = errors.New("the session has not been initialized")
+	ErrSessionAlreadyInitialized  = errors.New("the session has already been initialized")
+	ErrLackSession                = errors.New("lack session")
+	ErrSessionClosed              = errors.New("session closed")
+	ErrSendEOF                    = errors.New("send EOF")
+	ErrRateLimitExceeded          = errors.New("rate limit exceeded")
+	ErrSubscriptionLimitExceeded  = errors.New("subscription limit exceeded")
+	ErrServerShuttingDown         = errors.New("server shutting down")
+	ErrUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+	ErrMiddlewareTimeout          = errors.New("middleware timeout")
+	ErrAlreadyRegistered          = errors.New("already registered")
+	ErrUnsupportedResourceScheme  = errors.New("unsupported resource uri scheme")
+	ErrStructuredContentMismatch  = errors.New("structured content and text content disagree")
+	ErrReconnectFailed            = errors.New("reconnect failed, retry budget exhausted")
 )
 
 type ResponseError struct {
@@ -2,25 +2,40 @@ package pkg
 
 import (
 	"errors"
-	"log"
 	"runtime/debug"
 	"strings"
 	"unsafe"
 )
 
+// Recover recovers a panic and logs it through DefaultLogger
 func Recover() {
 	if r := recover(); r != nil {
-		log.Printf("panic: %v\nstack: %s", r, debug.Stack())
+		logPanic(DefaultLogger, r)
 	}
 }
 
+// RecoverWithFunc recovers a panic, calls f with it and logs it through DefaultLogger
 func RecoverWithFunc(f func(r any)) {
 	if r := recover(); r != nil {
 		f(r)
-		log.Printf("panic: %v\nstack: %s", r, debug.Stack())
+		logPanic(DefaultLogger, r)
 	}
 }
 
+// RecoverWithLogger recovers a panic and logs it through logger, onPanic is called with it if not nil
+func RecoverWithLogger(logger Logger, onPanic func(r any)) {
+	if r := recover(); r != nil {
+		if onPanic != nil {
+			onPanic(r)
+		}
+		logPanic(logger, r)
+	}
+}
+
+func logPanic(logger Logger, r any) {
+	logger.Errorf("panic: %v\nstack: %s", r, debug.Stack())
+}
+
 func B2S(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b))
 }
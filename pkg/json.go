@@ -13,3 +13,26 @@ func JSONUnmarshal(data []byte, v interface{}) error {
 	}
 	return nil
 }
+
+// JSONWithMeta returns the JSON encoding of params with key set in its _meta
+func JSONWithMeta(params interface{}, key string, value interface{}) (json.RawMessage, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{})
+	if string(raw) != "null" {
+		if err = JSONUnmarshal(raw, &m); err != nil {
+			return nil, err
+		}
+	}
+	meta, _ := m["_meta"].(map[string]interface{})
+	if meta == nil {
+		meta = make(map[string]interface{}, 1)
+	}
+	meta[key] = value
+	m["_meta"] = meta
+
+	return json.Marshal(m)
+}
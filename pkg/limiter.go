@@ -7,15 +7,18 @@ import (
 
 // RateLimiter 定义速率限制接口
 type RateLimiter interface {
-	Allow(toolName string) bool
+	// Allow 检查 key (例如工具名、会话ID) 对应的请求是否被允许
+	Allow(key string) bool
 }
 
 // TokenBucketLimiter 令牌桶限速器实现
+// 每个 key 拥有独立的桶和锁, 热路径上除首次创建桶外不产生内存分配
 type TokenBucketLimiter struct {
-	mu           sync.RWMutex
-	buckets      map[string]*bucket
+	buckets      sync.Map // map[string]*bucket
 	defaultLimit Rate
-	toolLimits   map[string]Rate
+
+	mu         sync.RWMutex
+	toolLimits map[string]Rate
 }
 
 // Rate 定义速率限制参数
@@ -26,6 +29,7 @@ type Rate struct {
 
 // bucket 令牌桶
 type bucket struct {
+	mu            sync.Mutex
 	tokens        float64
 	lastTimestamp time.Time
 	rate          Rate
@@ -34,7 +38,6 @@ type bucket struct {
 // NewTokenBucketLimiter 创建新的令牌桶限速器
 func NewTokenBucketLimiter(defaultRate Rate) *TokenBucketLimiter {
 	return &TokenBucketLimiter{
-		buckets:      make(map[string]*bucket),
 		defaultLimit: defaultRate,
 		toolLimits:   make(map[string]Rate),
 	}
@@ -47,43 +50,49 @@ func (l *TokenBucketLimiter) SetToolLimit(toolName string, rate Rate) {
 
 	l.toolLimits[toolName] = rate
 	// 如果已有桶，更新其速率
-	if b, exists := l.buckets[toolName]; exists {
+	if v, exists := l.buckets.Load(toolName); exists {
+		b := v.(*bucket)
+		b.mu.Lock()
 		b.rate = rate
+		b.mu.Unlock()
 	}
 }
 
 // Allow 检查请求是否被允许
-func (l *TokenBucketLimiter) Allow(toolName string) bool {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-
+func (l *TokenBucketLimiter) Allow(key string) bool {
 	now := time.Now()
 
 	// 获取或创建桶
-	b, exists := l.buckets[toolName]
+	v, exists := l.buckets.Load(key)
 	if !exists {
 		// 查找工具特定的限制，如果没有则使用默认限制
-		rate, exists := l.toolLimits[toolName]
+		l.mu.RLock()
+		rate, exists := l.toolLimits[key]
+		l.mu.RUnlock()
 		if !exists {
 			rate = l.defaultLimit
 		}
 
-		b = &bucket{
+		v, _ = l.buckets.LoadOrStore(key, &bucket{
 			tokens:        float64(rate.Burst),
 			lastTimestamp: now,
 			rate:          rate,
-		}
-		l.buckets[toolName] = b
+		})
 	}
+	b := v.(*bucket)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
 	// 计算从上次请求到现在应该添加的令牌
 	elapsed := now.Sub(b.lastTimestamp).Seconds()
-	b.lastTimestamp = now
-
-	// 添加令牌，但不超过最大值
-	b.tokens += elapsed * b.rate.Limit
-	if b.tokens > float64(b.rate.Burst) {
-		b.tokens = float64(b.rate.Burst)
+	if elapsed > 0 {
+		b.lastTimestamp = now
+		// 添加令牌，但不超过最大值
+		b.tokens += elapsed * b.rate.Limit
+		if b.tokens > float64(b.rate.Burst) {
+			b.tokens = float64(b.rate.Burst)
+		}
 	}
 
 	if b.tokens >= 1.0 {
@@ -0,0 +1,43 @@
+//go:build go1.21
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// NewSlogLogger adapts a log/slog logger to Logger, so the library logs through the structured logging
+// of the application. The formatted message becomes the message of the record.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogLogger) Debugf(format string, a ...any) {
+	l.log(slog.LevelDebug, format, a...)
+}
+
+func (l *slogLogger) Infof(format string, a ...any) {
+	l.log(slog.LevelInfo, format, a...)
+}
+
+func (l *slogLogger) Warnf(format string, a ...any) {
+	l.log(slog.LevelWarn, format, a...)
+}
+
+func (l *slogLogger) Errorf(format string, a ...any) {
+	l.log(slog.LevelError, format, a...)
+}
+
+func (l *slogLogger) log(level slog.Level, format string, a ...any) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUCache is a size-capped in-memory cache whose entries expire after their TTL,
+// the least recently used entry is evicted when it is full. It is safe for concurrent use.
+type LRUCache[V any] struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front is the most recently used entry
+	entries map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRUCache creates an LRUCache holding at most size entries
+func NewLRUCache[V any](size int) *LRUCache[V] {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache[V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key unless it has expired
+func (c *LRUCache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[V])
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value under key for ttl
+func (c *LRUCache[V]) Set(key string, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &lruEntry[V]{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: time.Now().Add(ttl)})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+// Len returns the number of entries, including expired entries not evicted yet
+func (c *LRUCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceParent is a W3C trace context span context, see https://www.w3.org/TR/trace-context/#traceparent-header
+type TraceParent struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// ParseTraceParent parses a version 00 traceparent header value, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return tp, fmt.Errorf("invalid traceparent: %q", s)
+	}
+	// future versions may append fields, only version 00 must have exactly four
+	if parts[0] == "00" && len(parts) != 4 {
+		return tp, fmt.Errorf("invalid traceparent: %q", s)
+	}
+
+	var flags [1]byte
+	if err := decodeHex(tp.TraceID[:], parts[1]); err != nil {
+		return tp, fmt.Errorf("invalid traceparent trace-id: %w", err)
+	}
+	if err := decodeHex(tp.SpanID[:], parts[2]); err != nil {
+		return tp, fmt.Errorf("invalid traceparent parent-id: %w", err)
+	}
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return tp, fmt.Errorf("invalid traceparent trace-flags: %w", err)
+	}
+	tp.Flags = flags[0]
+
+	if !tp.IsValid() {
+		return tp, fmt.Errorf("invalid traceparent: %q, all zero trace-id or parent-id", s)
+	}
+	return tp, nil
+}
+
+func decodeHex(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return fmt.Errorf("want %d lowercase hex characters, got %q", hex.EncodedLen(len(dst)), s)
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// IsValid reports whether both the trace id and span id are non-zero
+func (tp TraceParent) IsValid() bool {
+	return tp.TraceID != [16]byte{} && tp.SpanID != [8]byte{}
+}
+
+// Sampled reports whether the sampled trace flag is set
+func (tp TraceParent) Sampled() bool {
+	return tp.Flags&0x01 != 0
+}
+
+// NewChild returns a span context in the same trace with a new random span id
+func (tp TraceParent) NewChild() TraceParent {
+	child := tp
+	for child.SpanID == [8]byte{} || child.SpanID == tp.SpanID {
+		_, _ = rand.Read(child.SpanID[:])
+	}
+	return child
+}
+
+// String formats the span context as a traceparent header value
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(tp.TraceID[:]), hex.EncodeToString(tp.SpanID[:]), tp.Flags)
+}
+
+// TraceParentFromSpanContext converts an OpenTelemetry span context
+func TraceParentFromSpanContext(sc trace.SpanContext) TraceParent {
+	return TraceParent{TraceID: sc.TraceID(), SpanID: sc.SpanID(), Flags: byte(sc.TraceFlags())}
+}
+
+// SpanContext converts the span context to a remote OpenTelemetry span context
+func (tp TraceParent) SpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tp.TraceID,
+		SpanID:     tp.SpanID,
+		TraceFlags: trace.TraceFlags(tp.Flags),
+		Remote:     true,
+	})
+}
@@ -0,0 +1,146 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ArgumentCoercion selects how BindArguments treats an argument whose JSON type doesn't match its target field
+type ArgumentCoercion int
+
+const (
+	// ArgumentCoercionLenient converts numeric strings to numbers and integral numbers such as 3.0 to integers
+	// for numeric fields, this is the default
+	ArgumentCoercionLenient ArgumentCoercion = iota
+	// ArgumentCoercionStrict rejects every type mismatch with an invalid params error
+	ArgumentCoercionStrict
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// coerceArguments rewrites the JSON arguments so that numeric fields of v's type accept numeric strings
+// and integral floats, arguments that can't be converted are left for unmarshalling to reject
+func coerceArguments(raw json.RawMessage, v interface{}) json.RawMessage {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return raw
+	}
+
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	var args interface{}
+	if err := d.Decode(&args); err != nil {
+		return raw
+	}
+	coerced, err := json.Marshal(coerceValue(args, t))
+	if err != nil {
+		return raw
+	}
+	return coerced
+}
+
+func coerceValue(value interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if obj, ok := value.(map[string]interface{}); ok {
+			coerceFields(obj, t)
+		}
+	case reflect.Map:
+		if obj, ok := value.(map[string]interface{}); ok {
+			for k, v := range obj {
+				obj[k] = coerceValue(v, t.Elem())
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := value.([]interface{}); ok {
+			for i, v := range arr {
+				arr[i] = coerceValue(v, t.Elem())
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s, ok := numericText(value); ok {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return json.Number(strconv.FormatInt(i, 10))
+			}
+			if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return json.Number(strconv.FormatUint(u, 10))
+			}
+			if f, err := strconv.ParseFloat(s, 64); err == nil && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+				return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		if s, ok := numericText(value); ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+			}
+		}
+	}
+	return value
+}
+
+// coerceFields coerces the members of obj matching the exported fields of the struct type t, following
+// the field naming of encoding/json including the fields of embedded structs
+func coerceFields(obj map[string]interface{}, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				coerceFields(obj, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		// fields tagged ",string" expect the number quoted
+		if strings.Contains(","+opts+",", ",string,") {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		for key, v := range obj {
+			if key == name || strings.EqualFold(key, name) {
+				obj[key] = coerceValue(v, field.Type)
+			}
+		}
+	}
+}
+
+// numericText returns the text of a JSON number or a string that may hold one
+func numericText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return string(v), true
+	case string:
+		return strings.TrimSpace(v), true
+	default:
+		return "", false
+	}
+}
@@ -1,5 +1,20 @@
 package protocol
 
+import (
+	"encoding/json"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+// Reference types a completion request may complete the arguments of
+const (
+	RefTypePrompt   = "ref/prompt"
+	RefTypeResource = "ref/resource"
+)
+
+// CompletionMaxValues is the maximum number of values a completion result may carry
+const CompletionMaxValues = 100
+
 // CompleteRequest represents a request for completion options
 type CompleteRequest struct {
 	Argument struct {
@@ -7,6 +22,57 @@ type CompleteRequest struct {
 		Value string `json:"value"`
 	} `json:"argument"`
 	Ref interface{} `json:"ref"` // Can be PromptReference or ResourceReference
+
+	rawParamsHolder
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for CompleteRequest,
+// the ref is decoded into a *PromptReference or a *ResourceReference according to its type
+func (r *CompleteRequest) UnmarshalJSON(data []byte) error {
+	type Alias CompleteRequest
+	aux := &struct {
+		Ref json.RawMessage `json:"ref"`
+		*Alias
+	}{
+		Alias: (*Alias)(r),
+	}
+	if err := pkg.JSONUnmarshal(data, aux); err != nil {
+		return err
+	}
+	r.keepRawParams(data)
+	if len(aux.Ref) == 0 || string(aux.Ref) == "null" {
+		r.Ref = nil
+		return nil
+	}
+
+	var typeOnly struct {
+		Type string `json:"type"`
+	}
+	if err := pkg.JSONUnmarshal(aux.Ref, &typeOnly); err != nil {
+		return err
+	}
+	switch typeOnly.Type {
+	case RefTypePrompt:
+		ref := &PromptReference{}
+		if err := pkg.JSONUnmarshal(aux.Ref, ref); err != nil {
+			return err
+		}
+		r.Ref = ref
+	case RefTypeResource:
+		ref := &ResourceReference{}
+		if err := pkg.JSONUnmarshal(aux.Ref, ref); err != nil {
+			return err
+		}
+		r.Ref = ref
+	default:
+		// references of types this library does not know are kept as is
+		var ref map[string]interface{}
+		if err := pkg.JSONUnmarshal(aux.Ref, &ref); err != nil {
+			return err
+		}
+		r.Ref = ref
+	}
+	return nil
 }
 
 // Reference types
@@ -17,7 +83,17 @@ type PromptReference struct {
 
 type ResourceReference struct {
 	Type string `json:"type"`
-	URI  string `json:"uri"`
+	URI  string `json:"uri"` // the uri template the completed variable belongs to
+}
+
+// NewPromptReference references the prompt with the given name
+func NewPromptReference(name string) *PromptReference {
+	return &PromptReference{Type: RefTypePrompt, Name: name}
+}
+
+// NewResourceReference references the resource template with the given uri template
+func NewResourceReference(uri string) *ResourceReference {
+	return &ResourceReference{Type: RefTypeResource, URI: uri}
 }
 
 // CompleteResult represents the response to a completion request
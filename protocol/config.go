@@ -0,0 +1,15 @@
+package protocol
+
+// ClientConfigNotification represents a configuration pushed by the server to the client after initialization,
+// e.g. recommended polling intervals or feature toggles. It is a go-mcp extension, not part of the MCP spec.
+type ClientConfigNotification struct {
+	Meta   map[string]interface{} `json:"_meta,omitempty"`
+	Config map[string]interface{} `json:"config"`
+}
+
+// NewClientConfigNotification creates a new client config notification
+func NewClientConfigNotification(config map[string]interface{}) *ClientConfigNotification {
+	return &ClientConfigNotification{
+		Config: config,
+	}
+}
@@ -0,0 +1,16 @@
+package protocol
+
+// DrainingNotification advises the client that the server is draining, e.g. during a rolling restart, and that it
+// should reconnect, possibly to another instance, before the session is closed. It is a go-mcp extension,
+// not part of the MCP spec.
+type DrainingNotification struct {
+	Meta   map[string]interface{} `json:"_meta,omitempty"`
+	Reason string                 `json:"reason,omitempty"`
+}
+
+// NewDrainingNotification creates a new draining notification
+func NewDrainingNotification(reason string) *DrainingNotification {
+	return &DrainingNotification{
+		Reason: reason,
+	}
+}
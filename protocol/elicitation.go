@@ -0,0 +1,56 @@
+package protocol
+
+import "github.com/hhfgeg/go-mcp/pkg"
+
+// ElicitAction is the user's answer to an elicitation request
+type ElicitAction string
+
+const (
+	// ElicitActionAccept the user submitted the requested input
+	ElicitActionAccept ElicitAction = "accept"
+	// ElicitActionDecline the user explicitly declined to provide the input
+	ElicitActionDecline ElicitAction = "decline"
+	// ElicitActionCancel the user dismissed the request without an explicit choice
+	ElicitActionCancel ElicitAction = "cancel"
+)
+
+// ElicitRequest represents a request from the server to collect structured input from the user through the client
+type ElicitRequest struct {
+	Message         string      `json:"message"`
+	RequestedSchema InputSchema `json:"requestedSchema"`
+
+	rawParamsHolder
+}
+
+// UnmarshalJSON keeps the raw params, see RawParams
+func (r *ElicitRequest) UnmarshalJSON(data []byte) error {
+	type alias ElicitRequest
+	if err := pkg.JSONUnmarshal(data, (*alias)(r)); err != nil {
+		return err
+	}
+	r.keepRawParams(data)
+	return nil
+}
+
+// ElicitResult represents the response to an elicitation request
+type ElicitResult struct {
+	Action ElicitAction `json:"action"`
+	// Content holds the submitted input, it is only present when Action is ElicitActionAccept
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// NewElicitRequest creates a new elicitation request
+func NewElicitRequest(message string, requestedSchema InputSchema) *ElicitRequest {
+	return &ElicitRequest{
+		Message:         message,
+		RequestedSchema: requestedSchema,
+	}
+}
+
+// NewElicitResult creates a new elicitation response
+func NewElicitResult(action ElicitAction, content map[string]interface{}) *ElicitResult {
+	return &ElicitResult{
+		Action:  action,
+		Content: content,
+	}
+}
@@ -30,8 +30,9 @@ type Implementation struct {
 // ClientCapabilities capabilities
 type ClientCapabilities struct {
 	// Experimental map[string]interface{} `json:"experimental,omitempty"`
-	// Roots        *RootsCapability       `json:"roots,omitempty"`
-	Sampling interface{} `json:"sampling,omitempty"`
+	Roots       *RootsCapability `json:"roots,omitempty"`
+	Sampling    interface{}      `json:"sampling,omitempty"`
+	Elicitation interface{}      `json:"elicitation,omitempty"`
 }
 
 type RootsCapability struct {
@@ -41,9 +42,10 @@ type RootsCapability struct {
 type ServerCapabilities struct {
 	// Experimental map[string]interface{} `json:"experimental,omitempty"`
 	// Logging      interface{}            `json:"logging,omitempty"`
-	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
-	Resources *ResourcesCapability `json:"resources,omitempty"`
-	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Prompts     *PromptsCapability     `json:"prompts,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
 }
 
 type PromptsCapability struct {
@@ -59,6 +61,9 @@ type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+// CompletionsCapability is advertised by servers answering completion/complete requests
+type CompletionsCapability struct{}
+
 // InitializedNotification represents the notification sent from client to server after initialization
 type InitializedNotification struct {
 	Meta map[string]interface{} `json:"_meta,omitempty"`
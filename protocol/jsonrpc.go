@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 )
@@ -17,7 +18,9 @@ const (
 	InternalError  = -32603 // Internal JSON-RPC error
 
 	// 可以定义自己的错误代码，范围在-32000 以上。
-	ConnectionError = -32400
+	ConnectionError    = -32400
+	ServerShuttingDown = -32001 // The server is shutting down, the request can be retried later
+	RateLimitExceeded  = -32029 // The request was rate limited, it can be retried later
 )
 
 type RequestID interface{} // 字符串/数值
@@ -166,6 +169,13 @@ func NewJSONRPCErrorResponse(id RequestID, code int, message string) *JSONRPCRes
 	return err
 }
 
+// NewJSONRPCErrorResponseWithData creates a new JSON-RPC error response carrying additional error data
+func NewJSONRPCErrorResponseWithData(id RequestID, code int, message string, data interface{}) *JSONRPCResponse {
+	resp := NewJSONRPCErrorResponse(id, code, message)
+	resp.Error.Data = data
+	return resp
+}
+
 // NewJSONRPCNotification creates a new JSON-RPC notification
 func NewJSONRPCNotification(method Method, params interface{}) *JSONRPCNotification {
 	return &JSONRPCNotification{
@@ -174,3 +184,52 @@ func NewJSONRPCNotification(method Method, params interface{}) *JSONRPCNotificat
 		Params:  params,
 	}
 }
+
+// rawParamsHolder keeps the undecoded params object of a request, the request types passed to handlers embed it
+// and keep their params from their UnmarshalJSON
+type rawParamsHolder struct {
+	rawParams json.RawMessage
+}
+
+// RawParams returns the undecoded params object of the request, e.g. to read vendor-specific fields
+// the struct doesn't model. It is nil for a request that was not decoded from JSON.
+func (h *rawParamsHolder) RawParams() json.RawMessage {
+	return h.rawParams
+}
+
+// keepRawParams copies data, UnmarshalJSON must not retain the data it is passed
+func (h *rawParamsHolder) keepRawParams(data []byte) {
+	h.rawParams = append(json.RawMessage(nil), data...)
+}
+
+// Error is an error that carries a JSON-RPC error code and optional data.
+// Handlers may return it (or wrap it) to control the error object sent to the client.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// NewError creates a new JSON-RPC error with the given code, message and data
+func NewError(code int, message string, data interface{}) *Error {
+	return &Error{Code: code, Message: message, Data: data}
+}
+
+// NewInvalidParamsError creates a new JSON-RPC invalid params error
+func NewInvalidParamsError(message string, data interface{}) *Error {
+	return NewError(InvalidParams, message, data)
+}
+
+// NewMethodNotFoundError creates a new JSON-RPC method not found error
+func NewMethodNotFoundError(message string, data interface{}) *Error {
+	return NewError(MethodNotFound, message, data)
+}
+
+// NewInternalError creates a new JSON-RPC internal error
+func NewInternalError(message string, data interface{}) *Error {
+	return NewError(InternalError, message, data)
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code=%d message=%s", e.Code, e.Message)
+}
@@ -0,0 +1,20 @@
+package protocol
+
+// Keys of the request _meta that hint how long the client is willing to wait for the response.
+const (
+	// DeadlineKey carries an absolute deadline as an RFC 3339 timestamp
+	DeadlineKey = "deadline"
+	// TimeoutKey carries a relative timeout in milliseconds
+	TimeoutKey = "timeout"
+)
+
+// WarningKey is the result _meta key carrying a human readable warning about the result, e.g. that it was truncated
+const WarningKey = "warning"
+
+// RootCallIDKey is the _meta key correlating nested operations, e.g. a tool call that triggers sampling
+// which in turn triggers another tool call, with the request at the root of the chain
+const RootCallIDKey = "rootCallId"
+
+// IdempotencyKey is the request _meta key of a tool call carrying a client chosen key, a retry of the call
+// with the same key is answered with the result of the original call by servers supporting it
+const IdempotencyKey = "idempotencyKey"
@@ -0,0 +1,85 @@
+package protocol
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// mimeTypesByExtension maps the extensions of common resource files to their MIME type,
+// it takes precedence over sniffing which can't tell e.g. JSON or Markdown from plain text
+var mimeTypesByExtension = map[string]string{
+	".txt":  "text/plain",
+	".md":   "text/markdown",
+	".csv":  "text/csv",
+	".html": "text/html",
+	".htm":  "text/html",
+	".css":  "text/css",
+	".js":   "text/javascript",
+	".json": "application/json",
+	".xml":  "application/xml",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+	".toml": "application/toml",
+	".go":   "text/x-go",
+	".py":   "text/x-python",
+	".sh":   "application/x-sh",
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+	".gz":   "application/gzip",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".mp4":  "video/mp4",
+}
+
+// MimeTypeByExtension returns the MIME type of common files by the extension of name, a path or file name
+func MimeTypeByExtension(name string) (string, bool) {
+	mimeType, ok := mimeTypesByExtension[strings.ToLower(path.Ext(name))]
+	return mimeType, ok
+}
+
+// NewContentFromFile reads the file at path and returns its contents as a tool result content: an ImageContent
+// or AudioContent for images and audio, a TextContent for UTF-8 text, and otherwise an EmbeddedResource holding
+// the base64-encoded blob under its file:// URI. The MIME type is detected from the extension,
+// or sniffed from the contents if the extension is unknown.
+// A missing or unreadable file is reported as an error wrapping the one of os.ReadFile, e.g. os.ErrNotExist.
+func NewContentFromFile(path string) (Content, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read content file: %w", err)
+	}
+
+	mimeType, ok := MimeTypeByExtension(path)
+	if !ok {
+		// DetectContentType falls back to application/octet-stream when inconclusive
+		mimeType = http.DetectContentType(data)
+	}
+
+	switch {
+	case strings.HasPrefix(mimeType, "image/") && mimeType != "image/svg+xml":
+		return &ImageContent{Type: "image", Data: data, MimeType: mimeType}, nil
+	case strings.HasPrefix(mimeType, "audio/"):
+		return &AudioContent{Type: "audio", Data: data, MimeType: mimeType}, nil
+	case utf8.Valid(data) && bytes.IndexByte(data, 0) < 0:
+		return &TextContent{Type: "text", Text: string(data)}, nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve content file path: %w", err)
+	}
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+	return NewEmbeddedResource(NewBlobResourceContents(uri, mimeType, data), nil), nil
+}
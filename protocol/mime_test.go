@@ -0,0 +1,46 @@
+package protocol
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewContentFromFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0o600); err != nil {
+			t.Fatalf("WriteFile: %+v", err)
+		}
+		return p
+	}
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	text, err := NewContentFromFile(write("notes.md", []byte("# notes")))
+	if c, ok := text.(*TextContent); err != nil || !ok || c.Text != "# notes" || c.Type != "text" {
+		t.Fatalf("expected text content, got %#v, %v", text, err)
+	}
+
+	// the MIME type of a file without a known extension is sniffed
+	image, err := NewContentFromFile(write("logo", png))
+	if c, ok := image.(*ImageContent); err != nil || !ok || c.MimeType != "image/png" || string(c.Data) != string(png) {
+		t.Fatalf("expected png image content, got %#v, %v", image, err)
+	}
+
+	blob, err := NewContentFromFile(write("data.bin", []byte{0x00, 0xff, 0x10}))
+	c, ok := blob.(*EmbeddedResource)
+	if err != nil || !ok {
+		t.Fatalf("expected embedded resource, got %#v, %v", blob, err)
+	}
+	contents, _ := c.Resource.(*BlobResourceContents)
+	if data, _ := contents.Data(); contents.MimeType != "application/octet-stream" || string(data) != "\x00\xff\x10" ||
+		contents.URI != "file://"+filepath.ToSlash(filepath.Join(dir, "data.bin")) {
+		t.Fatalf("unexpected blob contents %#v", contents)
+	}
+
+	if _, err = NewContentFromFile(filepath.Join(dir, "missing.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+}
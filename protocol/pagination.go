@@ -8,6 +8,11 @@ import (
 // Cursor is an opaque token used to represent a cursor for pagination.
 type Cursor string
 
+// NewCursor returns the cursor of the page starting after the element with the given name
+func NewCursor(name string) Cursor {
+	return Cursor(base64.StdEncoding.EncodeToString([]byte(name)))
+}
+
 type Named interface {
 	GetName() string
 }
@@ -38,10 +43,7 @@ func PaginationLimit[T Named](allElements []T, cursor Cursor, limit int) ([]T, C
 		if len(elementsToReturn) < limit {
 			return ""
 		}
-		element := elementsToReturn[len(elementsToReturn)-1]
-		nc := element.GetName()
-		toString := base64.StdEncoding.EncodeToString([]byte(nc))
-		return Cursor(toString)
+		return NewCursor(elementsToReturn[len(elementsToReturn)-1].GetName())
 	}()
 	return elementsToReturn, nextCursor, nil
 }
@@ -0,0 +1,51 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+// PartialToolResultNotification carries a piece of the output of a tool call ahead of its final result,
+// e.g. a line of a build log. It is a go-mcp extension, not part of the MCP spec.
+type PartialToolResultNotification struct {
+	// RequestID is the id of the tools/call request the partial result belongs to
+	RequestID RequestID `json:"requestId"`
+	// Sequence numbers the partial results of a call starting at 1
+	Sequence int       `json:"sequence"`
+	Content  []Content `json:"content"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for PartialToolResultNotification
+func (n *PartialToolResultNotification) UnmarshalJSON(data []byte) error {
+	type Alias PartialToolResultNotification
+	aux := &struct {
+		Content []json.RawMessage `json:"content"`
+		*Alias
+	}{
+		Alias: (*Alias)(n),
+	}
+	if err := pkg.JSONUnmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	n.Content = make([]Content, 0, len(aux.Content))
+	for _, raw := range aux.Content {
+		content, err := unmarshalContent(raw)
+		if err != nil {
+			return fmt.Errorf("partial tool result: %w", err)
+		}
+		n.Content = append(n.Content, content)
+	}
+	return nil
+}
+
+// NewPartialToolResultNotification creates a new partial tool result notification
+func NewPartialToolResultNotification(requestID RequestID, sequence int, content ...Content) *PartialToolResultNotification {
+	return &PartialToolResultNotification{
+		RequestID: requestID,
+		Sequence:  sequence,
+		Content:   content,
+	}
+}
@@ -2,7 +2,6 @@ package protocol
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 )
@@ -18,6 +17,16 @@ type ListPromptsResult struct {
 	NextCursor Cursor    `json:"nextCursor,omitempty"`
 }
 
+// MarshalJSON encodes a nil Prompts as [] rather than null
+func (r *ListPromptsResult) MarshalJSON() ([]byte, error) {
+	type Alias ListPromptsResult
+	aux := Alias(*r)
+	if aux.Prompts == nil {
+		aux.Prompts = []*Prompt{}
+	}
+	return json.Marshal(aux)
+}
+
 // Prompt related types
 type Prompt struct {
 	Name        string            `json:"name"`
@@ -39,6 +48,37 @@ type PromptArgument struct {
 type GetPromptRequest struct {
 	Name      string            `json:"name"`
 	Arguments map[string]string `json:"arguments,omitempty"`
+
+	coercion ArgumentCoercion
+	rawParamsHolder
+}
+
+// UnmarshalJSON keeps the raw params, see RawParams
+func (r *GetPromptRequest) UnmarshalJSON(data []byte) error {
+	type alias GetPromptRequest
+	if err := pkg.JSONUnmarshal(data, (*alias)(r)); err != nil {
+		return err
+	}
+	r.keepRawParams(data)
+	return nil
+}
+
+// SetArgumentCoercion sets how BindArguments treats arguments whose type doesn't match their field,
+// servers set it from their configuration before calling the handler
+func (r *GetPromptRequest) SetArgumentCoercion(coercion ArgumentCoercion) {
+	r.coercion = coercion
+}
+
+// BindArguments unmarshals the arguments into v, e.g. a pointer to a struct with json tags.
+// Prompt arguments are strings, numeric fields accept them unless strict coercion is set, in which case
+// numbers can only be bound to fields tagged with the json ",string" option.
+// An argument of the wrong type fails with an invalid params error naming it.
+func (r *GetPromptRequest) BindArguments(v interface{}) error {
+	raw, err := json.Marshal(r.Arguments)
+	if err != nil {
+		return err
+	}
+	return bindArguments(raw, v, r.coercion)
 }
 
 // GetPromptResult represents the response to a get prompt request
@@ -47,6 +87,18 @@ type GetPromptResult struct {
 	Description string           `json:"description,omitempty"`
 }
 
+// MarshalJSON encodes a nil Messages as [], a prompt may render to no messages
+func (r *GetPromptResult) MarshalJSON() ([]byte, error) {
+	type Alias GetPromptResult
+	aux := Alias(*r)
+	if aux.Messages == nil {
+		aux.Messages = []*PromptMessage{}
+	}
+	return json.Marshal(aux)
+}
+
+// PromptMessage is a message of a prompt, its content may be any content block a tool result may hold,
+// e.g. a screenshot the model should consider
 type PromptMessage struct {
 	Role    Role    `json:"role"`
 	Content Content `json:"content"`
@@ -65,35 +117,60 @@ func (m *PromptMessage) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	// Try to unmarshal content as TextContent first
-	var textContent *TextContent
-	if err := pkg.JSONUnmarshal(aux.Content, &textContent); err == nil {
-		m.Content = textContent
-		return nil
+	content, err := unmarshalContent(aux.Content)
+	if err != nil {
+		return err
 	}
+	m.Content = content
+	return nil
+}
 
-	// Try to unmarshal content as ImageContent
-	var imageContent *ImageContent
-	if err := pkg.JSONUnmarshal(aux.Content, &imageContent); err == nil {
-		m.Content = imageContent
-		return nil
+// NewPromptMessage creates a new prompt message
+func NewPromptMessage(role Role, content Content) *PromptMessage {
+	return &PromptMessage{
+		Role:    role,
+		Content: content,
 	}
+}
 
-	// Try to unmarshal content as AudioContent
-	var audioContent *AudioContent
-	if err := pkg.JSONUnmarshal(aux.Content, &audioContent); err == nil {
-		m.Content = audioContent
-		return nil
-	}
+// NewUserTextMessage creates a new prompt message with text content from the user
+func NewUserTextMessage(text string) *PromptMessage {
+	return NewPromptMessage(RoleUser, &TextContent{Type: "text", Text: text})
+}
 
-	// Try to unmarshal content as embeddedResource
-	var embeddedResource *EmbeddedResource
-	if err := pkg.JSONUnmarshal(aux.Content, &embeddedResource); err == nil {
-		m.Content = embeddedResource
-		return nil
-	}
+// NewAssistantTextMessage creates a new prompt message with text content from the assistant
+func NewAssistantTextMessage(text string) *PromptMessage {
+	return NewPromptMessage(RoleAssistant, &TextContent{Type: "text", Text: text})
+}
+
+// NewUserImageMessage creates a new prompt message with image content from the user
+func NewUserImageMessage(data []byte, mimeType string) *PromptMessage {
+	return NewPromptMessage(RoleUser, &ImageContent{Type: "image", Data: data, MimeType: mimeType})
+}
+
+// NewAssistantImageMessage creates a new prompt message with image content from the assistant
+func NewAssistantImageMessage(data []byte, mimeType string) *PromptMessage {
+	return NewPromptMessage(RoleAssistant, &ImageContent{Type: "image", Data: data, MimeType: mimeType})
+}
+
+// NewUserAudioMessage creates a new prompt message with audio content from the user
+func NewUserAudioMessage(data []byte, mimeType string) *PromptMessage {
+	return NewPromptMessage(RoleUser, &AudioContent{Type: "audio", Data: data, MimeType: mimeType})
+}
+
+// NewAssistantAudioMessage creates a new prompt message with audio content from the assistant
+func NewAssistantAudioMessage(data []byte, mimeType string) *PromptMessage {
+	return NewPromptMessage(RoleAssistant, &AudioContent{Type: "audio", Data: data, MimeType: mimeType})
+}
+
+// NewUserResourceMessage creates a new prompt message embedding resource from the user
+func NewUserResourceMessage(resource ResourceContents) *PromptMessage {
+	return NewPromptMessage(RoleUser, NewEmbeddedResource(resource, nil))
+}
 
-	return fmt.Errorf("unknown content type")
+// NewAssistantResourceMessage creates a new prompt message embedding resource from the assistant
+func NewAssistantResourceMessage(resource ResourceContents) *PromptMessage {
+	return NewPromptMessage(RoleAssistant, NewEmbeddedResource(resource, nil))
 }
 
 // PromptListChangedNotification represents a notification that the prompt list has changed
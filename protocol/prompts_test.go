@@ -0,0 +1,47 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPromptMessageContent(t *testing.T) {
+	result := NewGetPromptResult([]*PromptMessage{NewUserImageMessage([]byte("png"), "image/png")}, "")
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if want := `{"messages":[{"role":"user","content":{"type":"image","data":"cG5n","mimeType":"image/png"}}]}`; string(b) != want {
+		t.Fatalf("got %s, want %s", b, want)
+	}
+
+	messages := []*PromptMessage{
+		NewUserTextMessage("describe the screenshot"),
+		NewAssistantTextMessage("a login form"),
+		NewUserImageMessage([]byte("png"), "image/png"),
+		NewAssistantImageMessage([]byte("jpeg"), "image/jpeg"),
+		NewUserAudioMessage([]byte("wav"), "audio/wav"),
+		NewAssistantAudioMessage([]byte("mp3"), "audio/mpeg"),
+		NewUserResourceMessage(&TextResourceContents{URI: "file:///a.txt", MimeType: "text/plain", Text: "a"}),
+		NewAssistantResourceMessage(&BlobResourceContents{URI: "file:///b.bin", MimeType: "application/octet-stream", Blob: "Yg=="}),
+	}
+	for _, message := range messages {
+		b, err := json.Marshal(message)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		var got *PromptMessage
+		if err = json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("json.Unmarshal %s: %v", b, err)
+		}
+		if !reflect.DeepEqual(got, message) {
+			t.Fatalf("round trip of %s: got %+v, want %+v", b, got.Content, message.Content)
+		}
+	}
+
+	var message *PromptMessage
+	if err = json.Unmarshal([]byte(`{"role":"user","content":{"type":"video"}}`), &message); err == nil {
+		t.Fatal("expected an error for an unknown content type")
+	}
+}
@@ -1,9 +1,11 @@
 package protocol
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
+	"github.com/tidwall/gjson"
 	"github.com/yosida95/uritemplate/v3"
 
 	"github.com/hhfgeg/go-mcp/pkg"
@@ -24,6 +26,16 @@ type ListResourcesResult struct {
 	NextCursor Cursor `json:"nextCursor,omitempty"`
 }
 
+// MarshalJSON encodes a nil Resources as [] rather than null
+func (r *ListResourcesResult) MarshalJSON() ([]byte, error) {
+	type Alias ListResourcesResult
+	aux := Alias(*r)
+	if aux.Resources == nil {
+		aux.Resources = []*Resource{}
+	}
+	return json.Marshal(aux)
+}
+
 // ListResourceTemplatesRequest represents a request to list resource templates
 type ListResourceTemplatesRequest struct {
 	Cursor Cursor `json:"cursor,omitempty"`
@@ -35,15 +47,73 @@ type ListResourceTemplatesResult struct {
 	NextCursor        Cursor              `json:"nextCursor,omitempty"`
 }
 
+// MarshalJSON encodes a nil ResourceTemplates as [] rather than null
+func (r *ListResourceTemplatesResult) MarshalJSON() ([]byte, error) {
+	type Alias ListResourceTemplatesResult
+	aux := Alias(*r)
+	if aux.ResourceTemplates == nil {
+		aux.ResourceTemplates = []*ResourceTemplate{}
+	}
+	return json.Marshal(aux)
+}
+
 // ReadResourceRequest represents a request to read a specific resource
 type ReadResourceRequest struct {
-	URI       string                 `json:"uri"`
+	URI string `json:"uri"`
+	// Range asks for a part of the resource only, handlers that don't support ranges may ignore it
+	Range     *ByteRange             `json:"range,omitempty"`
 	Arguments map[string]interface{} `json:"-"`
+
+	rawParamsHolder
+}
+
+// UnmarshalJSON keeps the raw params, see RawParams
+func (r *ReadResourceRequest) UnmarshalJSON(data []byte) error {
+	type alias ReadResourceRequest
+	if err := pkg.JSONUnmarshal(data, (*alias)(r)); err != nil {
+		return err
+	}
+	r.keepRawParams(data)
+	return nil
+}
+
+// ByteRange selects Length bytes of a resource starting at Offset, a zero Length selects the rest of the resource
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length,omitempty"`
+}
+
+// Apply returns the part of data selected by the range, a nil range selects all of data
+func (r *ByteRange) Apply(data []byte) []byte {
+	if r == nil {
+		return data
+	}
+	if r.Offset >= int64(len(data)) {
+		return data[:0]
+	}
+	end := int64(len(data))
+	// compared without adding, Offset+Length overflows for a huge Length
+	if r.Length > 0 && r.Length < end-r.Offset {
+		end = r.Offset + r.Length
+	}
+	return data[r.Offset:end]
 }
 
 // ReadResourceResult The server's response to a resources/read request from the client.
 type ReadResourceResult struct {
 	Contents []ResourceContents `json:"contents"`
+	// TotalSize is the size of the whole resource in bytes, set when a range was read so that clients can page
+	TotalSize int64 `json:"totalSize,omitempty"`
+}
+
+// MarshalJSON encodes a nil Contents as [] rather than null
+func (r *ReadResourceResult) MarshalJSON() ([]byte, error) {
+	type Alias ReadResourceResult
+	aux := Alias(*r)
+	if aux.Contents == nil {
+		aux.Contents = []ResourceContents{}
+	}
+	return json.Marshal(aux)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface for ReadResourceResult
@@ -61,21 +131,11 @@ func (r *ReadResourceResult) UnmarshalJSON(data []byte) error {
 
 	r.Contents = make([]ResourceContents, len(aux.Contents))
 	for i, content := range aux.Contents {
-		// Try to unmarshal content as TextResourceContents first
-		var textContent *TextResourceContents
-		if err := pkg.JSONUnmarshal(content, &textContent); err == nil {
-			r.Contents[i] = textContent
-			continue
-		}
-
-		// Try to unmarshal content as BlobResourceContents
-		var blobContent *BlobResourceContents
-		if err := pkg.JSONUnmarshal(content, &blobContent); err == nil {
-			r.Contents[i] = blobContent
-			continue
+		resourceContents, err := unmarshalResourceContents(content)
+		if err != nil {
+			return fmt.Errorf("unmarshal content at index %d: %w", i, err)
 		}
-
-		return fmt.Errorf("unknown content type at index %d", i)
+		r.Contents[i] = resourceContents
 	}
 
 	return nil
@@ -178,6 +238,30 @@ type Content interface {
 	GetType() string
 }
 
+// unmarshalContent decodes a content block into the concrete type named by its "type" field
+func unmarshalContent(data json.RawMessage) (Content, error) {
+	var content Content
+	switch typ := gjson.GetBytes(data, "type").String(); typ {
+	case "text":
+		content = &TextContent{}
+	case "image":
+		content = &ImageContent{}
+	case "audio":
+		content = &AudioContent{}
+	case "resource_link":
+		content = &ResourceLink{}
+	case "resource":
+		content = &EmbeddedResource{}
+	default:
+		return nil, fmt.Errorf("unknown content type %q, content=%s", typ, data)
+	}
+
+	if err := pkg.JSONUnmarshal(data, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
 type TextContent struct {
 	Annotated
 	Type string `json:"type"`
@@ -210,13 +294,23 @@ func (i *AudioContent) GetType() string {
 	return "audio"
 }
 
+// ResourceLink references a resource from a tool result without embedding its contents
 type ResourceLink struct {
 	Annotated
 	Type        string `json:"type"` // Must be "resource_link"
 	URI         string `json:"uri"`
 	Name        string `json:"name"`
-	Description string `json:"description"`
-	MIMEType    string `json:"mimeType"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// NewResourceLink creates a new ResourceLink
+func NewResourceLink(uri, name string) *ResourceLink {
+	return &ResourceLink{
+		Type: "resource_link",
+		URI:  uri,
+		Name: name,
+	}
 }
 
 func (r *ResourceLink) GetType() string {
@@ -244,6 +338,44 @@ func (i *EmbeddedResource) GetType() string {
 	return "resource"
 }
 
+// UnmarshalJSON implements the json.Unmarshaler interface for EmbeddedResource
+func (i *EmbeddedResource) UnmarshalJSON(data []byte) error {
+	type Alias EmbeddedResource
+	aux := &struct {
+		Resource json.RawMessage `json:"resource"`
+		*Alias
+	}{
+		Alias: (*Alias)(i),
+	}
+	if err := pkg.JSONUnmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	resource, err := unmarshalResourceContents(aux.Resource)
+	if err != nil {
+		return err
+	}
+	i.Resource = resource
+	return nil
+}
+
+// unmarshalResourceContents decodes text or blob resource contents, discriminated by the presence of the blob field
+func unmarshalResourceContents(data []byte) (ResourceContents, error) {
+	if gjson.GetBytes(data, "blob").Exists() {
+		var blobContent *BlobResourceContents
+		if err := pkg.JSONUnmarshal(data, &blobContent); err != nil {
+			return nil, err
+		}
+		return blobContent, nil
+	}
+
+	var textContent *TextResourceContents
+	if err := pkg.JSONUnmarshal(data, &textContent); err != nil {
+		return nil, err
+	}
+	return textContent, nil
+}
+
 type ResourceContents interface {
 	GetURI() string
 	GetMimeType() string
@@ -255,6 +387,15 @@ type TextResourceContents struct {
 	MimeType string `json:"mimeType,omitempty"`
 }
 
+// NewTextResourceContents creates new text resource contents
+func NewTextResourceContents(uri, mimeType, text string) *TextResourceContents {
+	return &TextResourceContents{
+		URI:      uri,
+		Text:     text,
+		MimeType: mimeType,
+	}
+}
+
 func (t *TextResourceContents) GetURI() string {
 	return t.URI
 }
@@ -264,11 +405,26 @@ func (t *TextResourceContents) GetMimeType() string {
 }
 
 type BlobResourceContents struct {
-	URI      string `json:"uri"`
-	Blob     []byte `json:"blob"`
+	URI string `json:"uri"`
+	// Blob the base64-encoded binary data of the resource
+	Blob     string `json:"blob"`
 	MimeType string `json:"mimeType,omitempty"`
 }
 
+// NewBlobResourceContents creates new blob resource contents, base64-encoding data
+func NewBlobResourceContents(uri, mimeType string, data []byte) *BlobResourceContents {
+	return &BlobResourceContents{
+		URI:      uri,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+		MimeType: mimeType,
+	}
+}
+
+// Data returns the decoded binary data of the resource
+func (b *BlobResourceContents) Data() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(b.Blob)
+}
+
 func (b *BlobResourceContents) GetURI() string {
 	return b.URI
 }
@@ -0,0 +1,62 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestReadResourceResultContents(t *testing.T) {
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	result := NewReadResourceResult([]ResourceContents{
+		NewTextResourceContents("file:///notes.txt", "text/plain", "hello"),
+		NewBlobResourceContents("file:///logo.png", "image/png", data),
+	})
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	want := `{"contents":[{"uri":"file:///notes.txt","text":"hello","mimeType":"text/plain"},` +
+		`{"uri":"file:///logo.png","blob":"iVBORwD/","mimeType":"image/png"}]}`
+	if string(b) != want {
+		t.Fatalf("unexpected json:\n got: %s\nwant: %s", b, want)
+	}
+
+	var got ReadResourceResult
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+	if text, ok := got.Contents[0].(*TextResourceContents); !ok || text.Text != "hello" {
+		t.Fatalf("expected text contents, got %+v", got.Contents[0])
+	}
+	blob, ok := got.Contents[1].(*BlobResourceContents)
+	if !ok {
+		t.Fatalf("expected blob contents, got %+v", got.Contents[1])
+	}
+	if decoded, err := blob.Data(); err != nil || !bytes.Equal(decoded, data) || blob.GetMimeType() != "image/png" {
+		t.Fatalf("unexpected blob contents %+v, decoded %v (%v)", blob, decoded, err)
+	}
+}
+
+func TestByteRangeApply(t *testing.T) {
+	data := []byte("0123456789")
+	tests := []struct {
+		name string
+		r    *ByteRange
+		want string
+	}{
+		{name: "nil range", r: nil, want: "0123456789"},
+		{name: "offset and length", r: &ByteRange{Offset: 2, Length: 3}, want: "234"},
+		{name: "rest of data", r: &ByteRange{Offset: 7}, want: "789"},
+		{name: "length past the end", r: &ByteRange{Offset: 8, Length: 5}, want: "89"},
+		{name: "offset past the end", r: &ByteRange{Offset: 20, Length: 5}, want: ""},
+		{name: "huge length", r: &ByteRange{Offset: 3, Length: math.MaxInt64}, want: "3456789"},
+	}
+	for _, tt := range tests {
+		if got := string(tt.r.Apply(data)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
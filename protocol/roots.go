@@ -1,5 +1,7 @@
 package protocol
 
+import "encoding/json"
+
 // ListRootsRequest represents a request to list root directories
 type ListRootsRequest struct{}
 
@@ -8,6 +10,16 @@ type ListRootsResult struct {
 	Roots []*Root `json:"roots"`
 }
 
+// MarshalJSON encodes a nil Roots as [] for a client exposing no roots
+func (r *ListRootsResult) MarshalJSON() ([]byte, error) {
+	type Alias ListRootsResult
+	aux := Alias(*r)
+	if aux.Roots == nil {
+		aux.Roots = []*Root{}
+	}
+	return json.Marshal(aux)
+}
+
 // Root represents a root directory or file that the server can operate on
 type Root struct {
 	Name string `json:"name,omitempty"`
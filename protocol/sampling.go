@@ -17,6 +17,18 @@ type CreateMessageRequest struct {
 	ModelPreferences *ModelPreferences      `json:"modelPreferences,omitempty"`
 	IncludeContext   string                 `json:"includeContext,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+
+	rawParamsHolder
+}
+
+// UnmarshalJSON keeps the raw params, see RawParams
+func (r *CreateMessageRequest) UnmarshalJSON(data []byte) error {
+	type alias CreateMessageRequest
+	if err := pkg.JSONUnmarshal(data, (*alias)(r)); err != nil {
+		return err
+	}
+	r.keepRawParams(data)
+	return nil
 }
 
 type SamplingMessage struct {
@@ -30,11 +30,24 @@ type Property struct {
 	// Properties describes the properties of an object, if the schema type is Object.
 	Properties map[string]*Property `json:"properties,omitempty"`
 	Required   []string             `json:"required,omitempty"`
-	Enum       []string             `json:"enum,omitempty"`
+	// Enum restricts the value to a fixed set, the values must match the schema type.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Default is the value used when the field is omitted.
+	Default interface{} `json:"default,omitempty"`
+	// Examples are sample values clients may show or prefill argument forms with, the values must match the schema type.
+	Examples []interface{} `json:"examples,omitempty"`
+	// Minimum and Maximum are the inclusive bounds of a number or integer.
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
 }
 
 var schemaCache = pkg.SyncMap[*InputSchema]{}
 
+// GenerateSchema generates the JSON schema of a struct from its fields and tags, the same way tool input schemas are
+func GenerateSchema(v any) (*InputSchema, error) {
+	return generateSchemaFromReqStruct(v)
+}
+
 func generateSchemaFromReqStruct(v any) (*InputSchema, error) {
 	t := reflect.TypeOf(v)
 	for t.Kind() != reflect.Struct {
@@ -51,7 +64,7 @@ func generateSchemaFromReqStruct(v any) (*InputSchema, error) {
 
 	schema := &InputSchema{Type: Object}
 
-	property, err := reflectSchemaByObject(t)
+	property, err := reflectSchemaByObject(t, make(map[reflect.Type]struct{}))
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +84,15 @@ func getTypeUUID(t reflect.Type) string {
 	return t.String()
 }
 
-func reflectSchemaByObject(t reflect.Type) (*Property, error) {
+// reflectSchemaByObject generates the schema of a struct, nested structs, slices and maps are expanded recursively.
+// visiting holds the struct types on the current path, it is used to reject self-referencing types.
+func reflectSchemaByObject(t reflect.Type, visiting map[reflect.Type]struct{}) (*Property, error) {
+	if _, ok := visiting[t]; ok {
+		return nil, fmt.Errorf("recursive type %v is not supported", t)
+	}
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+
 	var (
 		properties      = make(map[string]*Property)
 		requiredFields  = make([]string, 0)
@@ -103,7 +124,7 @@ func reflectSchemaByObject(t reflect.Type) (*Property, error) {
 			required = false
 		}
 
-		item, err := reflectSchemaByType(field.Type)
+		item, err := reflectSchemaByType(field.Type, visiting)
 		if err != nil {
 			return nil, err
 		}
@@ -125,33 +146,46 @@ func reflectSchemaByObject(t reflect.Type) (*Property, error) {
 
 		if v := field.Tag.Get("enum"); v != "" {
 			enumValues := strings.Split(v, ",")
-			for j, value := range enumValues {
-				enumValues[j] = strings.TrimSpace(value)
+			item.Enum = make([]interface{}, 0, len(enumValues))
+			for _, value := range enumValues {
+				enumValue, err := parseTagValue(field.Type, strings.TrimSpace(value))
+				if err != nil {
+					return nil, fmt.Errorf("enum value %q is not compatible with type %v: %w", value, field.Type, err)
+				}
+				item.Enum = append(item.Enum, enumValue)
 			}
+		}
 
-			// Check if enum values are consistent with the field type
-			for _, value := range enumValues {
-				switch field.Type.Kind() {
-				case reflect.String:
-					// No additional processing required for string type
-				case reflect.Int, reflect.Int64:
-					if _, err := strconv.Atoi(value); err != nil {
-						return nil, fmt.Errorf("enum value %q is not compatible with type %v", value, field.Type)
-					}
-				case reflect.Float64:
-					if _, err := strconv.ParseFloat(value, 64); err != nil {
-						return nil, fmt.Errorf("enum value %q is not compatible with type %v", value, field.Type)
-					}
-				default:
-					return nil, fmt.Errorf("unsupported type %v for enum validation", field.Type)
+		if v := field.Tag.Get("examples"); v != "" {
+			exampleValues := strings.Split(v, ",")
+			item.Examples = make([]interface{}, 0, len(exampleValues))
+			for _, value := range exampleValues {
+				exampleValue, err := parseTagValue(field.Type, strings.TrimSpace(value))
+				if err != nil {
+					return nil, fmt.Errorf("example value %q is not compatible with type %v: %w", value, field.Type, err)
 				}
+				item.Examples = append(item.Examples, exampleValue)
 			}
-			item.Enum = enumValues
+		}
+
+		if v := field.Tag.Get("default"); v != "" {
+			defaultValue, err := parseTagValue(field.Type, v)
+			if err != nil {
+				return nil, fmt.Errorf("default value %q is not compatible with type %v: %w", v, field.Type, err)
+			}
+			item.Default = defaultValue
+		}
+
+		if item.Minimum, err = parseBoundTag(field, "minimum"); err != nil {
+			return nil, err
+		}
+		if item.Maximum, err = parseBoundTag(field, "maximum"); err != nil {
+			return nil, err
 		}
 	}
 
 	for _, field := range anonymousFields {
-		object, err := reflectSchemaByObject(field.Type)
+		object, err := reflectSchemaByObject(field.Type, visiting)
 		if err != nil {
 			return nil, err
 		}
@@ -172,7 +206,7 @@ func reflectSchemaByObject(t reflect.Type) (*Property, error) {
 	return property, nil
 }
 
-func reflectSchemaByType(t reflect.Type) (*Property, error) {
+func reflectSchemaByType(t reflect.Type, visiting map[reflect.Type]struct{}) (*Property, error) {
 	s := &Property{}
 
 	switch t.Kind() {
@@ -187,13 +221,13 @@ func reflectSchemaByType(t reflect.Type) (*Property, error) {
 		s.Type = Boolean
 	case reflect.Slice, reflect.Array:
 		s.Type = Array
-		items, err := reflectSchemaByType(t.Elem())
+		items, err := reflectSchemaByType(t.Elem(), visiting)
 		if err != nil {
 			return nil, err
 		}
 		s.Items = items
 	case reflect.Struct:
-		object, err := reflectSchemaByObject(t)
+		object, err := reflectSchemaByObject(t, visiting)
 		if err != nil {
 			return nil, err
 		}
@@ -208,7 +242,7 @@ func reflectSchemaByType(t reflect.Type) (*Property, error) {
 		}
 		s = object
 	case reflect.Ptr:
-		p, err := reflectSchemaByType(t.Elem())
+		p, err := reflectSchemaByType(t.Elem(), visiting)
 		if err != nil {
 			return nil, err
 		}
@@ -221,3 +255,33 @@ func reflectSchemaByType(t reflect.Type) (*Property, error) {
 	}
 	return s, nil
 }
+
+// parseTagValue converts the string value of a struct tag to the Go value matching the field type.
+func parseTagValue(t reflect.Type, value string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	default:
+		return nil, fmt.Errorf("unsupported type %v", t)
+	}
+}
+
+func parseBoundTag(field reflect.StructField, tag string) (*float64, error) {
+	v := field.Tag.Get(tag)
+	if v == "" {
+		return nil, nil
+	}
+	bound, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q for field %s: %w", tag, v, field.Name, err)
+	}
+	return &bound, nil
+}
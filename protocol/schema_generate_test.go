@@ -1,6 +1,9 @@
 package protocol
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"sort"
 	"testing"
 )
@@ -72,19 +75,19 @@ func TestGenerateSchemaFromReqStruct(t *testing.T) {
 					},
 					"string4enum": {
 						Type: String,
-						Enum: []string{"a", "b", "c"},
+						Enum: []interface{}{"a", "b", "c"},
 					},
 					"integer4enum": {
 						Type: Integer,
-						Enum: []string{"1", "2", "3"},
+						Enum: []interface{}{1, 2, 3},
 					},
 					"number4enum": {
 						Type: Number,
-						Enum: []string{"1.1", "2.2", "3.3"},
+						Enum: []interface{}{1.1, 2.2, 3.3},
 					},
 					"number4enum2": {
 						Type: Integer,
-						Enum: []string{"1", "2", "3"},
+						Enum: []interface{}{1, 2, 3},
 					},
 				},
 				Required: []string{"string"},
@@ -107,19 +110,19 @@ func TestGenerateSchemaFromReqStruct(t *testing.T) {
 					},
 					"string4enum": {
 						Type: String,
-						Enum: []string{"a", "b", "c"},
+						Enum: []interface{}{"a", "b", "c"},
 					},
 					"integer4enum": {
 						Type: Integer,
-						Enum: []string{"1", "2", "3"},
+						Enum: []interface{}{1, 2, 3},
 					},
 					"number4enum": {
 						Type: Number,
-						Enum: []string{"1.1", "2.2", "3.3"},
+						Enum: []interface{}{1.1, 2.2, 3.3},
 					},
 					"number4enum2": {
 						Type: Integer,
-						Enum: []string{"1", "2", "3"},
+						Enum: []interface{}{1, 2, 3},
 					},
 				},
 				Required: []string{"string"},
@@ -140,26 +143,26 @@ func TestGenerateSchemaFromReqStruct(t *testing.T) {
 					"extraField": {
 						Type:        String,
 						Description: "extra string enum",
-						Enum:        []string{"a", "b", "c"},
+						Enum:        []interface{}{"a", "b", "c"},
 					},
 					"number": {
 						Type: Number,
 					},
 					"string4enum": {
 						Type: String,
-						Enum: []string{"a", "b", "c"},
+						Enum: []interface{}{"a", "b", "c"},
 					},
 					"integer4enum": {
 						Type: Integer,
-						Enum: []string{"1", "2", "3"},
+						Enum: []interface{}{1, 2, 3},
 					},
 					"number4enum": {
 						Type: Number,
-						Enum: []string{"1.1", "2.2", "3.3"},
+						Enum: []interface{}{1.1, 2.2, 3.3},
 					},
 					"number4enum2": {
 						Type: Integer,
-						Enum: []string{"1", "2", "3"},
+						Enum: []interface{}{1, 2, 3},
 					},
 				},
 				Required: []string{"string"},
@@ -450,14 +453,23 @@ func compareProperty(a, b *Property) bool {
 		}
 	}
 
+	if fmt.Sprint(a.Default) != fmt.Sprint(b.Default) {
+		return false
+	}
+	if !compareBound(a.Minimum, b.Minimum) || !compareBound(a.Maximum, b.Maximum) {
+		return false
+	}
+
 	// 比较Enum字段
 	if len(a.Enum) != len(b.Enum) {
 		return false
 	}
 	aEnumCopy := make([]string, len(a.Enum))
 	bEnumCopy := make([]string, len(b.Enum))
-	copy(aEnumCopy, a.Enum)
-	copy(bEnumCopy, b.Enum)
+	for i := range a.Enum {
+		aEnumCopy[i] = fmt.Sprint(a.Enum[i])
+		bEnumCopy[i] = fmt.Sprint(b.Enum[i])
+	}
 	sort.Strings(aEnumCopy)
 	sort.Strings(bEnumCopy)
 	for i := range aEnumCopy {
@@ -468,3 +480,208 @@ func compareProperty(a, b *Property) bool {
 
 	return true
 }
+
+func compareBound(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestGenerateSchemaWithDefaultAndBounds(t *testing.T) {
+	type testData4Bounds struct {
+		Level  string  `json:"level,omitempty" enum:"low,high" default:"low"`
+		Count  int     `json:"count,omitempty" default:"3" minimum:"1" maximum:"10"`
+		Ratio  float64 `json:"ratio" minimum:"0.5"`
+		Plain  string  `json:"plain"`
+		Labels []int   `json:"labels,omitempty"`
+	}
+
+	minCount, maxCount, minRatio := 1.0, 10.0, 0.5
+	want := &InputSchema{
+		Type: Object,
+		Properties: map[string]*Property{
+			"level":  {Type: String, Enum: []interface{}{"low", "high"}, Default: "low"},
+			"count":  {Type: Integer, Default: 3, Minimum: &minCount, Maximum: &maxCount},
+			"ratio":  {Type: Number, Minimum: &minRatio},
+			"plain":  {Type: String},
+			"labels": {Type: Array, Items: &Property{Type: Integer}},
+		},
+		Required: []string{"ratio", "plain"},
+	}
+
+	got, err := generateSchemaFromReqStruct(testData4Bounds{})
+	if err != nil {
+		t.Fatalf("generateSchemaFromReqStruct: %+v", err)
+	}
+	if !compareInputSchema(got, want) {
+		t.Fatalf("generateSchemaFromReqStruct() got = %+v, want %+v", got, want)
+	}
+
+	// omitted keywords must not appear in the marshalled schema
+	plain, err := json.Marshal(got.Properties["plain"])
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if string(plain) != `{"type":"string"}` {
+		t.Fatalf("unexpected marshalled property: %s", plain)
+	}
+
+	count, err := json.Marshal(got.Properties["count"])
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if string(count) != `{"type":"integer","default":3,"minimum":1,"maximum":10}` {
+		t.Fatalf("unexpected marshalled property: %s", count)
+	}
+}
+
+func TestGenerateSchemaForNestedStruct(t *testing.T) {
+	type address struct {
+		City string   `json:"city" description:"city name"`
+		Tags []string `json:"tags,omitempty"`
+	}
+	type member struct {
+		Name      string     `json:"name"`
+		Addresses []*address `json:"addresses,omitempty"`
+	}
+	type team struct {
+		Leader  member   `json:"leader"`
+		Members []member `json:"members"`
+	}
+
+	wantJSON := `{
+		"type": "object",
+		"properties": {
+			"leader": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"addresses": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {
+								"city": {"type": "string", "description": "city name"},
+								"tags": {"type": "array", "items": {"type": "string"}}
+							},
+							"required": ["city"]
+						}
+					}
+				},
+				"required": ["name"]
+			},
+			"members": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"addresses": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"city": {"type": "string", "description": "city name"},
+									"tags": {"type": "array", "items": {"type": "string"}}
+								},
+								"required": ["city"]
+							}
+						}
+					},
+					"required": ["name"]
+				}
+			}
+		},
+		"required": ["leader", "members"]
+	}`
+
+	schema, err := generateSchemaFromReqStruct(team{})
+	if err != nil {
+		t.Fatalf("generateSchemaFromReqStruct: %+v", err)
+	}
+	gotBytes, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+
+	var got, want map[string]interface{}
+	if err = json.Unmarshal(gotBytes, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err = json.Unmarshal([]byte(wantJSON), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("generated schema not as expected.\ngot  = %s\nwant = %s", gotBytes, wantJSON)
+	}
+
+	var v team
+	if err = VerifyAndUnmarshal([]byte(`{"leader":{"name":"a","addresses":[{"city":"x"}]},"members":[]}`), &v); err != nil {
+		t.Fatalf("VerifyAndUnmarshal: %+v", err)
+	}
+	if v.Leader.Addresses[0].City != "x" {
+		t.Fatalf("unexpected unmarshal result: %+v", v)
+	}
+	if err = VerifyAndUnmarshal([]byte(`{"leader":{"name":"a","addresses":[{"tags":["t"]}]},"members":[]}`), &v); err == nil {
+		t.Fatal("expected validation error for missing nested required field")
+	}
+}
+
+func TestGenerateSchemaForRecursiveStruct(t *testing.T) {
+	type node struct {
+		Value    string  `json:"value"`
+		Children []*node `json:"children,omitempty"`
+	}
+
+	if _, err := generateSchemaFromReqStruct(node{}); err == nil {
+		t.Fatal("expected error for recursive type")
+	}
+}
+
+func TestGenerateSchemaWithExamples(t *testing.T) {
+	type testData4Examples struct {
+		City string `json:"city" examples:"Paris, Tokyo"`
+		Days int    `json:"days,omitempty" examples:"1,7"`
+	}
+
+	tool, err := NewTool("forecast", "weather forecast", testData4Examples{})
+	if err != nil {
+		t.Fatalf("NewTool: %+v", err)
+	}
+	tool.AddExample(map[string]interface{}{"city": "Paris", "days": 3})
+
+	city, err := json.Marshal(tool.InputSchema.Properties["city"])
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if string(city) != `{"type":"string","examples":["Paris","Tokyo"]}` {
+		t.Fatalf("unexpected marshalled property: %s", city)
+	}
+
+	b, err := json.Marshal(NewListToolsResult([]*Tool{tool}, ""))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	var listed ListToolsResult
+	if err = json.Unmarshal(b, &listed); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+	schema := listed.Tools[0].InputSchema
+	if got := schema.Properties["city"].Examples; !reflect.DeepEqual(got, []interface{}{"Paris", "Tokyo"}) {
+		t.Fatalf("unexpected city examples after listing: %v", got)
+	}
+	if got := schema.Properties["days"].Examples; !reflect.DeepEqual(got, []interface{}{float64(1), float64(7)}) {
+		t.Fatalf("unexpected days examples after listing: %v", got)
+	}
+	if want := []interface{}{map[string]interface{}{"city": "Paris", "days": float64(3)}}; !reflect.DeepEqual(schema.Examples, want) {
+		t.Fatalf("unexpected tool examples after listing: %v", schema.Examples)
+	}
+
+	type invalidExamples struct {
+		Days int `json:"days" examples:"one"`
+	}
+	if _, err = generateSchemaFromReqStruct(invalidExamples{}); err == nil {
+		t.Fatal("expected an example incompatible with the field type to be rejected")
+	}
+}
@@ -1,11 +1,13 @@
 package protocol
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"math"
 	"reflect"
-	"strconv"
+	"sort"
+	"strings"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 )
@@ -36,129 +38,261 @@ func VerifyAndUnmarshal(content json.RawMessage, v any) error {
 	}, content, v)
 }
 
+// VerifyAndUnmarshalStrict is like VerifyAndUnmarshal, but the schema of v is generated on demand
+// and fields not declared by v are rejected, so it can tell apart variant argument shapes.
+func VerifyAndUnmarshalStrict(content json.RawMessage, v any) error {
+	if len(content) == 0 {
+		return fmt.Errorf("request arguments is empty")
+	}
+
+	schema, err := generateSchemaFromReqStruct(v)
+	if err != nil {
+		return err
+	}
+
+	var data any
+	if err = pkg.JSONUnmarshal(content, &data); err != nil {
+		return err
+	}
+	objectSchema := Property{Type: ObjectT, Properties: schema.Properties, Required: schema.Required}
+	if err = validateSchema(objectSchema, data); err != nil {
+		return err
+	}
+	if fields := undeclaredFields(objectSchema, data); len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %+v", pkg.ErrJSONUnmarshal, err)
+	}
+	return nil
+}
+
 func verifySchemaAndUnmarshal(schema Property, content []byte, v any) error {
 	var data any
 	err := pkg.JSONUnmarshal(content, &data)
 	if err != nil {
 		return err
 	}
-	if !validate(schema, data) {
-		return errors.New("data validation failed against the provided schema")
+	if err = validateSchema(schema, data); err != nil {
+		return err
 	}
 	return pkg.JSONUnmarshal(content, &v)
 }
 
+// FieldError describes an argument that failed validation, Field is its path in the arguments,
+// e.g. "address.city" or "tags[1]", and is empty for the arguments as a whole
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError is returned when arguments don't match the schema of their target.
+// A handler returning it, or wrapping it, replies with an invalid params error whose data lists the failed fields,
+// so that the client or the model can correct them precisely.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if f.Field == "" {
+			reasons = append(reasons, f.Reason)
+			continue
+		}
+		reasons = append(reasons, f.Field+": "+f.Reason)
+	}
+	return "data validation failed against the provided schema: " + strings.Join(reasons, "; ")
+}
+
 func validate(schema Property, data any) bool {
+	return len(validateValue(schema, data, "")) == 0
+}
+
+// validateSchema validates data against schema and returns a *ValidationError listing every violation
+func validateSchema(schema Property, data any) error {
+	if fields := validateValue(schema, data, ""); len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateValue(schema Property, data any, path string) []FieldError {
 	switch schema.Type {
 	case ObjectT:
-		return validateObject(schema, data)
+		return validateObject(schema, data, path)
 	case Array:
-		return validateArray(schema, data)
+		return validateArray(schema, data, path)
 	case String:
-		str, ok := data.(string)
-		if ok {
-			return validateEnumProperty[string](str, schema.Enum, func(value string, enumValue string) bool {
-				return value == enumValue
-			})
+		if _, ok := data.(string); ok {
+			return validateEnum(schema.Enum, data, path)
 		}
-		return false
 	case Number: // float64 and int
-		if num, ok := data.(float64); ok {
-			return validateEnumProperty[float64](num, schema.Enum, func(value float64, enumValue string) bool {
-				if enumNum, err := strconv.ParseFloat(enumValue, 64); err == nil && value == enumNum {
-					return true
-				}
-				return false
-			})
-		}
-		if num, ok := data.(int); ok {
-			return validateEnumProperty[int](num, schema.Enum, func(value int, enumValue string) bool {
-				if enumNum, err := strconv.Atoi(enumValue); err == nil && value == enumNum {
-					return true
-				}
-				return false
-			})
+		if num, ok := toFloat64(data); ok {
+			return append(validateNumber(schema, num, path), validateEnum(schema.Enum, data, path)...)
 		}
-		return false
 	case Boolean:
-		_, ok := data.(bool)
-		return ok
+		if _, ok := data.(bool); ok {
+			return validateEnum(schema.Enum, data, path)
+		}
 	case Integer:
 		// Golang unmarshals all numbers as float64, so we need to check if the float64 is an integer
-		if num, ok := data.(float64); ok {
-			if num == float64(int64(num)) {
-				return validateEnumProperty[float64](num, schema.Enum, func(value float64, enumValue string) bool {
-					if enumNum, err := strconv.ParseFloat(enumValue, 64); err == nil && value == enumNum {
-						return true
-					}
-					return false
-				})
-			}
-			return false
+		if num, ok := toFloat64(data); ok && num == math.Trunc(num) {
+			return append(validateNumber(schema, num, path), validateEnum(schema.Enum, data, path)...)
 		}
-
-		if num, ok := data.(int); ok {
-			return validateEnumProperty[int](num, schema.Enum, func(value int, enumValue string) bool {
-				if enumNum, err := strconv.Atoi(enumValue); err == nil && value == enumNum {
-					return true
-				}
-				return false
-			})
-		}
-
-		if num, ok := data.(int64); ok {
-			return validateEnumProperty[int64](num, schema.Enum, func(value int64, enumValue string) bool {
-				if enumNum, err := strconv.Atoi(enumValue); err == nil && value == int64(enumNum) {
-					return true
-				}
-				return false
-			})
-		}
-		return false
 	case Null:
-		return data == nil
+		if data == nil {
+			return nil
+		}
 	default:
-		return false
+		return []FieldError{{Field: path, Reason: fmt.Sprintf("unsupported schema type %q", schema.Type)}}
 	}
+	return []FieldError{{Field: path, Reason: fmt.Sprintf("expected %s, got %s", schema.Type, jsonTypeOf(data))}}
 }
 
-func validateObject(schema Property, data any) bool {
+func validateNumber(schema Property, num float64, path string) []FieldError {
+	if schema.Minimum != nil && num < *schema.Minimum {
+		return []FieldError{{Field: path, Reason: fmt.Sprintf("must be greater than or equal to %v", *schema.Minimum)}}
+	}
+	if schema.Maximum != nil && num > *schema.Maximum {
+		return []FieldError{{Field: path, Reason: fmt.Sprintf("must be less than or equal to %v", *schema.Maximum)}}
+	}
+	return nil
+}
+
+func validateObject(schema Property, data any, path string) []FieldError {
 	dataMap, ok := data.(map[string]any)
 	if !ok {
-		return false
+		return []FieldError{{Field: path, Reason: fmt.Sprintf("expected object, got %s", jsonTypeOf(data))}}
 	}
+	var fields []FieldError
 	for _, field := range schema.Required {
 		if _, exists := dataMap[field]; !exists {
-			return false
+			fields = append(fields, FieldError{Field: joinFieldPath(path, field), Reason: "is required"})
 		}
 	}
-	for key, valueSchema := range schema.Properties {
-		value, exists := dataMap[key]
-		if exists && !validate(*valueSchema, value) {
-			return false
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, exists := dataMap[key]; exists {
+			fields = append(fields, validateValue(*schema.Properties[key], value, joinFieldPath(path, key))...)
 		}
 	}
-	return true
+	return fields
 }
 
-func validateArray(schema Property, data any) bool {
+func validateArray(schema Property, data any, path string) []FieldError {
 	dataArray, ok := data.([]any)
 	if !ok {
-		return false
+		return []FieldError{{Field: path, Reason: fmt.Sprintf("expected array, got %s", jsonTypeOf(data))}}
 	}
-	for _, item := range dataArray {
-		if !validate(*schema.Items, item) {
-			return false
-		}
+	if schema.Items == nil {
+		return nil
+	}
+	var fields []FieldError
+	for i, item := range dataArray {
+		fields = append(fields, validateValue(*schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
 	}
-	return true
+	return fields
 }
 
-func validateEnumProperty[T any](data T, enum []string, compareFunc func(T, string) bool) bool {
+func validateEnum(enum []interface{}, data any, path string) []FieldError {
 	for _, enumValue := range enum {
-		if compareFunc(data, enumValue) {
-			return true
+		if enumEqual(data, enumValue) {
+			return nil
+		}
+	}
+	if len(enum) == 0 {
+		return nil
+	}
+	return []FieldError{{Field: path, Reason: fmt.Sprintf("must be one of %v", enum)}}
+}
+
+// undeclaredFields reports the fields of the arguments object the schema doesn't declare
+func undeclaredFields(schema Property, data any) []FieldError {
+	dataMap, ok := data.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var fields []FieldError
+	for key := range dataMap {
+		if _, declared := schema.Properties[key]; !declared {
+			fields = append(fields, FieldError{Field: key, Reason: "is not declared"})
 		}
 	}
-	return len(enum) == 0
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func jsonTypeOf(data any) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		if _, ok := toFloat64(data); ok {
+			return "number"
+		}
+		return fmt.Sprintf("%T", data)
+	}
+}
+
+// enumEqual compares numbers by value regardless of their Go type, e.g. float64(1) equals int64(1).
+func enumEqual(data any, enumValue any) bool {
+	if num, ok := toFloat64(data); ok {
+		enumNum, ok := toFloat64(enumValue)
+		return ok && num == enumNum
+	}
+	return reflect.DeepEqual(data, enumValue)
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch num := v.(type) {
+	case float64:
+		return num, true
+	case float32:
+		return float64(num), true
+	case int:
+		return float64(num), true
+	case int8:
+		return float64(num), true
+	case int16:
+		return float64(num), true
+	case int32:
+		return float64(num), true
+	case int64:
+		return float64(num), true
+	case uint:
+		return float64(num), true
+	case uint8:
+		return float64(num), true
+	case uint16:
+		return float64(num), true
+	case uint32:
+		return float64(num), true
+	case uint64:
+		return float64(num), true
+	default:
+		return 0, false
+	}
 }
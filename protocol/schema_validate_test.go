@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -19,18 +20,21 @@ func Test_Validate(t *testing.T) {
 		// string integer number boolean
 		{"", args{data: "ABC", schema: Property{Type: String}}, true},
 		{"", args{data: 123, schema: Property{Type: String}}, false},
-		{"", args{data: "a", schema: Property{Type: String, Enum: []string{"a", "b", "c"}}}, true},
-		{"", args{data: "d", schema: Property{Type: String, Enum: []string{"a", "b", "c"}}}, false},
+		{"", args{data: "a", schema: Property{Type: String, Enum: []interface{}{"a", "b", "c"}}}, true},
+		{"", args{data: "d", schema: Property{Type: String, Enum: []interface{}{"a", "b", "c"}}}, false},
 		{"", args{data: 123, schema: Property{Type: Integer}}, true},
 		{"", args{data: 123.4, schema: Property{Type: Integer}}, false},
-		{"", args{data: 1, schema: Property{Type: Integer, Enum: []string{"1", "2", "3"}}}, true},
-		{"", args{data: 4, schema: Property{Type: Integer, Enum: []string{"1", "2", "3"}}}, false},
+		{"", args{data: 1, schema: Property{Type: Integer, Enum: []interface{}{1, 2, 3}}}, true},
+		{"", args{data: 4, schema: Property{Type: Integer, Enum: []interface{}{1, 2, 3}}}, false},
 		{"", args{data: "ABC", schema: Property{Type: Number}}, false},
 		{"", args{data: 123, schema: Property{Type: Number}}, true},
-		{"", args{data: 1.1, schema: Property{Type: Number, Enum: []string{"1.1", "2.2", "3.3"}}}, true},
-		{"", args{data: 4.4, schema: Property{Type: Number, Enum: []string{"1.1", "2.2", "3.3"}}}, false},
-		{"", args{data: 1, schema: Property{Type: Number, Enum: []string{"1", "2", "3"}}}, true},
-		{"", args{data: 4, schema: Property{Type: Number, Enum: []string{"1", "2", "3"}}}, false},
+		{"", args{data: 1.1, schema: Property{Type: Number, Enum: []interface{}{1.1, 2.2, 3.3}}}, true},
+		{"", args{data: 4.4, schema: Property{Type: Number, Enum: []interface{}{1.1, 2.2, 3.3}}}, false},
+		{"", args{data: 1, schema: Property{Type: Number, Enum: []interface{}{1, 2, 3}}}, true},
+		{"", args{data: 4, schema: Property{Type: Number, Enum: []interface{}{1, 2, 3}}}, false},
+		{"", args{data: 5.0, schema: Property{Type: Integer, Minimum: floatPtr(1), Maximum: floatPtr(10)}}, true},
+		{"", args{data: 11.0, schema: Property{Type: Integer, Minimum: floatPtr(1), Maximum: floatPtr(10)}}, false},
+		{"", args{data: 0.4, schema: Property{Type: Number, Minimum: floatPtr(0.5)}}, false},
 		{"", args{data: false, schema: Property{Type: Boolean}}, true},
 		{"", args{data: 123, schema: Property{Type: Boolean}}, false},
 		{"", args{data: nil, schema: Property{Type: Null}}, true},
@@ -48,22 +52,22 @@ func Test_Validate(t *testing.T) {
 		}, false},
 		{"", args{
 			data: []any{"a"}, schema: Property{
-				Type: Array, Items: &Property{Type: String, Enum: []string{"a", "b", "c"}},
+				Type: Array, Items: &Property{Type: String, Enum: []interface{}{"a", "b", "c"}},
 			},
 		}, true},
 		{"", args{
 			data: []any{"a", "b", "c"}, schema: Property{
-				Type: Array, Items: &Property{Type: String, Enum: []string{"a", "b", "c"}},
+				Type: Array, Items: &Property{Type: String, Enum: []interface{}{"a", "b", "c"}},
 			},
 		}, true},
 		{"", args{
 			data: []any{"d"}, schema: Property{
-				Type: Array, Items: &Property{Type: String, Enum: []string{"a", "b", "c"}},
+				Type: Array, Items: &Property{Type: String, Enum: []interface{}{"a", "b", "c"}},
 			},
 		}, false},
 		{"", args{
 			data: []any{"a", "b", "c", "d"}, schema: Property{
-				Type: Array, Items: &Property{Type: String, Enum: []string{"a", "b", "c"}},
+				Type: Array, Items: &Property{Type: String, Enum: []interface{}{"a", "b", "c"}},
 			},
 		}, false},
 		{"", args{
@@ -78,22 +82,22 @@ func Test_Validate(t *testing.T) {
 		}, false},
 		{"", args{
 			data: []any{1}, schema: Property{
-				Type: Array, Items: &Property{Type: Integer, Enum: []string{"1", "2", "3"}},
+				Type: Array, Items: &Property{Type: Integer, Enum: []interface{}{1, 2, 3}},
 			},
 		}, true},
 		{"", args{
 			data: []any{1, 2, 3}, schema: Property{
-				Type: Array, Items: &Property{Type: Integer, Enum: []string{"1", "2", "3"}},
+				Type: Array, Items: &Property{Type: Integer, Enum: []interface{}{1, 2, 3}},
 			},
 		}, true},
 		{"", args{
 			data: []any{1, 2, 3, 4}, schema: Property{
-				Type: Array, Items: &Property{Type: Integer, Enum: []string{"1", "2", "3"}},
+				Type: Array, Items: &Property{Type: Integer, Enum: []interface{}{1, 2, 3}},
 			},
 		}, false},
 		{"", args{
 			data: []any{4}, schema: Property{
-				Type: Array, Items: &Property{Type: Integer, Enum: []string{"1", "2", "3"}},
+				Type: Array, Items: &Property{Type: Integer, Enum: []interface{}{1, 2, 3}},
 			},
 		}, false},
 		// object
@@ -136,11 +140,11 @@ func Test_Validate(t *testing.T) {
 			"array":      []any{1, 2, 3},
 		}, schema: Property{
 			Type: ObjectT, Properties: map[string]*Property{
-				"string":     {Type: String, Enum: []string{"a", "b", "c"}},
-				"integer":    {Type: Integer, Enum: []string{"1", "2", "3"}},
-				"number":     {Type: Number, Enum: []string{"1.1", "2.2", "3.3"}},
-				"number4Int": {Type: Number, Enum: []string{"1", "2", "3"}},
-				"array":      {Type: Array, Items: &Property{Type: Number}, Enum: []string{"1", "2", "3"}},
+				"string":     {Type: String, Enum: []interface{}{"a", "b", "c"}},
+				"integer":    {Type: Integer, Enum: []interface{}{1, 2, 3}},
+				"number":     {Type: Number, Enum: []interface{}{1.1, 2.2, 3.3}},
+				"number4Int": {Type: Number, Enum: []interface{}{1, 2, 3}},
+				"array":      {Type: Array, Items: &Property{Type: Number}, Enum: []interface{}{1, 2, 3}},
 			},
 			Required: []string{"string"},
 		}}, true},
@@ -152,11 +156,11 @@ func Test_Validate(t *testing.T) {
 			"array":      []any{4},
 		}, schema: Property{
 			Type: ObjectT, Properties: map[string]*Property{
-				"string":     {Type: String, Enum: []string{"a", "b", "c"}},
-				"integer":    {Type: Integer, Enum: []string{"1", "2", "3"}},
-				"number":     {Type: Number, Enum: []string{"1.1", "2.2", "3.3"}},
-				"number4Int": {Type: Number, Enum: []string{"1", "2", "3"}},
-				"array":      {Type: Array, Items: &Property{Type: Number}, Enum: []string{"1", "2", "3"}},
+				"string":     {Type: String, Enum: []interface{}{"a", "b", "c"}},
+				"integer":    {Type: Integer, Enum: []interface{}{1, 2, 3}},
+				"number":     {Type: Number, Enum: []interface{}{1.1, 2.2, 3.3}},
+				"number4Int": {Type: Number, Enum: []interface{}{1, 2, 3}},
+				"array":      {Type: Array, Items: &Property{Type: Number}, Enum: []interface{}{1, 2, 3}},
 			},
 			Required: []string{"string"},
 		}}, false},
@@ -462,3 +466,36 @@ func TestVerifyAndUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestValidationErrorFields(t *testing.T) {
+	type address struct {
+		City string `json:"city" required:"true"`
+	}
+	type order struct {
+		Size      string    `json:"size" enum:"small,large"`
+		Addresses []address `json:"addresses"`
+	}
+
+	var v order
+	err := VerifyAndUnmarshalStrict(json.RawMessage(`{"size":"medium","addresses":[{"city":"x"},{}],"note":1}`), &v)
+	var validErr *ValidationError
+	if !errors.As(err, &validErr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	want := []FieldError{
+		{Field: "addresses[1].city", Reason: "is required"},
+		{Field: "size", Reason: "must be one of [small large]"},
+	}
+	if !reflect.DeepEqual(validErr.Fields, want) {
+		t.Fatalf("unexpected fields %+v", validErr.Fields)
+	}
+
+	err = VerifyAndUnmarshalStrict(json.RawMessage(`{"size":"small","addresses":[],"note":1}`), &v)
+	if !errors.As(err, &validErr) || !reflect.DeepEqual(validErr.Fields, []FieldError{{Field: "note", Reason: "is not declared"}}) {
+		t.Fatalf("expected undeclared field note to be reported, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/hhfgeg/go-mcp/pkg"
@@ -14,8 +15,19 @@ type ListToolsRequest struct {
 
 // ListToolsResult represents the response to a list tools request
 type ListToolsResult struct {
-	Tools      []*Tool `json:"tools"`
-	NextCursor Cursor  `json:"nextCursor,omitempty"`
+	Meta       map[string]interface{} `json:"_meta,omitempty"`
+	Tools      []*Tool                `json:"tools"`
+	NextCursor Cursor                 `json:"nextCursor,omitempty"`
+}
+
+// MarshalJSON encodes a nil Tools as [], clients reject a tools/list result without the array
+func (r *ListToolsResult) MarshalJSON() ([]byte, error) {
+	type Alias ListToolsResult
+	aux := Alias(*r)
+	if aux.Tools == nil {
+		aux.Tools = []*Tool{}
+	}
+	return json.Marshal(aux)
 }
 
 // ToolAnnotations contains hints about the tool's behavior
@@ -50,11 +62,15 @@ type Tool struct {
 	InputSchema InputSchema `json:"inputSchema"`
 
 	// OutputSchema defines expected output structure for the tool using Optional JSON Schema
-	OutputSchema OutputSchema `json:"outputSchema"`
+	OutputSchema *OutputSchema `json:"outputSchema,omitempty"`
 
 	// Annotations provides additional hints about the tool's behavior
 	Annotations *ToolAnnotations `json:"annotations,omitempty"`
 
+	// SchemaVersion optionally identifies the revision of the tool's schemas,
+	// clients caching schemas can compare it to detect changes and refresh
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+
 	RawInputSchema json.RawMessage `json:"-"`
 }
 
@@ -66,6 +82,55 @@ func (t *Tool) GetName() string {
 	return t.Name
 }
 
+// SetTitle sets the human-readable title annotation of the tool
+func (t *Tool) SetTitle(title string) *Tool {
+	t.annotations().Title = title
+	return t
+}
+
+// SetReadOnlyHint marks whether the tool does not modify its environment
+func (t *Tool) SetReadOnlyHint(readOnly bool) *Tool {
+	t.annotations().ReadOnlyHint = &readOnly
+	return t
+}
+
+// SetDestructiveHint marks whether the tool may perform destructive updates
+func (t *Tool) SetDestructiveHint(destructive bool) *Tool {
+	t.annotations().DestructiveHint = &destructive
+	return t
+}
+
+// SetIdempotentHint marks whether repeated calls with the same arguments have no additional effect
+func (t *Tool) SetIdempotentHint(idempotent bool) *Tool {
+	t.annotations().IdempotentHint = &idempotent
+	return t
+}
+
+// AddExample adds a sample of the arguments of a tool call to the input schema, e.g. for clients to prefill argument forms
+func (t *Tool) AddExample(arguments map[string]interface{}) *Tool {
+	t.InputSchema.Examples = append(t.InputSchema.Examples, arguments)
+	return t
+}
+
+// SetSchemaVersion sets the revision of the tool's schemas
+func (t *Tool) SetSchemaVersion(version string) *Tool {
+	t.SchemaVersion = version
+	return t
+}
+
+// SetOpenWorldHint marks whether the tool may interact with an "open world" of external entities
+func (t *Tool) SetOpenWorldHint(openWorld bool) *Tool {
+	t.annotations().OpenWorldHint = &openWorld
+	return t
+}
+
+func (t *Tool) annotations() *ToolAnnotations {
+	if t.Annotations == nil {
+		t.Annotations = &ToolAnnotations{}
+	}
+	return t.Annotations
+}
+
 func (t *Tool) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{}, 4)
 
@@ -81,11 +146,15 @@ func (t *Tool) MarshalJSON() ([]byte, error) {
 		}
 		m["inputSchema"] = t.RawInputSchema
 	} else {
-		// Use the structured InputSchema
-		m["inputSchema"] = t.InputSchema
+		// Use the structured InputSchema, the type of a tool's input schema is always object
+		inputSchema := t.InputSchema
+		if inputSchema.Type == "" {
+			inputSchema.Type = Object
+		}
+		m["inputSchema"] = inputSchema
 	}
 
-	if t.OutputSchema.Properties != nil {
+	if t.OutputSchema != nil {
 		m["outputSchema"] = t.OutputSchema
 	}
 
@@ -94,6 +163,10 @@ func (t *Tool) MarshalJSON() ([]byte, error) {
 		m["annotations"] = t.Annotations
 	}
 
+	if t.SchemaVersion != "" {
+		m["schemaVersion"] = t.SchemaVersion
+	}
+
 	return json.Marshal(m)
 }
 
@@ -106,6 +179,8 @@ type InputSchema struct {
 	Type       InputSchemaType      `json:"type"`
 	Properties map[string]*Property `json:"properties,omitempty"`
 	Required   []string             `json:"required,omitempty"`
+	// Examples are sample argument objects of the whole tool call
+	Examples []interface{} `json:"examples,omitempty"`
 }
 
 // OutputSchema represents a Optional JSON Schema object defining expected output structure for a tool
@@ -117,6 +192,48 @@ type CallToolRequest struct {
 	Name         string                 `json:"name"`
 	Arguments    map[string]interface{} `json:"arguments,omitempty"`
 	RawArguments json.RawMessage        `json:"-"`
+
+	coercion ArgumentCoercion
+	rawParamsHolder
+}
+
+// SetArgumentCoercion sets how BindArguments treats arguments whose type doesn't match their field,
+// servers set it from their configuration before calling the handler
+func (r *CallToolRequest) SetArgumentCoercion(coercion ArgumentCoercion) {
+	r.coercion = coercion
+}
+
+// BindArguments unmarshals the arguments into v, e.g. a pointer to a struct with json tags, sparing type assertions
+// on Arguments. Numeric fields accept numeric strings and integral floats unless strict coercion is set,
+// an argument of the wrong type fails with an invalid params error naming it.
+func (r *CallToolRequest) BindArguments(v interface{}) error {
+	if len(r.RawArguments) != 0 {
+		return bindArguments(r.RawArguments, v, r.coercion)
+	}
+	raw, err := json.Marshal(r.Arguments)
+	if err != nil {
+		return err
+	}
+	return bindArguments(raw, v, r.coercion)
+}
+
+// bindArguments unmarshals the JSON arguments of a request into v, failing with an invalid params error
+// that names the offending argument
+func bindArguments(raw json.RawMessage, v interface{}, coercion ArgumentCoercion) error {
+	if coercion == ArgumentCoercionLenient {
+		raw = coerceArguments(raw, v)
+	}
+	err := json.Unmarshal(raw, v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return NewInvalidParamsError(fmt.Sprintf("invalid argument %q: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value),
+			map[string]interface{}{"argument": typeErr.Field})
+	}
+	return NewInvalidParamsError(fmt.Sprintf("invalid arguments: %v", err), nil)
 }
 
 func (r *CallToolRequest) UnmarshalJSON(data []byte) error {
@@ -133,6 +250,7 @@ func (r *CallToolRequest) UnmarshalJSON(data []byte) error {
 	}
 
 	r.RawArguments = temp.Arguments
+	r.keepRawParams(data)
 
 	if len(r.RawArguments) != 0 {
 		if err := pkg.JSONUnmarshal(r.RawArguments, &r.Arguments); err != nil {
@@ -168,7 +286,19 @@ func (r *CallToolRequest) MarshalJSON() ([]byte, error) {
 // CallToolResult represents the response to a tool call
 type CallToolResult struct {
 	Content []Content `json:"content"`
-	IsError bool      `json:"isError,omitempty"`
+	// StructuredContent is the result as a JSON object, conforming to the tool's output schema if it has one
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// MarshalJSON encodes a nil Content as [], e.g. for a tool returning only structured content
+func (r *CallToolResult) MarshalJSON() ([]byte, error) {
+	type Alias CallToolResult
+	aux := Alias(*r)
+	if aux.Content == nil {
+		aux.Content = []Content{}
+	}
+	return json.Marshal(aux)
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface for CallToolResult
@@ -186,42 +316,11 @@ func (r *CallToolResult) UnmarshalJSON(data []byte) error {
 
 	r.Content = make([]Content, len(aux.Content))
 	for i, content := range aux.Content {
-		// Try to unmarshal content as TextContent first
-		var textContent *TextContent
-		if err := pkg.JSONUnmarshal(content, &textContent); err == nil {
-			r.Content[i] = textContent
-			continue
-		}
-
-		// Try to unmarshal content as ImageContent
-		var imageContent *ImageContent
-		if err := pkg.JSONUnmarshal(content, &imageContent); err == nil {
-			r.Content[i] = imageContent
-			continue
-		}
-
-		// Try to unmarshal content as AudioContent
-		var audioContent *AudioContent
-		if err := pkg.JSONUnmarshal(content, &audioContent); err == nil {
-			r.Content[i] = audioContent
-			continue
-		}
-
-		// Try to unmarshal content as ResourceLink
-		var resourceLink *ResourceLink
-		if err := pkg.JSONUnmarshal(content, &resourceLink); err == nil {
-			r.Content[i] = resourceLink
-			continue
-		}
-
-		// Try to unmarshal content as embeddedResource
-		var embeddedResource *EmbeddedResource
-		if err := pkg.JSONUnmarshal(content, &embeddedResource); err == nil {
-			r.Content[i] = embeddedResource
-			return nil
+		c, err := unmarshalContent(content)
+		if err != nil {
+			return fmt.Errorf("content at index %d: %w", i, err)
 		}
-
-		return fmt.Errorf("unknown content type at index %d", i)
+		r.Content[i] = c
 	}
 
 	return nil
@@ -300,6 +399,58 @@ func NewCallToolResult(content []Content, isError bool) *CallToolResult {
 	}
 }
 
+// NewErrorResult creates a tool result that reports a failure to the model, e.g. a city that can't be found.
+// Use it for errors the model can act on, they are returned as a successful response with isError set.
+// Failures of the call itself, such as an unknown tool or malformed arguments, should instead be returned
+// as a *Error from the handler so that the client receives a JSON-RPC error.
+func NewErrorResult(format string, args ...interface{}) *CallToolResult {
+	return NewCallToolResult([]Content{&TextContent{Type: "text", Text: fmt.Sprintf(format, args...)}}, true)
+}
+
+// ResultBuilder assembles a CallToolResult from several content blocks
+type ResultBuilder struct {
+	content []Content
+	isError bool
+}
+
+// NewResultBuilder creates an empty ResultBuilder
+func NewResultBuilder() *ResultBuilder {
+	return &ResultBuilder{}
+}
+
+// AddText appends a text content block
+func (b *ResultBuilder) AddText(text string) *ResultBuilder {
+	return b.add(&TextContent{Type: "text", Text: text})
+}
+
+// AddImage appends an image content block
+func (b *ResultBuilder) AddImage(data []byte, mimeType string) *ResultBuilder {
+	return b.add(&ImageContent{Type: "image", Data: data, MimeType: mimeType})
+}
+
+// AddResourceLink appends a link to a resource
+func (b *ResultBuilder) AddResourceLink(uri, name string) *ResultBuilder {
+	return b.add(NewResourceLink(uri, name))
+}
+
+// SetError marks the result as a tool error
+func (b *ResultBuilder) SetError(isError bool) *ResultBuilder {
+	b.isError = isError
+	return b
+}
+
+func (b *ResultBuilder) add(content Content) *ResultBuilder {
+	b.content = append(b.content, content)
+	return b
+}
+
+// Build returns the assembled CallToolResult
+func (b *ResultBuilder) Build() *CallToolResult {
+	content := make([]Content, len(b.content))
+	copy(content, b.content)
+	return NewCallToolResult(content, b.isError)
+}
+
 // NewToolListChangedNotification creates a new tool list changed notification
 func NewToolListChangedNotification() *ToolListChangedNotification {
 	return &ToolListChangedNotification{}
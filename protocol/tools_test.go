@@ -0,0 +1,299 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToolAnnotationsSetter(t *testing.T) {
+	tool := NewToolWithInputSchema("read_file", "read a file", InputSchema{Type: Object}).
+		SetTitle("Read File").
+		SetReadOnlyHint(true).
+		SetOpenWorldHint(false)
+
+	b, err := json.Marshal(tool)
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+
+	want := `{"annotations":{"title":"Read File","readOnlyHint":true,"openWorldHint":false},` +
+		`"description":"read a file","inputSchema":{"type":"object"},"name":"read_file"}`
+	if string(b) != want {
+		t.Fatalf("tool not marshalled as expected.\ngot  = %s\nwant = %s", b, want)
+	}
+}
+
+func TestMinimalMarshalling(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "tool", v: &Tool{Name: "ping"}, want: `{"inputSchema":{"type":"object"},"name":"ping"}`},
+		{name: "tool with output schema", v: &Tool{Name: "ping", OutputSchema: &OutputSchema{Type: Object}}, want: `{"inputSchema":{"type":"object"},"name":"ping","outputSchema":{"type":"object"}}`},
+		{name: "prompt", v: &Prompt{Name: "greet"}, want: `{"name":"greet"}`},
+		{name: "resource", v: &Resource{Name: "readme", URI: "file:///README.md"}, want: `{"name":"readme","uri":"file:///README.md"}`},
+		{name: "call tool result", v: &CallToolResult{}, want: `{"content":[]}`},
+		{name: "list tools result", v: &ListToolsResult{}, want: `{"tools":[]}`},
+		{name: "list prompts result", v: &ListPromptsResult{}, want: `{"prompts":[]}`},
+		{name: "get prompt result", v: &GetPromptResult{}, want: `{"messages":[]}`},
+		{name: "list resources result", v: &ListResourcesResult{}, want: `{"resources":[]}`},
+		{name: "list resource templates result", v: &ListResourceTemplatesResult{}, want: `{"resourceTemplates":[]}`},
+		{name: "read resource result", v: &ReadResourceResult{}, want: `{"contents":[]}`},
+		{name: "list roots result", v: &ListRootsResult{}, want: `{"roots":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("json Marshal: %+v", err)
+			}
+			if string(b) != tt.want {
+				t.Fatalf("not marshalled as expected.\ngot  = %s\nwant = %s", b, tt.want)
+			}
+		})
+	}
+
+	// a minimal tool survives a round trip unchanged
+	var tool Tool
+	if err := json.Unmarshal([]byte(tests[0].want), &tool); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+	b, err := json.Marshal(&tool)
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if string(b) != tests[0].want {
+		t.Fatalf("tool changed in round trip.\ngot  = %s\nwant = %s", b, tests[0].want)
+	}
+}
+
+func TestCallToolResultWithResourceLinks(t *testing.T) {
+	link := NewResourceLink("file:///project/README.md", "README.md")
+	link.MimeType = "text/markdown"
+
+	result := NewCallToolResult([]Content{
+		&TextContent{Type: "text", Text: "found 2 files"},
+		link,
+		NewResourceLink("file:///project/main.go", "main.go"),
+	}, false)
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+
+	var got CallToolResult
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+
+	if len(got.Content) != 3 {
+		t.Fatalf("expected 3 contents, got %d", len(got.Content))
+	}
+	if text, ok := got.Content[0].(*TextContent); !ok || text.Text != "found 2 files" {
+		t.Fatalf("expected text content first, got %#v", got.Content[0])
+	}
+	gotLink, ok := got.Content[1].(*ResourceLink)
+	if !ok {
+		t.Fatalf("expected resource link, got %#v", got.Content[1])
+	}
+	if gotLink.Type != "resource_link" || gotLink.URI != link.URI || gotLink.Name != link.Name || gotLink.MimeType != link.MimeType {
+		t.Fatalf("resource link not decoded as expected: %#v", gotLink)
+	}
+	if _, ok = got.Content[2].(*ResourceLink); !ok {
+		t.Fatalf("expected resource link, got %#v", got.Content[2])
+	}
+}
+
+func TestBindArguments(t *testing.T) {
+	type forecastArgs struct {
+		City string `json:"city"`
+		Days int    `json:"days"`
+	}
+
+	var req CallToolRequest
+	if err := json.Unmarshal([]byte(`{"name":"forecast","arguments":{"city":"Paris","days":3}}`), &req); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+	var args forecastArgs
+	if err := req.BindArguments(&args); err != nil {
+		t.Fatalf("BindArguments: %+v", err)
+	}
+	if args != (forecastArgs{City: "Paris", Days: 3}) {
+		t.Fatalf("unexpected arguments %+v", args)
+	}
+
+	// arguments set in code instead of unmarshalled
+	req = CallToolRequest{Name: "forecast", Arguments: map[string]interface{}{"city": "Tokyo", "days": "three"}}
+	err := req.BindArguments(&args)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != InvalidParams || !strings.Contains(rpcErr.Message, `"days"`) {
+		t.Fatalf("expected an invalid params error naming days, got %v", err)
+	}
+
+	type promptArgs struct {
+		Topic string `json:"topic"`
+		Depth int    `json:"depth,string"`
+	}
+	var prompt promptArgs
+	promptReq := NewGetPromptRequest("explain", map[string]string{"topic": "channels", "depth": "2"})
+	if err = promptReq.BindArguments(&prompt); err != nil {
+		t.Fatalf("BindArguments: %+v", err)
+	}
+	if prompt != (promptArgs{Topic: "channels", Depth: 2}) {
+		t.Fatalf("unexpected prompt arguments %+v", prompt)
+	}
+}
+
+func TestArgumentCoercion(t *testing.T) {
+	type window struct {
+		From int `json:"from"`
+	}
+	type Paging struct {
+		Limit uint `json:"limit"`
+	}
+	type searchArgs struct {
+		Paging
+		Query     string      `json:"query"`
+		Days      int         `json:"days"`
+		Threshold float64     `json:"threshold"`
+		Max       *int64      `json:"max"`
+		IDs       []int       `json:"ids"`
+		Window    window      `json:"window"`
+		Quoted    int         `json:"quoted,string"`
+		Extra     yesOrNo     `json:"extra"`
+		Untyped   interface{} `json:"untyped"`
+	}
+
+	req := CallToolRequest{Name: "search", Arguments: map[string]interface{}{
+		"query":     "42",
+		"days":      "3",
+		"threshold": "0.5",
+		"max":       7.0,
+		"ids":       []interface{}{1.0, "2", " 3 "},
+		"window":    map[string]interface{}{"from": "10"},
+		"limit":     "20",
+		"quoted":    "5",
+		"extra":     "yes",
+		"untyped":   "8",
+	}}
+	var args searchArgs
+	if err := req.BindArguments(&args); err != nil {
+		t.Fatalf("BindArguments: %+v", err)
+	}
+	if args.Query != "42" || args.Days != 3 || args.Threshold != 0.5 || args.Max == nil || *args.Max != 7 ||
+		len(args.IDs) != 3 || args.IDs[2] != 3 || args.Window.From != 10 || args.Limit != 20 || args.Quoted != 5 ||
+		!args.Extra || args.Untyped != "8" {
+		t.Fatalf("unexpected coerced arguments %+v", args)
+	}
+
+	for _, days := range []interface{}{3.5, "three"} {
+		req = CallToolRequest{Name: "search", Arguments: map[string]interface{}{"days": days}}
+		var rpcErr *Error
+		if err := req.BindArguments(&args); !errors.As(err, &rpcErr) || !strings.Contains(rpcErr.Message, `"days"`) {
+			t.Fatalf("expected days=%v to be rejected, got %v", days, err)
+		}
+	}
+
+	req = CallToolRequest{Name: "search", Arguments: map[string]interface{}{"days": "3"}}
+	req.SetArgumentCoercion(ArgumentCoercionStrict)
+	var rpcErr *Error
+	if err := req.BindArguments(&args); !errors.As(err, &rpcErr) || rpcErr.Code != InvalidParams {
+		t.Fatalf("expected strict coercion to reject a numeric string, got %v", err)
+	}
+
+	var prompt struct {
+		Depth int `json:"depth"`
+	}
+	if err := NewGetPromptRequest("explain", map[string]string{"depth": "2"}).BindArguments(&prompt); err != nil || prompt.Depth != 2 {
+		t.Fatalf("expected a numeric prompt argument to be bound, got %+v, %v", prompt, err)
+	}
+}
+
+// yesOrNo unmarshals itself, coercion leaves it alone
+type yesOrNo bool
+
+func (b *yesOrNo) UnmarshalJSON(data []byte) error {
+	*b = string(data) == `"yes"`
+	return nil
+}
+
+func TestResultBuilder(t *testing.T) {
+	result := NewResultBuilder().
+		AddText("forecast ready").
+		AddImage([]byte{0x89, 0x50}, "image/png").
+		AddResourceLink("file:///forecast.csv", "forecast.csv").
+		SetError(true).
+		Build()
+
+	if !result.IsError {
+		t.Fatal("expected an error result")
+	}
+	types := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		types = append(types, content.GetType())
+	}
+	if got := strings.Join(types, ","); got != "text,image,resource_link" {
+		t.Fatalf("unexpected content types %s", got)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	var decoded CallToolResult
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+	if link, ok := decoded.Content[2].(*ResourceLink); !ok || link.URI != "file:///forecast.csv" {
+		t.Fatalf("unexpected resource link %+v", decoded.Content[2])
+	}
+}
+
+func TestNewErrorResult(t *testing.T) {
+	result := NewErrorResult("city %q not found", "Atlantis")
+	if !result.IsError {
+		t.Fatal("expected an error result")
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("expected one content block, got %d", len(result.Content))
+	}
+	if text, ok := result.Content[0].(*TextContent); !ok || text.Text != `city "Atlantis" not found` || text.Type != "text" {
+		t.Fatalf("unexpected content %+v", result.Content[0])
+	}
+}
+
+func TestRawParams(t *testing.T) {
+	params := `{"name":"search","arguments":{"q":"go"},"x-vendor":{"trace":"abc"}}`
+
+	var call CallToolRequest
+	if err := json.Unmarshal([]byte(params), &call); err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	if call.Name != "search" || call.Arguments["q"] != "go" {
+		t.Fatalf("expected the decoded fields to be populated, got %+v", call)
+	}
+	var ext struct {
+		Vendor struct {
+			Trace string `json:"trace"`
+		} `json:"x-vendor"`
+	}
+	if err := json.Unmarshal(call.RawParams(), &ext); err != nil || ext.Vendor.Trace != "abc" {
+		t.Fatalf("expected the extension field in the raw params, got %s, %v", call.RawParams(), err)
+	}
+
+	var prompt GetPromptRequest
+	if err := json.Unmarshal([]byte(`{"name":"greet","x-locale":"de"}`), &prompt); err != nil {
+		t.Fatalf("Unmarshal: %+v", err)
+	}
+	if prompt.Name != "greet" || string(prompt.RawParams()) != `{"name":"greet","x-locale":"de"}` {
+		t.Fatalf("unexpected prompt request %+v, raw params %s", prompt, prompt.RawParams())
+	}
+
+	if raw := NewCallToolRequest("search", nil).RawParams(); raw != nil {
+		t.Fatalf("expected no raw params for a request not decoded from JSON, got %s", raw)
+	}
+}
@@ -0,0 +1,4 @@
+package protocol
+
+// TraceParentKey is the _meta key carrying a W3C traceparent, mirroring the HTTP header of the same name
+const TraceParentKey = "traceparent"
@@ -42,6 +42,9 @@ const (
 	// Sampling related methods
 	SamplingCreateMessage Method = "sampling/createMessage"
 
+	// Elicitation related methods
+	ElicitationCreate Method = "elicitation/create"
+
 	// Logging related methods
 	LoggingSetLevel        Method = "logging/setLevel"
 	NotificationLogMessage Method = "notifications/message"
@@ -52,6 +55,11 @@ const (
 	// progress related methods
 	NotificationProgress  Method = "notifications/progress"
 	NotificationCancelled Method = "notifications/cancelled" // nolint:misspell
+
+	// Extension methods
+	NotificationClientConfig      Method = "notifications/x-config"
+	NotificationToolPartialResult Method = "notifications/x-partial_result"
+	NotificationServerDraining    Method = "notifications/x-draining"
 )
 
 // Role represents the sender or recipient of messages and data in a conversation
@@ -86,6 +94,7 @@ var (
 	_ ClientResponse = &PingResult{}
 	_ ClientResponse = &ListToolsResult{}
 	_ ClientResponse = &CreateMessageResult{}
+	_ ClientResponse = &ElicitResult{}
 )
 
 type ClientNotify interface{}
@@ -103,6 +112,7 @@ var (
 	_ ServerRequest = &PingRequest{}
 	_ ServerRequest = &ListRootsRequest{}
 	_ ServerRequest = &CreateMessageRequest{}
+	_ ServerRequest = &ElicitRequest{}
 )
 
 type ServerResponse interface{}
@@ -133,4 +143,5 @@ var (
 	_ ServerNotify = &ResourceListChangedNotification{}
 	_ ServerNotify = &ResourceUpdatedNotification{}
 	_ ServerNotify = &LogMessageNotification{}
+	_ ServerNotify = &ClientConfigNotification{}
 )
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// UnmarshalArgsOneOf decodes the tool call arguments into the first target that matches them and returns it.
+// Each target must be a non-nil pointer to a struct. A target matches when the arguments contain no field it
+// does not declare and pass the validation of its generated schema (required fields, types, enum values),
+// so an enum-tagged field can be used as a discriminator between variants.
+// Targets that don't match are left untouched.
+func UnmarshalArgsOneOf(req *protocol.CallToolRequest, targets ...any) (any, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no candidate target provided")
+	}
+
+	args := req.RawArguments
+	if len(args) == 0 {
+		var err error
+		if args, err = json.Marshal(req.Arguments); err != nil {
+			return nil, err
+		}
+		if req.Arguments == nil {
+			args = json.RawMessage("{}")
+		}
+	}
+
+	reasons := make([]string, 0, len(targets))
+	for _, target := range targets {
+		v := reflect.ValueOf(target)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return nil, fmt.Errorf("target %T must be a non-nil pointer", target)
+		}
+
+		candidate := reflect.New(v.Elem().Type())
+		if err := protocol.VerifyAndUnmarshalStrict(args, candidate.Interface()); err != nil {
+			reasons = append(reasons, fmt.Sprintf("%T: %v", target, err))
+			continue
+		}
+		v.Elem().Set(candidate.Elem())
+		return target, nil
+	}
+	return nil, fmt.Errorf("arguments of tool %s match none of the candidate types: %s", req.Name, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+type circleArgs struct {
+	Kind   string  `json:"kind" enum:"circle"`
+	Radius float64 `json:"radius"`
+}
+
+type rectArgs struct {
+	Kind   string  `json:"kind" enum:"rect"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+func TestUnmarshalArgsOneOf(t *testing.T) {
+	var (
+		circle circleArgs
+		rect   rectArgs
+	)
+
+	req := protocol.NewCallToolRequestWithRawArguments("area", json.RawMessage(`{"kind":"rect","width":2,"height":3}`))
+	matched, err := UnmarshalArgsOneOf(req, &circle, &rect)
+	if err != nil {
+		t.Fatalf("UnmarshalArgsOneOf: %+v", err)
+	}
+	if matched != &rect {
+		t.Fatalf("expected the second variant to match, got %T", matched)
+	}
+	if rect.Width != 2 || rect.Height != 3 {
+		t.Fatalf("unexpected decoded value: %+v", rect)
+	}
+	if circle != (circleArgs{}) {
+		t.Fatalf("unmatched target must be untouched, got %+v", circle)
+	}
+
+	req = protocol.NewCallToolRequest("area", map[string]interface{}{"kind": "triangle", "base": 1})
+	if _, err = UnmarshalArgsOneOf(req, &circle, &rect); err == nil {
+		t.Fatal("expected error for unmatchable arguments")
+	} else if !strings.Contains(err.Error(), "match none of the candidate types") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithArgumentCoercion(t *testing.T) {
+	tool := protocol.NewToolWithInputSchema("repeat", "", protocol.InputSchema{Type: protocol.Object})
+	handler := func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		var args struct {
+			Times int `json:"times"`
+		}
+		if err := req.BindArguments(&args); err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: strings.Repeat("a", args.Times)}}, false), nil
+	}
+
+	for _, tt := range []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "lenient by default"},
+		{name: "strict", opts: []Option{WithArgumentCoercion(protocol.ArgumentCoercionStrict)}, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mcpClient, _ := newTestServerAndClient(t, tt.opts, nil, func(s *Server) {
+				s.RegisterTool(tool, handler)
+			})
+			result, err := mcpClient.CallTool(context.Background(), protocol.NewCallToolRequest(tool.Name, map[string]interface{}{"times": "3"}))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected the numeric string to be rejected, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CallTool: %+v", err)
+			}
+			if got := result.Content[0].(*protocol.TextContent).Text; got != "aaa" {
+				t.Fatalf("expected the numeric string to be coerced, got %q", got)
+			}
+		})
+	}
+}
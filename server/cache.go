@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+const defaultCacheSize = 1024
+
+// ResultCache stores the tool results of CacheMiddleware, e.g. in Redis. Implementations must be safe for concurrent use.
+type ResultCache interface {
+	Get(key string) (*protocol.CallToolResult, bool)
+	Set(key string, result *protocol.CallToolResult, ttl time.Duration)
+}
+
+// CacheOptions configures CacheMiddleware
+type CacheOptions struct {
+	// TTL is how long a result is served from the cache
+	TTL time.Duration
+	// Size caps the number of results of the built-in in-memory LRU cache, it defaults to 1024
+	Size int
+	// Cache overrides the built-in in-memory LRU cache
+	Cache ResultCache
+}
+
+// CacheMiddleware serves repeated calls of a tool with the same arguments from a cache for opts.TTL,
+// without calling the handler. Only apply it to idempotent read-only tools. Calls are keyed by the tool name
+// and the arguments, independently of their key order. Failed calls and results with IsError set are not cached.
+func CacheMiddleware(opts CacheOptions) ToolMiddleware {
+	cache := opts.Cache
+	if cache == nil {
+		size := opts.Size
+		if size <= 0 {
+			size = defaultCacheSize
+		}
+		cache = pkg.NewLRUCache[*protocol.CallToolResult](size)
+	}
+
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			key, err := cacheKey(req)
+			if err != nil {
+				return next(ctx, req)
+			}
+			if result, ok := cache.Get(key); ok {
+				return copyCallToolResult(result), nil
+			}
+
+			result, err := next(ctx, req)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			cache.Set(key, copyCallToolResult(result), opts.TTL)
+			return result, nil
+		}
+	}
+}
+
+// cacheKey hashes the tool name and the arguments, encoding/json writes map keys in sorted order
+// so that the key doesn't depend on the order the arguments were sent in
+func cacheKey(req *protocol.CallToolRequest) (string, error) {
+	arguments, err := json.Marshal(req.Arguments)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(req.Name))
+	h.Write([]byte{0})
+	h.Write(arguments)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyCallToolResult copies the content list, which later stages like the result transformer may append to
+func copyCallToolResult(result *protocol.CallToolResult) *protocol.CallToolResult {
+	c := *result
+	c.Content = append([]protocol.Content(nil), result.Content...)
+	return &c
+}
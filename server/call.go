@@ -56,6 +56,65 @@ func (server *Server) Sampling(ctx context.Context, request *protocol.CreateMess
 	return &result, nil
 }
 
+// Elicit asks the client of the session in ctx to collect structured input from the user, e.g. in the middle
+// of a tool call. The client must have advertised the elicitation capability.
+func (server *Server) Elicit(ctx context.Context, request *protocol.ElicitRequest) (*protocol.ElicitResult, error) {
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := server.sessionManager.GetSession(sessionID)
+	if !ok {
+		return nil, pkg.ErrLackSession
+	}
+
+	if s.GetClientCapabilities() == nil || s.GetClientCapabilities().Elicitation == nil {
+		return nil, pkg.ErrClientNotSupport
+	}
+
+	response, err := server.callClient(ctx, sessionID, protocol.ElicitationCreate, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var result protocol.ElicitResult
+	if err = pkg.JSONUnmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &result, nil
+}
+
+// ListRoots asks the client of the session in ctx for its roots. The client must have advertised the roots capability.
+func (server *Server) ListRoots(ctx context.Context) (*protocol.ListRootsResult, error) {
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := server.sessionManager.GetSession(sessionID)
+	if !ok {
+		return nil, pkg.ErrLackSession
+	}
+
+	if s.GetClientCapabilities() == nil || s.GetClientCapabilities().Roots == nil {
+		return nil, pkg.ErrClientNotSupport
+	}
+
+	response, err := server.callClient(ctx, sessionID, protocol.RootsList, protocol.NewListRootsRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	var result protocol.ListRootsResult
+	if err = pkg.JSONUnmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &result, nil
+}
+
+// SendProgressNotification reports the progress of the request in ctx. Notifications of a request go through the
+// same queue as its response, so the client receives them in emission order and before the response.
 func (server *Server) SendProgressNotification(ctx context.Context, notify *protocol.ProgressNotification) error {
 	progressToken, err := getProgressTokenFromCtx(ctx)
 	if err != nil {
@@ -76,8 +135,12 @@ func (server *Server) sendNotification4ToolListChanges(ctx context.Context) erro
 	}
 
 	var errList []error
-	server.sessionManager.RangeSessions(func(sessionID string, _ *session.State) bool {
-		if err := server.sendMsgWithNotification(ctx, sessionID, protocol.NotificationToolsListChanged, protocol.NewToolListChangedNotification()); err != nil {
+	server.sessionManager.RangeSessions(func(sessionID string, s *session.State) bool {
+		if s.GetInitPhase() == session.InitPhaseUninitialized {
+			return true // the client lists once initialized
+		}
+		if err := server.Notify(setSessionIDToCtx(ctx, sessionID), string(protocol.NotificationToolsListChanged),
+			protocol.NewToolListChangedNotification()); err != nil {
 			errList = append(errList, fmt.Errorf("sessionID=%s, err: %w", sessionID, err))
 		}
 		return true
@@ -91,8 +154,12 @@ func (server *Server) sendNotification4PromptListChanges(ctx context.Context) er
 	}
 
 	var errList []error
-	server.sessionManager.RangeSessions(func(sessionID string, _ *session.State) bool {
-		if err := server.sendMsgWithNotification(ctx, sessionID, protocol.NotificationPromptsListChanged, protocol.NewPromptListChangedNotification()); err != nil {
+	server.sessionManager.RangeSessions(func(sessionID string, s *session.State) bool {
+		if s.GetInitPhase() == session.InitPhaseUninitialized {
+			return true
+		}
+		if err := server.Notify(setSessionIDToCtx(ctx, sessionID), string(protocol.NotificationPromptsListChanged),
+			protocol.NewPromptListChangedNotification()); err != nil {
 			errList = append(errList, fmt.Errorf("sessionID=%s, err: %w", sessionID, err))
 		}
 		return true
@@ -106,8 +173,11 @@ func (server *Server) sendNotification4ResourceListChanges(ctx context.Context)
 	}
 
 	var errList []error
-	server.sessionManager.RangeSessions(func(sessionID string, _ *session.State) bool {
-		if err := server.sendMsgWithNotification(ctx, sessionID, protocol.NotificationResourcesListChanged,
+	server.sessionManager.RangeSessions(func(sessionID string, s *session.State) bool {
+		if s.GetInitPhase() == session.InitPhaseUninitialized {
+			return true
+		}
+		if err := server.Notify(setSessionIDToCtx(ctx, sessionID), string(protocol.NotificationResourcesListChanged),
 			protocol.NewResourceListChangedNotification()); err != nil {
 			errList = append(errList, fmt.Errorf("sessionID=%s, err: %w", sessionID, err))
 		}
@@ -127,7 +197,7 @@ func (server *Server) SendNotification4ResourcesUpdated(ctx context.Context, not
 			return true
 		}
 
-		if err := server.sendMsgWithNotification(ctx, sessionID, protocol.NotificationResourcesUpdated, notify); err != nil {
+		if err := server.Notify(setSessionIDToCtx(ctx, sessionID), string(protocol.NotificationResourcesUpdated), notify); err != nil {
 			errList = append(errList, fmt.Errorf("sessionID=%s, err: %w", sessionID, err))
 		}
 		return true
@@ -135,6 +205,15 @@ func (server *Server) SendNotification4ResourcesUpdated(ctx context.Context, not
 	return pkg.JoinErrors(errList)
 }
 
+// PushClientConfig pushes configuration to the client of the session via the notifications/x-config notification.
+func (server *Server) PushClientConfig(sessionID string, config map[string]any) error {
+	if _, ok := server.sessionManager.GetSession(sessionID); !ok {
+		return pkg.ErrLackSession
+	}
+	return server.sendMsgWithNotification(context.Background(), sessionID, protocol.NotificationClientConfig,
+		protocol.NewClientConfigNotification(config))
+}
+
 // Responsible for request and response assembly
 func (server *Server) callClient(ctx context.Context, sessionID string, method protocol.Method, params protocol.ServerRequest) (json.RawMessage, error) {
 	session, ok := server.sessionManager.GetSession(sessionID)
@@ -153,6 +232,14 @@ func (server *Server) callClient(ctx context.Context, sessionID string, method p
 
 	select {
 	case <-ctx.Done():
+		// the handler waiting on the client gave up, e.g. its own request was cancelled, so the client stops too.
+		// An unanswered ping has nothing to stop.
+		if method != protocol.Ping {
+			if err := server.sendMsgWithNotification(pkg.NewCancelShieldContext(ctx), sessionID, protocol.NotificationCancelled,
+				protocol.NewCancelledNotification(requestID, ctx.Err().Error())); err != nil {
+				server.logger.Warn("send cancellation notification fail", "method", method, "error", err)
+			}
+		}
 		return nil, ctx.Err()
 	case response := <-respChan:
 		if err := response.Error; err != nil {
@@ -161,3 +248,22 @@ func (server *Server) callClient(ctx context.Context, sessionID string, method p
 		return response.RawResult, nil
 	}
 }
+
+// Notify sends a JSON-RPC notification to the session bound to ctx, e.g. the session of the request a handler serves.
+// Notifications sent from within a handler are delivered before the handler's result.
+// It fails if ctx is not bound to a session.
+func (server *Server) Notify(ctx context.Context, method string, params interface{}) error {
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return server.sendMsgWithNotification(ctx, sessionID, protocol.Method(method), params)
+}
+
+// SessionSender returns a function sending out-of-band notifications to the session of the request in ctx.
+// Notifications sent from within a handler are delivered before the handler's result.
+func (server *Server) SessionSender(ctx context.Context) func(method string, params interface{}) error {
+	return func(method string, params interface{}) error {
+		return server.Notify(ctx, method, params)
+	}
+}
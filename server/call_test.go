@@ -0,0 +1,497 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/client"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+type configNotifyHandler struct {
+	*client.BaseNotifyHandler
+	configCh chan map[string]interface{}
+}
+
+func (h *configNotifyHandler) ClientConfig(_ context.Context, request *protocol.ClientConfigNotification) error {
+	h.configCh <- request.Config
+	return nil
+}
+
+func TestPushClientConfig(t *testing.T) {
+	handler := &configNotifyHandler{
+		BaseNotifyHandler: client.NewBaseNotifyHandler(),
+		configCh:          make(chan map[string]interface{}, 1),
+	}
+
+	server, _, sessionID := newTestServerAndClient(t, nil, []client.Option{client.WithNotifyHandler(handler)}, nil)
+
+	config := map[string]any{"pollInterval": float64(30), "beta": true}
+	if err := server.PushClientConfig(sessionID, config); err != nil {
+		t.Fatalf("PushClientConfig: %+v", err)
+	}
+
+	select {
+	case got := <-handler.configCh:
+		if !reflect.DeepEqual(got, config) {
+			t.Fatalf("config not as expected.\ngot  = %v\nwant = %v", got, config)
+		}
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout waiting for client config notification")
+	}
+
+	if err := server.PushClientConfig("unknown", config); err == nil {
+		t.Fatal("expected error for unknown session")
+	}
+}
+
+func TestSessionSender(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		send := server.SessionSender(ctx)
+		for i := 1; i <= 2; i++ {
+			if err := send("notifications/x-step", map[string]interface{}{"step": i}); err != nil {
+				return nil, err
+			}
+		}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+
+	for i := 1; i <= 2; i++ {
+		notify := testReadMessage(t, outScan)
+		params, _ := notify["params"].(map[string]interface{})
+		if notify["method"] != "notifications/x-step" || params["step"] != float64(i) {
+			t.Fatalf("expected step %d notification, got %v", i, notify)
+		}
+	}
+	if resp := testReadMessage(t, outScan); resp["result"] == nil {
+		t.Fatalf("expected tool result after notifications, got %v", resp)
+	}
+}
+
+type testElicitationHandler struct {
+	request *protocol.ElicitRequest
+}
+
+func (h *testElicitationHandler) Elicit(_ context.Context, request *protocol.ElicitRequest) (*protocol.ElicitResult, error) {
+	h.request = request
+	return protocol.NewElicitResult(protocol.ElicitActionAccept, map[string]interface{}{"city": "Paris"}), nil
+}
+
+func TestElicit(t *testing.T) {
+	schema := protocol.InputSchema{
+		Type:       protocol.Object,
+		Properties: map[string]*protocol.Property{"city": {Type: protocol.String}},
+		Required:   []string{"city"},
+	}
+	elicitTool := protocol.NewToolWithInputSchema("elicit_tool", "", protocol.InputSchema{Type: protocol.Object})
+
+	var server *Server
+	elicit := func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		result, err := server.Elicit(ctx, protocol.NewElicitRequest("Which city?", schema))
+		if err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: string(result.Action) + ":" + result.Content["city"].(string)},
+		}, false), nil
+	}
+
+	t.Run("supported", func(t *testing.T) {
+		handler := &testElicitationHandler{}
+		var mcpClient *client.Client
+		server, mcpClient, _ = newTestServerAndClient(t, nil, []client.Option{client.WithElicitationHandler(handler)}, func(s *Server) {
+			s.RegisterTool(elicitTool, elicit)
+		})
+
+		result, err := mcpClient.CallTool(context.Background(), protocol.NewCallToolRequest(elicitTool.Name, map[string]interface{}{}))
+		if err != nil {
+			t.Fatalf("CallTool: %+v", err)
+		}
+		if text := result.Content[0].(*protocol.TextContent).Text; text != "accept:Paris" {
+			t.Fatalf("unexpected tool result %q", text)
+		}
+		if handler.request == nil || handler.request.Message != "Which city?" || !reflect.DeepEqual(handler.request.RequestedSchema.Required, schema.Required) {
+			t.Fatalf("unexpected elicitation request %+v", handler.request)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		var mcpClient *client.Client
+		server, mcpClient, _ = newTestServerAndClient(t, nil, nil, func(s *Server) {
+			s.RegisterTool(elicitTool, elicit)
+		})
+
+		if _, err := mcpClient.CallTool(context.Background(), protocol.NewCallToolRequest(elicitTool.Name, map[string]interface{}{})); err == nil {
+			t.Fatal("expected elicitation to fail without the client capability")
+		}
+	})
+}
+
+type blockingSamplingHandler struct {
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (h *blockingSamplingHandler) CreateMessage(ctx context.Context, _ *protocol.CreateMessageRequest) (*protocol.CreateMessageResult, error) {
+	close(h.started)
+	<-ctx.Done()
+	close(h.cancelled)
+	return nil, ctx.Err()
+}
+
+func TestCancelDuringSampling(t *testing.T) {
+	samplingTool := protocol.NewToolWithInputSchema("sampling_tool", "", protocol.InputSchema{Type: protocol.Object})
+	handler := &blockingSamplingHandler{started: make(chan struct{}), cancelled: make(chan struct{})}
+	samplingErr := make(chan error, 1)
+
+	var server *Server
+	server, mcpClient, sessionID := newTestServerAndClient(t, nil, []client.Option{client.WithSamplingHandler(handler)}, func(s *Server) {
+		s.RegisterTool(samplingTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			_, err := server.Sampling(ctx, &protocol.CreateMessageRequest{})
+			samplingErr <- err
+			return nil, err
+		})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-handler.started
+		cancel()
+	}()
+	if _, err := mcpClient.CallTool(ctx, protocol.NewCallToolRequest(samplingTool.Name, map[string]interface{}{})); err == nil {
+		t.Fatal("expected the cancelled tool call to fail")
+	}
+
+	select {
+	case err := <-samplingErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the sampling call to be cancelled, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for the sampling call to be cancelled")
+	}
+	select {
+	case <-handler.cancelled:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for the client sampling handler to be cancelled")
+	}
+
+	s, ok := server.sessionManager.GetSession(sessionID)
+	if !ok {
+		t.Fatal("session not found")
+	}
+	if n := s.GetServerReqID2respChan().Count(); n != 0 {
+		t.Fatalf("expected no pending response waiter, got %d", n)
+	}
+}
+
+type nestedToolSamplingHandler struct {
+	client     *client.Client
+	rootCallID string
+}
+
+func (h *nestedToolSamplingHandler) CreateMessage(ctx context.Context, _ *protocol.CreateMessageRequest) (*protocol.CreateMessageResult, error) {
+	h.rootCallID, _ = client.RootCallID(ctx)
+	if _, err := h.client.CallTool(ctx, protocol.NewCallToolRequest("inner_tool", map[string]interface{}{})); err != nil {
+		return nil, err
+	}
+	return protocol.NewCreateMessageResult(&protocol.TextContent{Type: "text", Text: "ok"}, protocol.RoleAssistant, "test-model", ""), nil
+}
+
+func TestRootCallID(t *testing.T) {
+	var (
+		server                   *Server
+		outerRootID, innerRootID string
+	)
+	handler := &nestedToolSamplingHandler{}
+	server, handler.client, _ = newTestServerAndClient(t, nil, []client.Option{client.WithSamplingHandler(handler)}, func(s *Server) {
+		s.RegisterTool(protocol.NewToolWithInputSchema("outer_tool", "", protocol.InputSchema{Type: protocol.Object}),
+			func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+				outerRootID, _ = RootCallID(ctx)
+				messages := []*protocol.SamplingMessage{{Role: protocol.RoleUser, Content: &protocol.TextContent{Type: "text", Text: "hi"}}}
+				if _, err := server.Sampling(ctx, protocol.NewCreateMessageRequest(messages, 16)); err != nil {
+					return nil, err
+				}
+				return protocol.NewCallToolResult(nil, false), nil
+			})
+		s.RegisterTool(protocol.NewToolWithInputSchema("inner_tool", "", protocol.InputSchema{Type: protocol.Object}),
+			func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+				innerRootID, _ = RootCallID(ctx)
+				return protocol.NewCallToolResult(nil, false), nil
+			})
+	})
+
+	if _, err := handler.client.CallTool(context.Background(), protocol.NewCallToolRequest("outer_tool", map[string]interface{}{})); err != nil {
+		t.Fatalf("CallTool: %+v", err)
+	}
+	if outerRootID == "" || handler.rootCallID != outerRootID || innerRootID != outerRootID {
+		t.Fatalf("expected a shared root call id, got outer=%q sampling=%q inner=%q", outerRootID, handler.rootCallID, innerRootID)
+	}
+}
+
+func TestStreamLogs(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		logs := server.StreamLogs(ctx)
+		for _, chunk := range []string{"first line\nsec", "ond line\n", "third line"} {
+			if _, err := logs.Write([]byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+		if err := logs.Close(); err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+
+	for _, want := range []string{"first line", "second line", "third line"} {
+		notify := testReadMessage(t, outScan)
+		params, _ := notify["params"].(map[string]interface{})
+		if notify["method"] != string(protocol.NotificationLogMessage) || params["message"] != want {
+			t.Fatalf("expected log notification %q, got %v", want, notify)
+		}
+	}
+	if resp := testReadMessage(t, outScan); resp["result"] == nil {
+		t.Fatalf("expected tool result after log notifications, got %v", resp)
+	}
+}
+
+func TestStreamLogsWriteError(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	// without a session bound to ctx no line can be sent
+	logs := server.StreamLogs(context.Background())
+	if n, err := logs.Write([]byte("partial")); n != len("partial") || err != nil {
+		t.Fatalf("expected a partial line to be buffered, got n=%d err=%v", n, err)
+	}
+	if n, err := logs.Write([]byte(" line\nnext")); n != 0 || err == nil {
+		t.Fatalf("expected no bytes written with the send error, got n=%d err=%v", n, err)
+	}
+	if err := logs.Close(); err == nil {
+		t.Fatal("expected Close to fail sending the buffered partial line")
+	}
+}
+
+func TestRawParamsDispatch(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	rawParams := make(chan json.RawMessage, 1)
+	testTool := protocol.NewToolWithInputSchema("search", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		rawParams <- req.RawParams()
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, map[string]interface{}{
+		"name":      testTool.Name,
+		"arguments": map[string]interface{}{"q": "go"},
+		"x-vendor":  map[string]interface{}{"trace": "abc"},
+	})
+	if resp := testReadMessage(t, outScan); resp["result"] == nil {
+		t.Fatalf("expected a tool result, got %v", resp)
+	}
+
+	var ext struct {
+		Vendor struct {
+			Trace string `json:"trace"`
+		} `json:"x-vendor"`
+	}
+	raw := <-rawParams
+	if err := json.Unmarshal(raw, &ext); err != nil || ext.Vendor.Trace != "abc" {
+		t.Fatalf("expected the extension field in the raw params passed to the handler, got %s, %v", raw, err)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	if err := server.Notify(context.Background(), "notifications/x-custom", nil); err == nil {
+		t.Fatal("expected Notify without a session bound to ctx to fail")
+	}
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		if err := server.Notify(ctx, "notifications/x-custom", map[string]interface{}{"key": "value"}); err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+
+	notify := testReadMessage(t, outScan)
+	params, _ := notify["params"].(map[string]interface{})
+	if notify["method"] != "notifications/x-custom" || notify["id"] != nil || params["key"] != "value" {
+		t.Fatalf("unexpected notification %v", notify)
+	}
+	if resp := testReadMessage(t, outScan); resp["result"] == nil {
+		t.Fatalf("expected tool result after notification, got %v", resp)
+	}
+}
+
+func TestClientOnNotification(t *testing.T) {
+	server, mcpClient, sessionID := newTestServerAndClient(t, nil, nil, nil)
+
+	type received struct {
+		method string
+		params json.RawMessage
+	}
+	ch := make(chan received, 3)
+	mcpClient.OnNotification(string(protocol.NotificationLogMessage), func(params json.RawMessage) {
+		ch <- received{method: string(protocol.NotificationLogMessage), params: params}
+	})
+	mcpClient.OnNotification(string(protocol.NotificationToolsListChanged), func(params json.RawMessage) {
+		ch <- received{method: string(protocol.NotificationToolsListChanged), params: params}
+	})
+	mcpClient.OnUnknownNotification(func(method string, params json.RawMessage) {
+		ch <- received{method: method, params: params}
+	})
+
+	ctx := setSessionIDToCtx(context.Background(), sessionID)
+	for _, method := range []string{
+		string(protocol.NotificationLogMessage),
+		string(protocol.NotificationToolsListChanged),
+		"notifications/x-custom",
+	} {
+		if err := server.Notify(ctx, method, map[string]interface{}{"method": method}); err != nil {
+			t.Fatalf("Notify: %+v", err)
+		}
+
+		select {
+		case r := <-ch:
+			var params map[string]interface{}
+			if err := json.Unmarshal(r.params, &params); err != nil {
+				t.Fatalf("invalid params %s: %+v", r.params, err)
+			}
+			if r.method != method || params["method"] != method {
+				t.Fatalf("expected notification %s, got %s with params %v", method, r.method, params)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("notification %s not delivered", method)
+		}
+	}
+}
+
+func TestCallToolsBatch(t *testing.T) {
+	echoTool := protocol.NewToolWithInputSchema("echo", "", protocol.InputSchema{Type: protocol.Object})
+
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	setup := func(s *Server) {
+		s.RegisterTool(echoTool, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			time.Sleep(20 * time.Millisecond)
+			return protocol.NewCallToolResult([]protocol.Content{
+				&protocol.TextContent{Type: "text", Text: fmt.Sprint(req.Arguments["n"])},
+			}, false), nil
+		})
+	}
+	_, mcpClient, _ := newTestServerAndClient(t, nil, []client.Option{client.WithMaxBatchInFlight(2)}, setup)
+
+	calls := make([]client.ToolCall, 0, 6)
+	for i := 0; i < 5; i++ {
+		calls = append(calls, client.ToolCall{Name: echoTool.Name, Arguments: map[string]interface{}{"n": i}})
+	}
+	calls = append(calls, client.ToolCall{Name: "unknown"})
+
+	results, err := mcpClient.CallToolsBatch(context.Background(), calls)
+	if err != nil {
+		t.Fatalf("CallToolsBatch: %+v", err)
+	}
+	if len(results) != len(calls) {
+		t.Fatalf("expected %d results, got %d", len(calls), len(results))
+	}
+	for i, result := range results[:5] {
+		if result.Err != nil {
+			t.Fatalf("call %d: %+v", i, result.Err)
+		}
+		if text := result.Result.Content[0].(*protocol.TextContent).Text; text != fmt.Sprint(i) {
+			t.Fatalf("expected result %d in order, got %q", i, text)
+		}
+	}
+	if results[5].Err == nil {
+		t.Fatal("expected the call of the unknown tool to fail on its own")
+	}
+	if maxInFlight > 2 {
+		t.Fatalf("expected at most 2 calls in flight, got %d", maxInFlight)
+	}
+}
+
+func TestClientCapabilitiesAndInfo(t *testing.T) {
+	infoTool := protocol.NewToolWithInputSchema("info_tool", "", protocol.InputSchema{Type: protocol.Object})
+
+	var server *Server
+	server, mcpClient, _ := newTestServerAndClient(t, nil, []client.Option{
+		client.WithClientInfo(&protocol.Implementation{Name: "text-client", Version: "2.1.0"}),
+		client.WithSamplingHandler(&blockingSamplingHandler{}),
+	}, func(s *Server) {
+		s.RegisterTool(infoTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			info := server.ClientInfo(ctx)
+			capabilities := server.ClientCapabilities(ctx)
+			text := fmt.Sprintf("%s/%s sampling=%v roots=%v", info.Name, info.Version, capabilities.Sampling != nil, capabilities.Roots != nil)
+			return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: text}}, false), nil
+		})
+	})
+
+	result, err := mcpClient.CallTool(context.Background(), protocol.NewCallToolRequest(infoTool.Name, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("CallTool: %+v", err)
+	}
+	if got := result.Content[0].(*protocol.TextContent).Text; got != "text-client/2.1.0 sampling=true roots=false" {
+		t.Fatalf("unexpected client capabilities and info: %s", got)
+	}
+
+	// outside of a session both are zero values
+	if info := server.ClientInfo(context.Background()); info != (protocol.Implementation{}) {
+		t.Fatalf("expected zero client info, got %+v", info)
+	}
+	if capabilities := server.ClientCapabilities(context.Background()); capabilities.Sampling != nil || capabilities.Roots != nil {
+		t.Fatalf("expected zero client capabilities, got %+v", capabilities)
+	}
+}
+
+func TestPingLatency(t *testing.T) {
+	_, mcpClient, _ := newTestServerAndClient(t, nil, nil, nil)
+
+	latency, err := mcpClient.PingLatency(context.Background())
+	if err != nil {
+		t.Fatalf("PingLatency: %+v", err)
+	}
+	if latency <= 0 || latency > 3*time.Second {
+		t.Fatalf("unexpected round-trip time %v", latency)
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
+)
+
+// ClientCapabilities returns the capabilities the client of the session in ctx advertised during initialize,
+// so that handlers can adapt, e.g. only request sampling if the client supports it.
+// A zero value is returned outside of a session or before initialize.
+func (server *Server) ClientCapabilities(ctx context.Context) protocol.ClientCapabilities {
+	s, ok := server.sessionFromCtx(ctx)
+	if !ok || s.GetClientCapabilities() == nil {
+		return protocol.ClientCapabilities{}
+	}
+	return *s.GetClientCapabilities()
+}
+
+// ClientInfo returns the name and version the client of the session in ctx sent during initialize,
+// a zero value is returned outside of a session or before initialize.
+func (server *Server) ClientInfo(ctx context.Context) protocol.Implementation {
+	s, ok := server.sessionFromCtx(ctx)
+	if !ok {
+		return protocol.Implementation{}
+	}
+	return s.GetClientInfo()
+}
+
+func (server *Server) sessionFromCtx(ctx context.Context) (*session.State, bool) {
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return nil, false
+	}
+	return server.sessionManager.GetSession(sessionID)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// CompletionHandlerFunc completes the argument of a completion request, i.e. a prompt argument or
+// a resource template variable, from the value typed so far. It may return any number of values,
+// results beyond protocol.CompletionMaxValues are truncated with hasMore set.
+type CompletionHandlerFunc func(context.Context, *protocol.CompleteRequest) (*protocol.CompleteResult, error)
+
+// RegisterCompletionHandler registers the handler completing the arguments of ref, either a *protocol.PromptReference
+// naming a prompt or a *protocol.ResourceReference whose uri is the uri template of a resource template, in which
+// case the argument name of requests is the template variable being completed. A handler already registered
+// for the same reference is replaced.
+func (server *Server) RegisterCompletionHandler(ref interface{}, handler CompletionHandlerFunc) error {
+	key, err := completionKey(ref)
+	if err != nil {
+		return err
+	}
+	server.completions.Store(key, handler)
+	return nil
+}
+
+// UnregisterCompletionHandler removes the handler completing the arguments of ref
+func (server *Server) UnregisterCompletionHandler(ref interface{}) {
+	if key, err := completionKey(ref); err == nil {
+		server.completions.Delete(key)
+	}
+}
+
+// completionKey returns the key of the handler completing the arguments of ref
+func completionKey(ref interface{}) (string, error) {
+	switch ref := ref.(type) {
+	case *protocol.PromptReference:
+		return protocol.RefTypePrompt + ":" + ref.Name, nil
+	case *protocol.ResourceReference:
+		return protocol.RefTypeResource + ":" + ref.URI, nil
+	default:
+		return "", fmt.Errorf("unsupported completion reference: %+v", ref)
+	}
+}
+
+func (server *Server) handleRequestWithComplete(ctx context.Context, rawParams json.RawMessage) (*protocol.CompleteResult, error) {
+	if server.capabilities.Completions == nil {
+		return nil, pkg.ErrServerNotSupport
+	}
+
+	var request *protocol.CompleteRequest
+	if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
+		return nil, err
+	}
+
+	key, err := completionKey(request.Ref)
+	if err != nil {
+		return nil, protocol.NewInvalidParamsError(err.Error(), map[string]interface{}{"ref": request.Ref})
+	}
+	handler, ok := server.completions.Load(key)
+	if !ok {
+		// nothing to suggest, clients fall back to free-form input
+		return protocol.NewCompleteResult([]string{}, false, 0), nil
+	}
+
+	result, err := handler(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || result.Completion == nil {
+		return protocol.NewCompleteResult([]string{}, false, 0), nil
+	}
+	if result.Completion.Values == nil {
+		result.Completion.Values = []string{}
+	}
+	if n := len(result.Completion.Values); n > protocol.CompletionMaxValues {
+		if result.Completion.Total < n {
+			result.Completion.Total = n
+		}
+		result.Completion.Values = result.Completion.Values[:protocol.CompletionMaxValues]
+		result.Completion.HasMore = true
+	}
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestCompletion(t *testing.T) {
+	template := &protocol.ResourceTemplate{Name: "file", URITemplate: "file:///{path}"}
+	paths := make([]string, 0, 150)
+	for i := 0; i < 150; i++ {
+		paths = append(paths, fmt.Sprintf("docs/%03d.md", i))
+	}
+
+	setup := func(s *Server) {
+		if err := s.RegisterResourceTemplate(template, func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+			return &protocol.ReadResourceResult{}, nil
+		}); err != nil {
+			t.Fatalf("RegisterResourceTemplate: %+v", err)
+		}
+		if err := s.RegisterCompletionHandler(protocol.NewResourceReference(template.URITemplate),
+			func(_ context.Context, req *protocol.CompleteRequest) (*protocol.CompleteResult, error) {
+				if req.Argument.Name != "path" {
+					return nil, fmt.Errorf("unexpected variable %q", req.Argument.Name)
+				}
+				values := make([]string, 0)
+				for _, path := range paths {
+					if strings.HasPrefix(path, req.Argument.Value) {
+						values = append(values, path)
+					}
+				}
+				return protocol.NewCompleteResult(values, false, 0), nil
+			}); err != nil {
+			t.Fatalf("RegisterCompletionHandler: %+v", err)
+		}
+		if err := s.RegisterCompletionHandler(protocol.NewPromptReference("greet"),
+			func(context.Context, *protocol.CompleteRequest) (*protocol.CompleteResult, error) {
+				return protocol.NewCompleteResult([]string{"alice", "bob"}, false, 2), nil
+			}); err != nil {
+			t.Fatalf("RegisterCompletionHandler: %+v", err)
+		}
+	}
+	_, mcpClient, _ := newTestServerAndClient(t, nil, nil, setup)
+
+	result, err := mcpClient.Complete(context.Background(),
+		protocol.NewCompleteRequest("path", "docs/", protocol.NewResourceReference(template.URITemplate)))
+	if err != nil {
+		t.Fatalf("Complete: %+v", err)
+	}
+	if got := result.Completion; len(got.Values) != protocol.CompletionMaxValues || !got.HasMore || got.Total != len(paths) {
+		t.Fatalf("expected the first %d of %d values with hasMore, got %d values, hasMore=%v, total=%d",
+			protocol.CompletionMaxValues, len(paths), len(got.Values), got.HasMore, got.Total)
+	}
+
+	result, err = mcpClient.Complete(context.Background(),
+		protocol.NewCompleteRequest("path", "docs/14", protocol.NewResourceReference(template.URITemplate)))
+	if err != nil {
+		t.Fatalf("Complete: %+v", err)
+	}
+	if got := result.Completion; len(got.Values) != 10 || got.HasMore {
+		t.Fatalf("expected 10 values without hasMore, got %+v", got)
+	}
+
+	result, err = mcpClient.Complete(context.Background(), protocol.NewCompleteRequest("name", "", protocol.NewPromptReference("greet")))
+	if err != nil {
+		t.Fatalf("Complete: %+v", err)
+	}
+	if got := result.Completion.Values; len(got) != 2 || got[0] != "alice" {
+		t.Fatalf("expected the prompt completions, got %v", got)
+	}
+
+	// a reference without a handler has nothing to suggest
+	result, err = mcpClient.Complete(context.Background(), protocol.NewCompleteRequest("name", "", protocol.NewPromptReference("unknown")))
+	if err != nil {
+		t.Fatalf("Complete: %+v", err)
+	}
+	if len(result.Completion.Values) != 0 {
+		t.Fatalf("expected no completions, got %v", result.Completion.Values)
+	}
+
+	if _, err = mcpClient.Complete(context.Background(),
+		protocol.NewCompleteRequest("name", "", map[string]interface{}{"type": "ref/unknown"})); err == nil {
+		t.Fatal("expected a reference of unknown type to be rejected")
+	}
+}
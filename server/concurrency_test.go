@@ -0,0 +1,259 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+func TestMaxConcurrencyPerSession(t *testing.T) {
+	const limit = 2
+
+	server, _, _ := newTestServer(t, WithMaxConcurrencyPerSession(limit))
+
+	var inFlight, maxInFlight int32
+	release := make(chan struct{})
+	slowTool := protocol.NewToolWithInputSchema("slow_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(slowTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&inFlight, -1)
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+	fastTool := protocol.NewToolWithInputSchema("fast_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(fastTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	call := func(sessionID string, id int, tool string) <-chan []byte {
+		reqBytes, err := json.Marshal(protocol.NewJSONRPCRequest(id, protocol.ToolsCall, protocol.NewCallToolRequest(tool, map[string]interface{}{})))
+		if err != nil {
+			t.Fatalf("json Marshal: %+v", err)
+		}
+		ch, err := server.receive(context.Background(), sessionID, reqBytes)
+		if err != nil {
+			t.Fatalf("receive: %+v", err)
+		}
+		return ch
+	}
+
+	newSession := func() string {
+		sessionID := server.sessionManager.CreateSession(context.Background())
+		s, _ := server.sessionManager.GetSession(sessionID)
+		s.BeginInitialize()
+		return sessionID
+	}
+	busySession := newSession()
+	otherSession := newSession()
+
+	busyResps := make([]<-chan []byte, 0, 5)
+	for i := 0; i < 5; i++ {
+		busyResps = append(busyResps, call(busySession, i, slowTool.Name))
+	}
+	for atomic.LoadInt32(&inFlight) < limit {
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case <-call(otherSession, 100, fastTool.Name):
+	case <-time.After(time.Second):
+		t.Fatal("call of another session was blocked by the busy session")
+	}
+	if n := atomic.LoadInt32(&inFlight); n != limit {
+		t.Fatalf("expected %d in-flight handlers for the busy session, got %d", limit, n)
+	}
+
+	close(release)
+	for _, ch := range busyResps {
+		<-ch
+	}
+	if n := atomic.LoadInt32(&maxInFlight); n != limit {
+		t.Fatalf("expected at most %d concurrent handlers, got %d", limit, n)
+	}
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	const (
+		limit = 10
+		calls = 100
+	)
+
+	server, in, outScan := newTestServer(t, WithMaxConcurrentRequests(limit))
+
+	var inFlight, maxInFlight int32
+	slowTool := protocol.NewToolWithInputSchema("slow_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(slowTool, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: fmt.Sprint(req.Arguments["n"])}}, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	for i := 0; i < calls; i++ {
+		testWriteRequest(t, in, i, protocol.ToolsCall, protocol.NewCallToolRequest(slowTool.Name, map[string]interface{}{"n": i}))
+	}
+
+	seen := make(map[int]bool, calls)
+	for i := 0; i < calls; i++ {
+		resp := testReadMessage(t, outScan)
+		id, _ := resp["id"].(float64)
+		result, _ := resp["result"].(map[string]interface{})
+		content, _ := result["content"].([]interface{})
+		if len(content) != 1 || content[0].(map[string]interface{})["text"] != fmt.Sprint(id) {
+			t.Fatalf("unexpected response %v", resp)
+		}
+		seen[int(id)] = true
+	}
+	if len(seen) != calls {
+		t.Fatalf("expected %d distinct responses, got %d", calls, len(seen))
+	}
+	if n := atomic.LoadInt32(&maxInFlight); n > limit {
+		t.Fatalf("expected at most %d concurrent handlers, got %d", limit, n)
+	}
+}
+
+// byteWriter writes every byte with a separate call, so unserialized concurrent writes would interleave
+type byteWriter struct {
+	w io.Writer
+}
+
+func (b *byteWriter) Write(p []byte) (int, error) {
+	for i := range p {
+		if _, err := b.w.Write(p[i : i+1]); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+func TestConcurrentWritesAreNotInterleaved(t *testing.T) {
+	const (
+		calls    = 20
+		progress = 5
+	)
+
+	reader1, writer1 := io.Pipe()
+	reader2, writer2 := io.Pipe()
+	server, err := NewServer(transport.NewMockServerTransport(reader1, &byteWriter{w: writer2}),
+		WithServerInfo(protocol.Implementation{Name: "ExampleServer", Version: "1.0.0"}))
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	go func() {
+		if err := server.Run(); err != nil {
+			t.Errorf("server start: %+v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		_ = writer1.Close()
+		_ = reader2.Close()
+	})
+	outScan := bufio.NewScanner(reader2)
+
+	testTool := protocol.NewToolWithInputSchema("progress_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		for i := 1; i <= progress; i++ {
+			if err := server.SendProgressNotification(ctx, &protocol.ProgressNotification{Progress: float64(i), Total: progress}); err != nil {
+				return nil, err
+			}
+		}
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: "done"}}, false), nil
+	})
+
+	testServerInit(t, server, writer1, outScan)
+
+	for i := 0; i < calls; i++ {
+		request := protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{})
+		request.Meta = map[string]interface{}{protocol.ProgressTokenKey: i}
+		testWriteRequest(t, writer1, i, protocol.ToolsCall, request)
+	}
+
+	var notifications, results int
+	for notifications+results < calls*(progress+1) {
+		if !outScan.Scan() {
+			t.Fatalf("outScan: %+v", outScan.Err())
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(outScan.Bytes(), &msg); err != nil {
+			t.Fatalf("invalid JSON line %q: %+v", outScan.Text(), err)
+		}
+		if msg["method"] == string(protocol.NotificationProgress) {
+			notifications++
+		} else if msg["result"] != nil {
+			results++
+		}
+	}
+	if notifications != calls*progress || results != calls {
+		t.Fatalf("expected %d progress notifications and %d results, got %d and %d", calls*progress, calls, notifications, results)
+	}
+}
+
+func TestProgressOrderingUnderConcurrentCalls(t *testing.T) {
+	const calls = 30
+
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("progress_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		for i := 0; i <= 100; i += 25 {
+			if err := server.SendProgressNotification(ctx, &protocol.ProgressNotification{Progress: float64(i), Total: 100}); err != nil {
+				return nil, err
+			}
+			// let the other handlers run so their messages interleave
+			runtime.Gosched()
+		}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	for i := 0; i < calls; i++ {
+		request := protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{})
+		request.Meta = map[string]interface{}{protocol.ProgressTokenKey: i}
+		testWriteRequest(t, in, i, protocol.ToolsCall, request)
+	}
+
+	lastProgress := make(map[int]float64, calls)
+	for responses := 0; responses < calls; {
+		msg := testReadMessage(t, outScan)
+		if msg["method"] == string(protocol.NotificationProgress) {
+			params := msg["params"].(map[string]interface{})
+			token := int(params["progressToken"].(float64))
+			progress := params["progress"].(float64)
+			if last, ok := lastProgress[token]; ok && progress <= last {
+				t.Fatalf("call %d: progress %v arrived after %v", token, progress, last)
+			}
+			lastProgress[token] = progress
+			continue
+		}
+
+		id := int(msg["id"].(float64))
+		if last := lastProgress[id]; last != 100 {
+			t.Fatalf("call %d: response arrived before the final progress, last progress %v", id, last)
+		}
+		responses++
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// configOptions mirrors the configurable server options, one field per option named after it and typed as its argument,
+// options taking several arguments are described by an object of them.
+// Options taking functions or interfaces, e.g. WithLogger or WithObserver, can not be expressed in JSON and are left out.
+type configOptions struct {
+	Capabilities               protocol.ServerCapabilities `json:"WithCapabilities,omitempty" description:"advertised capabilities"`
+	ServerInfo                 protocol.Implementation     `json:"WithServerInfo,omitempty" description:"initialize serverInfo"`
+	Instructions               string                      `json:"WithInstructions,omitempty" description:"initialize instructions"`
+	SessionMaxIdleTime         time.Duration               `json:"WithSessionMaxIdleTime,omitempty" description:"session idle timeout in nanoseconds"`
+	KeepAlive                  keepAliveConfig             `json:"WithKeepAlive,omitempty" description:"session keep-alive pings"`
+	SupportedProtocolVersions  []string                    `json:"WithSupportedProtocolVersions,omitempty" description:"negotiable protocol versions"`
+	Pagination                 int                         `json:"WithPagination,omitempty" description:"list page size, 0 for no paging"`
+	ListChangedDebounce        time.Duration               `json:"WithListChangedDebounce,omitempty" description:"list changed coalescing in nanoseconds"`
+	MaxMessageSize             int                         `json:"WithMaxMessageSize,omitempty" description:"max message size in bytes, 0 for no limit"`
+	MaxSubscriptionsPerSession int                         `json:"WithMaxSubscriptionsPerSession,omitempty" description:"session subscriptions, 0 for no limit"`
+	MaxConcurrencyPerSession   int                         `json:"WithMaxConcurrencyPerSession,omitempty" description:"requests per session, 0 for no limit"`
+	MaxConcurrentRequests      int                         `json:"WithMaxConcurrentRequests,omitempty" description:"concurrent requests, 0 for no limit"`
+	ToolTimeout                time.Duration               `json:"WithToolTimeout,omitempty" description:"tool call timeout in nanoseconds"`
+	ResourceTimeout            time.Duration               `json:"WithResourceTimeout,omitempty" description:"resource read timeout in nanoseconds"`
+	ResourceSchemes            []string                    `json:"WithResourceSchemes,omitempty" description:"served uri schemes, empty means any"`
+	MimeDetection              bool                        `json:"WithMimeDetection,omitempty" description:"sniff missing resource mime types"`
+	PanicPolicy                panicPolicyConfig           `json:"WithPanicPolicy,omitempty" description:"handling of recovered panics"`
+	ArgumentCoercion           protocol.ArgumentCoercion   `json:"WithArgumentCoercion,omitempty" description:"0 for lenient, 1 for strict"`
+	StrictInitialization       bool                        `json:"WithStrictInitialization,omitempty" description:"require the initialized notification"`
+	EventBufferSize            int                         `json:"WithEventBufferSize,omitempty" description:"Server.Events capacity"`
+	LegacyResultFormat         string                      `json:"WithLegacyResultFormat,omitempty" description:"legacy result client name pattern"`
+	ContentDeduplication       bool                        `json:"WithContentDeduplication,omitempty" description:"drop duplicate content"`
+	StructuredTextConsistency  bool                        `json:"WithStructuredTextConsistency,omitempty" description:"check text against structured content"`
+}
+
+type keepAliveConfig struct {
+	Interval time.Duration `json:"interval" description:"ping interval in nanoseconds, 0 disables the pings"`
+	Timeout  time.Duration `json:"timeout" description:"ping timeout in nanoseconds"`
+}
+
+// panicPolicyConfig describes PanicPolicy without the Report hook
+type panicPolicyConfig struct {
+	Mode PanicMode `json:"mode" description:"0 for an error response, 1 for an isError tool result, 2 to crash"`
+}
+
+// ConfigSchema returns a JSON Schema describing the server options and the types of their arguments,
+// keyed by option name, e.g. to build configuration UIs. Durations are expressed in nanoseconds.
+func ConfigSchema() []byte {
+	schema, err := protocol.GenerateSchema(configOptions{})
+	if err != nil {
+		panic(err) // configOptions only holds JSON expressible fields
+	}
+	b, err := json.Marshal(schema)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
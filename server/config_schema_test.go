@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema(t *testing.T) {
+	var schema struct {
+		Type       string `json:"type"`
+		Properties map[string]struct {
+			Type       string                     `json:"type"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(ConfigSchema(), &schema); err != nil {
+		t.Fatalf("invalid schema: %+v", err)
+	}
+	if schema.Type != "object" {
+		t.Fatalf("expected an object schema, got %q", schema.Type)
+	}
+	if len(schema.Required) != 0 {
+		t.Fatalf("expected all options to be optional, got required %v", schema.Required)
+	}
+
+	for option, typ := range map[string]string{
+		"WithMaxConcurrencyPerSession":  "integer",
+		"WithMaxConcurrentRequests":     "integer",
+		"WithPagination":                "integer",
+		"WithToolTimeout":               "integer",
+		"WithInstructions":              "string",
+		"WithResourceSchemes":           "array",
+		"WithMimeDetection":             "boolean",
+		"WithPanicPolicy":               "object",
+		"WithServerInfo":                "object",
+		"WithStructuredTextConsistency": "boolean",
+	} {
+		property, ok := schema.Properties[option]
+		if !ok {
+			t.Errorf("expected option %s in the schema", option)
+			continue
+		}
+		if property.Type != typ {
+			t.Errorf("expected option %s to be %s, got %s", option, typ, property.Type)
+		}
+	}
+	if _, ok := schema.Properties["WithServerInfo"].Properties["name"]; !ok {
+		t.Errorf("expected WithServerInfo to describe the implementation fields")
+	}
+}
+
+// TestConfigSchemaCoversOptions keeps configOptions in sync with the options of the package,
+// an option is either described by a field or excluded here for not being expressible in JSON
+func TestConfigSchemaCoversOptions(t *testing.T) {
+	excluded := map[string]bool{
+		"WithIdempotency":        true,
+		"WithLogger":             true,
+		"WithObserver":           true,
+		"WithGenSessionIDFunc":   true,
+		"WithSessionScopedTools": true,
+		"WithTracer":             true,
+	}
+
+	described := make(map[string]bool)
+	typ := reflect.TypeOf(configOptions{})
+	for i := 0; i < typ.NumField(); i++ {
+		described[strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+
+	options := make(map[string]bool)
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("parse package: %+v", err)
+	}
+	for _, file := range pkgs["server"].Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !fn.Name.IsExported() || fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+				continue
+			}
+			if result, ok := fn.Type.Results.List[0].Type.(*ast.Ident); ok && result.Name == "Option" {
+				options[fn.Name.Name] = true
+			}
+		}
+	}
+
+	for option := range options {
+		if !described[option] && !excluded[option] {
+			t.Errorf("option %s is neither described by configOptions nor excluded", option)
+		}
+	}
+	for option := range described {
+		if !options[option] {
+			t.Errorf("configOptions describes %s which is not an option", option)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package server
 import (
 	"context"
 	"errors"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
 )
 
 type sessionIDKey struct{}
@@ -21,16 +25,38 @@ func GetSessionIDFromCtx(ctx context.Context) (string, error) {
 
 type sendChanKey struct{}
 
-func setSendChanToCtx(ctx context.Context, sendCh chan<- []byte) context.Context {
-	return context.WithValue(ctx, sendChanKey{}, sendCh)
+// sendChan carries the messages of the request being handled back to its session
+type sendChan struct {
+	sessionID string
+	ch        chan<- []byte
+}
+
+func setSendChanToCtx(ctx context.Context, sessionID string, sendCh chan<- []byte) context.Context {
+	return context.WithValue(ctx, sendChanKey{}, sendChan{sessionID: sessionID, ch: sendCh})
 }
 
-func getSendChanFromCtx(ctx context.Context) (chan<- []byte, error) {
-	ch := ctx.Value(sendChanKey{})
-	if ch == nil {
+// getSendChanFromCtx returns the send chan of the request in ctx if messages to sessionID can go through it
+func getSendChanFromCtx(ctx context.Context, sessionID string) (chan<- []byte, error) {
+	v, ok := ctx.Value(sendChanKey{}).(sendChan)
+	if !ok {
 		return nil, errors.New("no send chan found")
 	}
-	return ch.(chan<- []byte), nil
+	if sessionID != "" && v.sessionID != sessionID {
+		return nil, errors.New("send chan belongs to another session")
+	}
+	return v.ch, nil
+}
+
+type serverKey struct{}
+
+// setServerToCtx makes the server handling the request available to middlewares that are not bound to it
+func setServerToCtx(ctx context.Context, server *Server) context.Context {
+	return context.WithValue(ctx, serverKey{}, server)
+}
+
+func getServerFromCtx(ctx context.Context) (*Server, bool) {
+	server, ok := ctx.Value(serverKey{}).(*Server)
+	return server, ok
 }
 
 type progressTokenKey struct{}
@@ -46,3 +72,104 @@ func getProgressTokenFromCtx(ctx context.Context) (interface{}, error) {
 	}
 	return progressToken, nil
 }
+
+type traceParentKey struct{}
+
+func setTraceParentToCtx(ctx context.Context, traceParent pkg.TraceParent) context.Context {
+	return context.WithValue(ctx, traceParentKey{}, traceParent)
+}
+
+// GetTraceParentFromCtx returns the span context of the handler, a child of the traceparent the request carried.
+// Handlers can propagate it on outbound HTTP calls with the traceparent header, requests the server sends
+// to the client, e.g. sampling, carry it in _meta automatically.
+func GetTraceParentFromCtx(ctx context.Context) (pkg.TraceParent, error) {
+	traceParent := ctx.Value(traceParentKey{})
+	if traceParent == nil {
+		return pkg.TraceParent{}, errors.New("no traceparent found")
+	}
+	return traceParent.(pkg.TraceParent), nil
+}
+
+type metaKey struct{}
+
+func setMetaToCtx(ctx context.Context, meta map[string]interface{}) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFromContext returns the _meta object the client attached to the request, e.g. to read custom fields
+// like a trace id. The second return value is false if the request carried no _meta.
+func MetaFromContext(ctx context.Context) (map[string]interface{}, bool) {
+	meta, ok := ctx.Value(metaKey{}).(map[string]interface{})
+	return meta, ok
+}
+
+type rootCallIDKey struct{}
+
+func setRootCallIDToCtx(ctx context.Context, rootCallID string) context.Context {
+	return context.WithValue(ctx, rootCallIDKey{}, rootCallID)
+}
+
+// RootCallID returns the id shared by all operations nested under the same root request, e.g. a tool call,
+// the sampling it issues and the tool calls the client makes while serving that sampling.
+// Requests the server sends to the client carry it in _meta automatically.
+func RootCallID(ctx context.Context) (string, bool) {
+	rootCallID, ok := ctx.Value(rootCallIDKey{}).(string)
+	return rootCallID, ok
+}
+
+type toolKey struct{}
+
+func setToolToCtx(ctx context.Context, tool *protocol.Tool) context.Context {
+	return context.WithValue(ctx, toolKey{}, tool)
+}
+
+// ToolFromContext returns the registered tool being called, so that middlewares can decide by its annotations
+// or schema, e.g. skip authentication for read-only tools. It returns nil outside of a call to a registered tool,
+// including calls answered by the unknown tool handler.
+func ToolFromContext(ctx context.Context) *protocol.Tool {
+	tool, _ := ctx.Value(toolKey{}).(*protocol.Tool)
+	return tool
+}
+
+// SessionStore returns the key/value store of the session in ctx, values set by one handler are visible to later
+// handlers of the same session until it is closed. It returns nil, which holds nothing, outside of a session,
+// e.g. in stateless mode.
+func SessionStore(ctx context.Context) *session.Store {
+	server, ok := getServerFromCtx(ctx)
+	if !ok {
+		return nil
+	}
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return nil
+	}
+	s, ok := server.sessionManager.GetSession(sessionID)
+	if !ok {
+		return nil
+	}
+	return s.GetStore()
+}
+
+type requestIDKey struct{}
+
+func setRequestIDToCtx(ctx context.Context, requestID protocol.RequestID) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func getRequestIDFromCtx(ctx context.Context) (protocol.RequestID, bool) {
+	requestID := ctx.Value(requestIDKey{})
+	return requestID, requestID != nil
+}
+
+type partialResultWriterKey struct{}
+
+func setPartialResultWriterToCtx(ctx context.Context, writer *PartialResultWriter) context.Context {
+	return context.WithValue(ctx, partialResultWriterKey{}, writer)
+}
+
+// PartialResultWriterFromContext returns the writer for partial results of the tool call in ctx,
+// it returns nil outside of a tool call.
+func PartialResultWriterFromContext(ctx context.Context) *PartialResultWriter {
+	writer, _ := ctx.Value(partialResultWriterKey{}).(*PartialResultWriter)
+	return writer
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestStructuredToolError(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	invalidTool := protocol.NewToolWithInputSchema("invalid_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(invalidTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return nil, fmt.Errorf("wrapped: %w", protocol.NewInvalidParamsError("bad city", map[string]interface{}{"field": "city"}))
+	})
+	plainTool := protocol.NewToolWithInputSchema("plain_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(plainTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+	type weatherArgs struct {
+		City string `json:"city" required:"true"`
+		Days int    `json:"days" minimum:"1"`
+	}
+	validatedTool, err := protocol.NewTool("validated_tool", "", weatherArgs{})
+	if err != nil {
+		t.Fatalf("NewTool: %+v", err)
+	}
+	server.RegisterTool(validatedTool, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		var args weatherArgs
+		if err := protocol.VerifyAndUnmarshal(req.RawArguments, &args); err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]interface{}
+		code    int
+		message string
+		data    interface{}
+	}{
+		{name: "protocol error", tool: invalidTool.Name, code: protocol.InvalidParams, message: "bad city", data: map[string]interface{}{"field": "city"}},
+		{name: "plain error", tool: plainTool.Name, code: protocol.InternalError, message: "boom"},
+		{
+			name: "validation error", tool: validatedTool.Name, args: map[string]interface{}{"days": 0}, code: protocol.InvalidParams,
+			message: "data validation failed against the provided schema: city: is required; days: must be greater than or equal to 1",
+			data: map[string]interface{}{"fields": []interface{}{
+				map[string]interface{}{"field": "city", "reason": "is required"},
+				map[string]interface{}{"field": "days", "reason": "must be greater than or equal to 1"},
+			}},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if args == nil {
+				args = map[string]interface{}{}
+			}
+			testWriteRequest(t, in, i+1, protocol.ToolsCall, protocol.NewCallToolRequest(tt.tool, args))
+			resp := testReadMessage(t, outScan)
+			errObj, _ := resp["error"].(map[string]interface{})
+			if errObj["code"] != float64(tt.code) || errObj["message"] != tt.message || !reflect.DeepEqual(errObj["data"], tt.data) {
+				t.Fatalf("unexpected error object %v", resp["error"])
+			}
+		})
+	}
+}
+
+func TestMalformedInput(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+	testServerInit(t, server, in, outScan)
+
+	tests := []struct {
+		name  string
+		input string
+		id    interface{}
+		code  int
+	}{
+		{name: "invalid JSON", input: `{"jsonrpc":"2.0","id":1,"method":`, code: protocol.ParseError},
+		{name: "garbage", input: `not json at all`, code: protocol.ParseError},
+		{name: "not an object", input: `[1,2,3]`, code: protocol.InvalidRequest},
+		{name: "missing method", input: `{"jsonrpc":"2.0"}`, code: protocol.InvalidRequest},
+		{name: "request missing method", input: `{"jsonrpc":"2.0","id":7}`, id: float64(7), code: protocol.InvalidRequest},
+		{name: "missing jsonrpc", input: `{"id":"a","method":"ping"}`, id: "a", code: protocol.InvalidRequest},
+		{name: "method not a string", input: `{"jsonrpc":"2.0","id":8,"method":42}`, id: float64(8), code: protocol.InvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := in.Write([]byte(tt.input + "\n")); err != nil {
+				t.Fatalf("in Write: %+v", err)
+			}
+			msg := testReadMessage(t, outScan)
+			if id, ok := msg["id"]; !ok || id != tt.id {
+				t.Fatalf("expected id %v, got %v", tt.id, msg["id"])
+			}
+			respErr, ok := msg["error"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("expected an error response, got %v", msg)
+			}
+			if code := int(respErr["code"].(float64)); code != tt.code {
+				t.Fatalf("expected code %d, got %d", tt.code, code)
+			}
+		})
+	}
+
+	// the connection keeps processing valid messages
+	testWriteRequest(t, in, 9, protocol.Ping, protocol.NewPingRequest())
+	if msg := testReadMessage(t, outScan); msg["id"] != float64(9) || msg["result"] == nil {
+		t.Fatalf("expected a ping response, got %v", msg)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"time"
+)
+
+type EventType string
+
+const (
+	EventSessionStarted    EventType = "session_started"
+	EventSessionEnded      EventType = "session_ended"
+	EventToolCalled        EventType = "tool_called"
+	EventErrorOccurred     EventType = "error_occurred"
+	EventCapabilityChanged EventType = "capability_changed"
+)
+
+const defaultEventBufferSize = 64
+
+// ServerEvent is a lifecycle event emitted on the channel returned by Server.Events
+type ServerEvent struct {
+	Type      EventType
+	Time      time.Time
+	SessionID string
+
+	// Method is the JSON-RPC method of the request that failed, set for EventErrorOccurred
+	Method string
+	// ToolName is set for EventToolCalled
+	ToolName string
+	// Capability is the capability whose list changed ("tools", "prompts" or "resources"), set for EventCapabilityChanged
+	Capability string
+	// Err is the error returned by the tool or request, if any
+	Err error
+}
+
+// WithEventBufferSize sets the capacity of the channel returned by Server.Events, events are dropped once it is full
+func WithEventBufferSize(size int) Option {
+	return func(s *Server) {
+		s.events = make(chan ServerEvent, size)
+	}
+}
+
+// Events returns the channel lifecycle events are streamed to.
+// The channel is bounded and never blocks the server: events are dropped when the consumer falls behind.
+func (server *Server) Events() <-chan ServerEvent {
+	return server.events
+}
+
+func (server *Server) emitEvent(event ServerEvent) {
+	event.Time = time.Now()
+	select {
+	case server.events <- event:
+	default:
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestEventsToolCalled(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("unexpected error %v", resp["error"])
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-server.Events():
+			if event.Type != EventToolCalled {
+				continue
+			}
+			if event.ToolName != testTool.Name {
+				t.Fatalf("expected tool name %s, got %s", testTool.Name, event.ToolName)
+			}
+			if event.SessionID == "" {
+				t.Fatal("expected session id to be set")
+			}
+			if event.Err != nil {
+				t.Fatalf("unexpected event error %v", event.Err)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for tool called event")
+		}
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/yosida95/uritemplate/v3"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
 	"github.com/hhfgeg/go-mcp/transport"
 )
 
@@ -17,15 +21,17 @@ func (server *Server) handleRequestWithPing() (*protocol.PingResult, error) {
 }
 
 func (server *Server) handleRequestWithInitialize(ctx context.Context, sessionID string, rawParams json.RawMessage) (*protocol.InitializeResult, error) {
-	var request *protocol.InitializeRequest
-	if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
-		return nil, err
+	// minimal clients may omit clientInfo or even the whole params
+	request := &protocol.InitializeRequest{}
+	if len(rawParams) > 0 {
+		if err := pkg.JSONUnmarshal(rawParams, request); err != nil {
+			return nil, err
+		}
 	}
 
-	// Version negotiation: if client's version is supported, use it; otherwise use server's latest version
-	protocolVersion := request.ProtocolVersion
-	if _, ok := protocol.SupportedVersion[request.ProtocolVersion]; !ok {
-		protocolVersion = protocol.Version
+	protocolVersion, err := server.negotiateProtocolVersion(request.ProtocolVersion)
+	if err != nil {
+		return nil, err
 	}
 
 	if midVar, ok := ctx.Value(transport.SessionIDForReturnKey{}).(*transport.SessionIDForReturn); ok {
@@ -38,11 +44,14 @@ func (server *Server) handleRequestWithInitialize(ctx context.Context, sessionID
 		if !ok {
 			return nil, pkg.ErrLackSession
 		}
+		if !s.BeginInitialize() {
+			return nil, pkg.ErrSessionAlreadyInitialized
+		}
 		s.SetClientInfo(request.ClientInfo, request.Capabilities)
-		s.SetReceivedInitRequest()
+		s.SetProtocolVersion(protocolVersion)
 	}
 
-	return protocol.NewInitializeResult(server.serverInfo, server.capabilities, protocolVersion, server.instructions), nil
+	return protocol.NewInitializeResult(server.serverInfo, server.advertisedCapabilities(), protocolVersion, server.instructions), nil
 }
 
 func (server *Server) handleRequestWithListPrompts(rawParams json.RawMessage) (*protocol.ListPromptsResult, error) {
@@ -50,9 +59,9 @@ func (server *Server) handleRequestWithListPrompts(rawParams json.RawMessage) (*
 		return nil, pkg.ErrServerNotSupport
 	}
 
-	var request *protocol.ListPromptsRequest
+	request := &protocol.ListPromptsRequest{}
 	if len(rawParams) > 0 {
-		if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
+		if err := pkg.JSONUnmarshal(rawParams, request); err != nil {
 			return nil, err
 		}
 	}
@@ -83,6 +92,7 @@ func (server *Server) handleRequestWithGetPrompt(ctx context.Context, rawParams
 	if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
 		return nil, err
 	}
+	request.SetArgumentCoercion(server.argumentCoercion)
 
 	entry, ok := server.prompts.Load(request.Name)
 	if !ok {
@@ -95,9 +105,9 @@ func (server *Server) handleRequestWithListResources(rawParams json.RawMessage)
 	if server.capabilities.Resources == nil {
 		return nil, pkg.ErrServerNotSupport
 	}
-	var request *protocol.ListResourcesRequest
+	request := &protocol.ListResourcesRequest{}
 	if len(rawParams) > 0 {
-		if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
+		if err := pkg.JSONUnmarshal(rawParams, request); err != nil {
 			return nil, err
 		}
 	}
@@ -125,9 +135,9 @@ func (server *Server) handleRequestWithListResourceTemplates(rawParams json.RawM
 		return nil, pkg.ErrServerNotSupport
 	}
 
-	var request *protocol.ListResourceTemplatesRequest
+	request := &protocol.ListResourceTemplatesRequest{}
 	if len(rawParams) > 0 {
-		if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
+		if err := pkg.JSONUnmarshal(rawParams, request); err != nil {
 			return nil, err
 		}
 	}
@@ -159,6 +169,15 @@ func (server *Server) handleRequestWithReadResource(ctx context.Context, rawPara
 		return nil, err
 	}
 
+	if err := server.checkResourceScheme(request.URI); err != nil {
+		return nil, err
+	}
+
+	if r := request.Range; r != nil && (r.Offset < 0 || r.Length < 0) {
+		return nil, protocol.NewInvalidParamsError(fmt.Sprintf("invalid range: offset=%d length=%d must not be negative", r.Offset, r.Length),
+			map[string]interface{}{"offset": r.Offset, "length": r.Length})
+	}
+
 	var handler ResourceHandlerFunc
 	if entry, ok := server.resources.Load(request.URI); ok {
 		handler = entry.handler
@@ -180,11 +199,28 @@ func (server *Server) handleRequestWithReadResource(ctx context.Context, rawPara
 	if handler == nil {
 		return nil, fmt.Errorf("missing resource, resourceName=%s", request.URI)
 	}
-	return handler(ctx, request)
+
+	if server.resourceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, server.resourceTimeout)
+		defer cancel()
+	}
+	result, err := handler(ctx, request)
+	if err == nil {
+		// a result returned once the handler's context is done comes too late
+		err = ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if server.mimeDetection {
+		detectMimeTypes(result)
+	}
+	return result, nil
 }
 
 func (server *Server) handleRequestWithSubscribeResourceChange(sessionID string, rawParams json.RawMessage) (*protocol.SubscribeResult, error) {
-	if server.capabilities.Resources == nil && !server.capabilities.Resources.Subscribe {
+	if server.capabilities.Resources == nil || !server.capabilities.Resources.Subscribe {
 		return nil, pkg.ErrServerNotSupport
 	}
 
@@ -197,12 +233,14 @@ func (server *Server) handleRequestWithSubscribeResourceChange(sessionID string,
 	if !ok {
 		return nil, pkg.ErrLackSession
 	}
-	s.GetSubscribedResources().Set(request.URI, struct{}{})
+	if !s.SubscribeResource(request.URI, server.maxSubscriptionsPerSession) {
+		return nil, fmt.Errorf("%w: limit=%d", pkg.ErrSubscriptionLimitExceeded, server.maxSubscriptionsPerSession)
+	}
 	return protocol.NewSubscribeResult(), nil
 }
 
 func (server *Server) handleRequestWithUnSubscribeResourceChange(sessionID string, rawParams json.RawMessage) (*protocol.UnsubscribeResult, error) {
-	if server.capabilities.Resources == nil && !server.capabilities.Resources.Subscribe {
+	if server.capabilities.Resources == nil || !server.capabilities.Resources.Subscribe {
 		return nil, pkg.ErrServerNotSupport
 	}
 
@@ -219,7 +257,7 @@ func (server *Server) handleRequestWithUnSubscribeResourceChange(sessionID strin
 	return protocol.NewUnsubscribeResult(), nil
 }
 
-func (server *Server) handleRequestWithListTools(rawParams json.RawMessage) (*protocol.ListToolsResult, error) {
+func (server *Server) handleRequestWithListTools(ctx context.Context, rawParams json.RawMessage) (*protocol.ListToolsResult, error) {
 	if server.capabilities.Tools == nil {
 		return nil, pkg.ErrServerNotSupport
 	}
@@ -231,11 +269,23 @@ func (server *Server) handleRequestWithListTools(rawParams json.RawMessage) (*pr
 		}
 	}
 
+	sessionTools := server.sessionToolsOf(ctx)
+	shadowed := make(map[string]struct{}, len(sessionTools))
+	for _, t := range sessionTools {
+		shadowed[t.reg.name] = struct{}{}
+	}
 	tools := make([]*protocol.Tool, 0)
-	server.tools.Range(func(_ string, entry *toolEntry) bool {
-		tools = append(tools, entry.tool)
+	server.tools.Range(func(name string, entry *toolEntry) bool {
+		if _, ok := shadowed[name]; !ok && !entry.disabled() {
+			tools = append(tools, entry.tool)
+		}
 		return true
 	})
+	for _, t := range sessionTools {
+		if entry := t.entry(); !entry.disabled() {
+			tools = append(tools, entry.tool)
+		}
+	}
 	if server.paginationLimit > 0 {
 		resourcesToReturn, nextCursor, err := protocol.PaginationLimit(tools, request.Cursor, server.paginationLimit)
 		return &protocol.ListToolsResult{
@@ -243,7 +293,70 @@ func (server *Server) handleRequestWithListTools(rawParams json.RawMessage) (*pr
 			NextCursor: nextCursor,
 		}, err
 	}
-	return &protocol.ListToolsResult{Tools: tools}, nil
+	if server.maxMessageSize <= 0 {
+		return &protocol.ListToolsResult{Tools: tools}, nil
+	}
+
+	// a limit beyond the number of tools lists them all from the cursor on, which is set once a listing was truncated
+	tools, _, err := protocol.PaginationLimit(tools, request.Cursor, len(tools)+1)
+	if err != nil {
+		return nil, err
+	}
+	return server.truncateListTools(&protocol.ListToolsResult{Tools: tools})
+}
+
+// jsonRPCEnvelopeSize is reserved for the JSON-RPC response wrapping a result, e.g. {"jsonrpc":"2.0","id":...,"result":}
+const jsonRPCEnvelopeSize = 64
+
+// truncateListTools keeps the longest prefix of the tools fitting the max message size, the remaining tools
+// can be listed with the returned nextCursor. It fails if not even the first tool fits, an empty page would
+// end the listing for the client.
+func (server *Server) truncateListTools(result *protocol.ListToolsResult) (*protocol.ListToolsResult, error) {
+	fits := func(r *protocol.ListToolsResult) (bool, error) {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return false, err
+		}
+		return len(b)+jsonRPCEnvelopeSize <= server.maxMessageSize, nil
+	}
+	truncated := func(n int) *protocol.ListToolsResult {
+		r := &protocol.ListToolsResult{
+			Meta: map[string]interface{}{protocol.WarningKey: fmt.Sprintf(
+				"tools list truncated to %d of %d tools to fit the max message size of %d bytes, list the others with nextCursor",
+				n, len(result.Tools), server.maxMessageSize)},
+			Tools: result.Tools[:n],
+		}
+		if n > 0 {
+			r.NextCursor = protocol.NewCursor(result.Tools[n-1].Name)
+		}
+		return r
+	}
+
+	if ok, err := fits(result); ok || err != nil {
+		return result, err
+	}
+
+	// binary search the largest number of tools that fits, a listing that fits keeps fitting with fewer tools
+	lo, hi := 0, len(result.Tools)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		ok, err := fits(truncated(mid))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		return nil, protocol.NewInternalError(fmt.Sprintf("tool %s alone exceeds the max message size of %d bytes",
+			result.Tools[0].Name, server.maxMessageSize), map[string]interface{}{"tool": result.Tools[0].Name})
+	}
+	server.logger.Warn("tools list truncated to fit the max message size", "tools", lo, "total", len(result.Tools),
+		"maxMessageSize", server.maxMessageSize)
+	return truncated(lo), nil
 }
 
 func (server *Server) handleRequestWithCallTool(ctx context.Context, rawParams json.RawMessage) (*protocol.CallToolResult, error) {
@@ -255,13 +368,55 @@ func (server *Server) handleRequestWithCallTool(ctx context.Context, rawParams j
 	if err := pkg.JSONUnmarshal(rawParams, &request); err != nil {
 		return nil, err
 	}
+	request.SetArgumentCoercion(server.argumentCoercion)
 
-	entry, ok := server.tools.Load(request.Name)
-	if !ok {
-		return nil, fmt.Errorf("missing tool, toolName=%s", request.Name)
+	var handler ToolHandlerFunc
+	if entry, ok := server.lookupTool(ctx, request.Name); ok && !entry.disabled() {
+		handler = entry.handler
+		ctx = setToolToCtx(ctx, entry.tool)
+	} else if handler, _ = server.unknownToolHandler.Load().(ToolHandlerFunc); handler == nil {
+		return nil, fmt.Errorf("%w: missing tool, toolName=%s", pkg.ErrMethodNotSupport, request.Name)
 	}
 
-	return entry.handler(ctx, request)
+	if server.toolTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, server.toolTimeout)
+		defer cancel()
+	}
+
+	if requestID, ok := getRequestIDFromCtx(ctx); ok {
+		ctx = setPartialResultWriterToCtx(ctx, &PartialResultWriter{server: server, ctx: ctx, requestID: requestID})
+	}
+
+	start := time.Now()
+	var (
+		result *protocol.CallToolResult
+		err    error
+	)
+	if key, ok := idempotencyKeyOf(ctx, request); ok && server.idempotencyStore != nil {
+		result, err = server.callIdempotent(ctx, key, func() (*protocol.CallToolResult, error) {
+			return handler(ctx, request)
+		})
+	} else {
+		result, err = handler(ctx, request)
+	}
+	if err == nil {
+		// a result returned once the handler's context is done comes too late
+		err = ctx.Err()
+	}
+	sessionID, _ := GetSessionIDFromCtx(ctx)
+	server.emitEvent(ServerEvent{Type: EventToolCalled, SessionID: sessionID, ToolName: request.Name, Err: err})
+	if err == nil {
+		result, err = server.transformResult(ctx, request, result)
+	}
+	server.traceToolResult(ctx, result)
+	if len(server.observers) > 0 {
+		server.observers.ObserveToolResult(ctx, newToolCallRecord(request, result, err, time.Since(start)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 func (server *Server) handleNotifyWithInitialized(sessionID string, rawParams json.RawMessage) error {
@@ -281,10 +436,13 @@ func (server *Server) handleNotifyWithInitialized(sessionID string, rawParams js
 		return pkg.ErrLackSession
 	}
 
-	if !s.GetReceivedInitRequest() {
+	switch s.GetInitPhase() {
+	case session.InitPhaseUninitialized:
 		return fmt.Errorf("the server has not received the client's initialization request")
+	case session.InitPhaseReady:
+		return nil // a repeated notification changes nothing
 	}
-	s.SetReady()
+	s.CompleteInitialize()
 	return nil
 }
 
@@ -311,3 +469,21 @@ func (server *Server) handleNotifyWithCancelled(sessionID string, rawParams json
 func matchesTemplate(uri string, template *uritemplate.Template) bool {
 	return template.Regexp().MatchString(uri)
 }
+
+// checkResourceScheme rejects uri if its scheme is not one of the schemes registered by WithResourceSchemes
+func (server *Server) checkResourceScheme(uri string) error {
+	if len(server.resourceSchemes) == 0 {
+		return nil
+	}
+
+	var scheme string
+	if u, err := url.Parse(uri); err == nil {
+		scheme = strings.ToLower(u.Scheme)
+	}
+	for _, supported := range server.resourceSchemes {
+		if scheme == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: scheme=%q, uri=%s", pkg.ErrUnsupportedResourceScheme, scheme, uri)
+}
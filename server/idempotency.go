@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// WithIdempotency remembers the results of tool calls carrying an idempotency key in their _meta,
+// see protocol.IdempotencyKey, for ttl, so that a client retrying a call after e.g. a network failure gets
+// the result of the original call instead of running a destructive tool twice. A retry arriving while the
+// original call runs waits for it. Keys are scoped to the session and the tool, outside of a session,
+// e.g. in stateless mode, they are ignored since the calls of different clients can't be told apart.
+// Only successful results without IsError set are remembered, so a failed call is run again when retried.
+// store defaults to an in-memory LRU cache of 1024 results when nil.
+func WithIdempotency(store ResultCache, ttl time.Duration) Option {
+	return func(s *Server) {
+		if store == nil {
+			store = pkg.NewLRUCache[*protocol.CallToolResult](defaultCacheSize)
+		}
+		s.idempotencyStore = store
+		s.idempotencyTTL = ttl
+	}
+}
+
+// idempotentCall is a call with an idempotency key that is running, retries wait for it to be done
+type idempotentCall struct {
+	done   chan struct{}
+	result *protocol.CallToolResult
+	err    error
+}
+
+// idempotencyKeyOf returns the key the result of the call is remembered under, false if the call carries none
+// or is made outside of a session
+func idempotencyKeyOf(ctx context.Context, req *protocol.CallToolRequest) (string, bool) {
+	key, _ := req.Meta[protocol.IdempotencyKey].(string)
+	if key == "" {
+		return "", false
+	}
+	sessionID, _ := GetSessionIDFromCtx(ctx)
+	if sessionID == "" {
+		return "", false
+	}
+	return sessionID + "\x00" + req.Name + "\x00" + key, true
+}
+
+// callIdempotent returns the remembered result of the call with key if any, otherwise it calls handle
+// and remembers its result if successful
+func (server *Server) callIdempotent(ctx context.Context, key string,
+	handle func() (*protocol.CallToolResult, error),
+) (*protocol.CallToolResult, error) {
+	if result, ok := server.idempotencyStore.Get(key); ok {
+		return copyCallToolResult(result), nil
+	}
+
+	call := &idempotentCall{done: make(chan struct{})}
+	if running, loaded := server.idempotentCalls.LoadOrStore(key, call); loaded {
+		select {
+		case <-running.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if running.err == nil && running.result != nil && !running.result.IsError {
+			return copyCallToolResult(running.result), nil
+		}
+		// the original call failed, the retry runs it again
+		return server.callIdempotent(ctx, key, handle)
+	}
+	defer func() {
+		server.idempotentCalls.Delete(key)
+		close(call.done)
+	}()
+
+	call.result, call.err = handle()
+	if call.err == nil && call.result != nil && !call.result.IsError {
+		server.idempotencyStore.Set(key, copyCallToolResult(call.result), server.idempotencyTTL)
+	}
+	return call.result, call.err
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/client"
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+func TestIdempotency(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithIdempotency(nil, time.Minute))
+
+	var calls int32
+	deleteTool := protocol.NewToolWithInputSchema("delete_file", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(deleteTool, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		n := atomic.AddInt32(&calls, 1)
+		text := fmt.Sprintf("call %d", n)
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: text}}, req.Arguments["fail"] == true), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	id := 0
+	call := func(key string, args map[string]interface{}) string {
+		id++
+		req := protocol.NewCallToolRequest(deleteTool.Name, args)
+		if key != "" {
+			req.Meta = map[string]interface{}{protocol.IdempotencyKey: key}
+		}
+		testWriteRequest(t, in, id, protocol.ToolsCall, req)
+		resp := testReadMessage(t, outScan)
+		result, _ := resp["result"].(map[string]interface{})
+		content, _ := result["content"].([]interface{})
+		if len(content) != 1 {
+			t.Fatalf("unexpected response %v", resp)
+		}
+		return content[0].(map[string]interface{})["text"].(string)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "first call runs", key: "a", want: "call 1"},
+		{name: "retry is answered with the original result", key: "a", want: "call 1"},
+		{name: "another key runs", key: "b", want: "call 2"},
+		{name: "no key runs", want: "call 3"},
+		{name: "error result runs", key: "c", args: map[string]interface{}{"fail": true}, want: "call 4"},
+		{name: "error result is not remembered", key: "c", args: map[string]interface{}{"fail": true}, want: "call 5"},
+	}
+	for _, tt := range tests {
+		if got := call(tt.key, tt.args); got != tt.want {
+			t.Fatalf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestIdempotencyStateless(t *testing.T) {
+	tr, handler, err := transport.NewStreamableHTTPServerTransportAndHandler(
+		transport.WithStreamableHTTPServerTransportAndHandlerOptionStateMode(transport.Stateless))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %+v", err)
+	}
+	server, err := NewServer(tr, WithServerInfo(protocol.Implementation{Name: "ExampleServer", Version: "1.0.0"}),
+		WithIdempotency(nil, time.Minute))
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	whoami := protocol.NewToolWithInputSchema("whoami", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(whoami, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		text := fmt.Sprint(req.Arguments["client"])
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: text}}, false), nil
+	})
+	go func() {
+		if err := server.Run(); err != nil {
+			t.Errorf("server start: %+v", err)
+		}
+	}()
+	httpServer := httptest.NewServer(handler.HandleMCP())
+	t.Cleanup(func() {
+		httpServer.Close()
+		_ = server.Shutdown(context.Background())
+	})
+
+	// both clients happen to choose the same key, neither may get the result of the other
+	for _, name := range []string{"a", "b"} {
+		clientTransport, err := transport.NewStreamableHTTPClientTransport(httpServer.URL)
+		if err != nil {
+			t.Fatalf("NewStreamableHTTPClientTransport: %+v", err)
+		}
+		mcpClient, err := client.NewClient(clientTransport)
+		if err != nil {
+			t.Fatalf("NewClient: %+v", err)
+		}
+		t.Cleanup(func() { _ = mcpClient.Close() })
+
+		req := protocol.NewCallToolRequest(whoami.Name, map[string]interface{}{"client": name})
+		req.Meta = map[string]interface{}{protocol.IdempotencyKey: "key"}
+		result, err := mcpClient.CallTool(context.Background(), req)
+		if err != nil {
+			t.Fatalf("CallTool: %+v", err)
+		}
+		if got := result.Content[0].(*protocol.TextContent).Text; got != name {
+			t.Fatalf("client %s got the result of client %s", name, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+func TestInitializeWithoutClientInfo(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testWriteRequest(t, in, 1, protocol.Initialize, map[string]interface{}{
+		"protocolVersion": protocol.Version,
+		"capabilities":    map[string]interface{}{},
+	})
+	resp := testReadMessage(t, outScan)
+	if resp["error"] != nil {
+		t.Fatalf("initialize without clientInfo: unexpected error %v", resp["error"])
+	}
+
+	var states []*session.State
+	server.sessionManager.RangeSessions(func(_ string, state *session.State) bool {
+		states = append(states, state)
+		return true
+	})
+	if len(states) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(states))
+	}
+
+	if info := states[0].GetClientInfo(); info.Name != "" || info.Version != "" {
+		t.Fatalf("expected zero client info, got %+v", info)
+	}
+}
+
+func TestInitializeVersionNegotiation(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{name: "supported", requested: "2024-11-05", want: "2024-11-05"},
+		{name: "newer than supported", requested: "2099-01-01", want: "2025-03-26"},
+		{name: "between supported", requested: "2025-01-01", want: "2024-11-05"},
+		{name: "omitted", requested: "", want: "2025-03-26"},
+		{name: "older than supported", requested: "2024-01-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, in, outScan := newTestServer(t, WithSupportedProtocolVersions("2024-11-05", "2025-03-26"))
+
+			testWriteRequest(t, in, 1, protocol.Initialize, map[string]interface{}{
+				"protocolVersion": tt.requested,
+				"capabilities":    map[string]interface{}{},
+			})
+			resp := testReadMessage(t, outScan)
+
+			if tt.wantErr {
+				errObj, _ := resp["error"].(map[string]interface{})
+				if errObj["code"] != float64(protocol.InvalidParams) {
+					t.Fatalf("expected invalid params error, got %v", resp)
+				}
+				return
+			}
+
+			result, _ := resp["result"].(map[string]interface{})
+			if result["protocolVersion"] != tt.want {
+				t.Fatalf("expected protocol version %s, got %v", tt.want, resp)
+			}
+
+			server.sessionManager.RangeSessions(func(_ string, state *session.State) bool {
+				if got := state.GetProtocolVersion(); got != tt.want {
+					t.Fatalf("expected session protocol version %s, got %s", tt.want, got)
+				}
+				return true
+			})
+		})
+	}
+}
+
+func TestToolsCapabilityWithoutTools(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantTools bool
+	}{
+		{name: "omitted by default"},
+		{name: "omitted with pagination", opts: []Option{WithPagination(2)}},
+		{
+			name:      "forced",
+			opts:      []Option{WithCapabilities(protocol.ServerCapabilities{Tools: &protocol.ToolsCapability{}})},
+			wantTools: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, in, outScan := newTestServer(t, tt.opts...)
+
+			testWriteRequest(t, in, 1, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version})
+			resp := testReadMessage(t, outScan)
+			result, _ := resp["result"].(map[string]interface{})
+			capabilities, _ := result["capabilities"].(map[string]interface{})
+			if _, ok := capabilities["tools"]; ok != tt.wantTools {
+				t.Fatalf("expected tools capability advertised=%v, got %v", tt.wantTools, capabilities)
+			}
+
+			testWriteRequest(t, in, 2, protocol.ToolsList, protocol.ListToolsRequest{})
+			resp = testReadMessage(t, outScan)
+			result, _ = resp["result"].(map[string]interface{})
+			if tools, ok := result["tools"].([]interface{}); !ok || len(tools) != 0 {
+				t.Fatalf("expected empty tools array, got %v", resp)
+			}
+		})
+	}
+}
+
+func TestInitializeCapabilities(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]interface{}
+	}{
+		{
+			name: "derived",
+			want: map[string]interface{}{
+				"tools":   map[string]interface{}{"listChanged": true},
+				"prompts": map[string]interface{}{"listChanged": true},
+			},
+		},
+		{
+			name: "explicit",
+			opts: []Option{WithCapabilities(protocol.ServerCapabilities{
+				Tools:     &protocol.ToolsCapability{},
+				Resources: &protocol.ResourcesCapability{Subscribe: true},
+			})},
+			want: map[string]interface{}{
+				"tools":     map[string]interface{}{},
+				"resources": map[string]interface{}{"subscribe": true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, in, outScan := newTestServer(t, tt.opts...)
+			server.RegisterTool(protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object}),
+				func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+					return protocol.NewCallToolResult(nil, false), nil
+				})
+			server.RegisterPrompt(&protocol.Prompt{Name: "test_prompt"},
+				func(context.Context, *protocol.GetPromptRequest) (*protocol.GetPromptResult, error) {
+					return &protocol.GetPromptResult{}, nil
+				})
+
+			testWriteRequest(t, in, 1, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version})
+			resp := testReadMessage(t, outScan)
+			result, _ := resp["result"].(map[string]interface{})
+			if !reflect.DeepEqual(result["capabilities"], tt.want) {
+				t.Fatalf("capabilities not as expected.\ngot  = %v\nwant = %v", result["capabilities"], tt.want)
+			}
+		})
+	}
+}
+
+func TestInitializeServerInfoAndInstructions(t *testing.T) {
+	_, in, outScan := newTestServer(t,
+		WithServerInfo(protocol.Implementation{Name: "weather", Version: "1.2.3"}),
+		WithInstructions("Call get_forecast before answering weather questions."))
+
+	testWriteRequest(t, in, 1, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version})
+	result, _ := testReadMessage(t, outScan)["result"].(map[string]interface{})
+
+	want := map[string]interface{}{"name": "weather", "version": "1.2.3"}
+	if !reflect.DeepEqual(result["serverInfo"], want) {
+		t.Fatalf("serverInfo not as expected.\ngot  = %v\nwant = %v", result["serverInfo"], want)
+	}
+	if result["instructions"] != "Call get_forecast before answering weather questions." {
+		t.Fatalf("instructions not as expected, got %v", result["instructions"])
+	}
+}
+
+func TestDefaultServerInfo(t *testing.T) {
+	server, err := NewServer(transport.NewMockServerTransport(nil, nil))
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	if server.serverInfo.Name == "" || server.serverInfo.Version == "" {
+		t.Fatalf("expected default server name and version, got %+v", server.serverInfo)
+	}
+
+	server, err = NewServer(transport.NewMockServerTransport(nil, nil), WithServerInfo(protocol.Implementation{Name: "weather"}))
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	if server.serverInfo.Name != "weather" || server.serverInfo.Version == "" {
+		t.Fatalf("expected configured name and default version, got %+v", server.serverInfo)
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestKeepAlive(t *testing.T) {
+	t.Run("answered", func(t *testing.T) {
+		server, _, sessionID := newTestServerAndClient(t, []Option{WithKeepAlive(20*time.Millisecond, time.Second)}, nil, nil)
+
+		time.Sleep(200 * time.Millisecond)
+		if _, ok := server.sessionManager.GetSession(sessionID); !ok {
+			t.Fatal("expected the session of a client answering pings to be kept")
+		}
+	})
+
+	t.Run("unanswered", func(t *testing.T) {
+		server, in, outScan := newTestServer(t, WithKeepAlive(50*time.Millisecond, 20*time.Millisecond))
+		testServerInit(t, server, in, outScan)
+
+		for i := 0; i < 3; i++ {
+			if ping := testReadMessage(t, outScan); ping["method"] != string(protocol.Ping) {
+				t.Fatalf("expected a keep-alive ping, got %v", ping)
+			}
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for !server.sessionManager.IsEmpty() {
+			if time.Now().After(deadline) {
+				t.Fatal("expected the session to be closed after unanswered pings")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// WithLegacyResultFormat serves tool results to clients whose name matches clientNamePattern, a regular expression,
+// in the legacy format where content is a single string joining the text content instead of a content array.
+// It is an interop shim for older clients, non-text content is dropped for them. It panics if the pattern is invalid.
+func WithLegacyResultFormat(clientNamePattern string) Option {
+	pattern := regexp.MustCompile(clientNamePattern)
+	return func(s *Server) {
+		s.legacyResultClients = pattern
+	}
+}
+
+// legacyCallToolResult is the tool result format expected by legacy clients
+type legacyCallToolResult struct {
+	Content string `json:"content"`
+	IsError bool   `json:"isError,omitempty"`
+}
+
+// formatCallToolResult converts result to the legacy format if the client of the session expects it
+func (server *Server) formatCallToolResult(sessionID string, result *protocol.CallToolResult) protocol.ServerResponse {
+	if server.legacyResultClients == nil || result == nil {
+		return result
+	}
+	s, ok := server.sessionManager.GetSession(sessionID)
+	if !ok || !server.legacyResultClients.MatchString(s.GetClientInfo().Name) {
+		return result
+	}
+
+	texts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		if text, ok := content.(*protocol.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return &legacyCallToolResult{Content: strings.Join(texts, "\n"), IsError: result.IsError}
+}
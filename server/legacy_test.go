@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestLegacyResultFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientName string
+		want       interface{}
+	}{
+		{name: "legacy client", clientName: "old-agent/1.2", want: "hello\nworld"},
+		{name: "modern client", clientName: "new-agent", want: []interface{}{
+			map[string]interface{}{"type": "text", "text": "hello"},
+			map[string]interface{}{"type": "text", "text": "world"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, in, outScan := newTestServer(t, WithLegacyResultFormat("^old-agent"))
+
+			testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+			server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+				return protocol.NewCallToolResult([]protocol.Content{
+					&protocol.TextContent{Type: "text", Text: "hello"},
+					&protocol.TextContent{Type: "text", Text: "world"},
+				}, false), nil
+			})
+
+			testWriteRequest(t, in, 1, protocol.Initialize, protocol.NewInitializeRequest(
+				&protocol.Implementation{Name: tt.clientName, Version: "1.0.0"}, &protocol.ClientCapabilities{}))
+			testReadMessage(t, outScan)
+
+			testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+			resp := testReadMessage(t, outScan)
+			result, _ := resp["result"].(map[string]interface{})
+			if !reflect.DeepEqual(result["content"], tt.want) {
+				t.Fatalf("unexpected content %#v, want %#v", result["content"], tt.want)
+			}
+		})
+	}
+}
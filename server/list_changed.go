@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/server/session"
+)
+
+// defaultListChangedDebounce is how long list changes are coalesced, e.g. resources registered in a loop
+// at runtime, before a single list changed notification is sent
+const defaultListChangedDebounce = 50 * time.Millisecond
+
+// listChangedNotifier sends a list changed notification to all sessions at most once per debounce,
+// the changes made while a notification is pending are covered by it
+type listChangedNotifier struct {
+	debounce   time.Duration
+	hasClients func() bool
+	send       func()
+
+	mu      sync.Mutex
+	pending bool
+}
+
+func (server *Server) newListChangedNotifier(kind string, send func(context.Context) error) *listChangedNotifier {
+	return &listChangedNotifier{
+		debounce:   server.listChangedDebounce,
+		hasClients: server.hasInitializedSession,
+		send: func() {
+			if !server.hasInitializedSession() {
+				return
+			}
+			// without the listChanged capability clients are not notified
+			if err := send(context.Background()); err != nil && !errors.Is(err, pkg.ErrServerNotSupport) {
+				server.logger.Warn("send notification list changes fail", "kind", kind, "error", err)
+			}
+		},
+	}
+}
+
+func (n *listChangedNotifier) notify() {
+	// clients that are yet to initialize list everything anyway
+	if !n.hasClients() {
+		return
+	}
+	if n.debounce <= 0 {
+		n.send()
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.pending {
+		return
+	}
+	n.pending = true
+	time.AfterFunc(n.debounce, func() {
+		n.mu.Lock()
+		n.pending = false
+		n.mu.Unlock()
+
+		n.send()
+	})
+}
+
+// hasInitializedSession reports whether a client has initialized a session, list changed notifications
+// are only sent to such clients
+func (server *Server) hasInitializedSession() bool {
+	found := false
+	server.sessionManager.RangeSessions(func(_ string, s *session.State) bool {
+		found = s.GetInitPhase() != session.InitPhaseUninitialized
+		return !found
+	})
+	return found
+}
+
+// NotifyResourceListChanged tells the clients that the list of resources or resource templates changed,
+// e.g. when files backing the resources were added. Registering and unregistering resources notify on their own.
+// Notifications are coalesced, see WithListChangedDebounce.
+func (server *Server) NotifyResourceListChanged() {
+	server.resourceListChanged.notify()
+}
+
+// NotifyPromptListChanged tells the clients that the list of prompts changed, registering and unregistering prompts
+// notify on their own. Notifications are coalesced, see WithListChangedDebounce.
+func (server *Server) NotifyPromptListChanged() {
+	server.promptListChanged.notify()
+}
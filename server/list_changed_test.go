@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestResourceListPaginationAndListChanged(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithPagination(2))
+	testServerInit(t, server, in, outScan)
+
+	handler := func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+		return &protocol.ReadResourceResult{}, nil
+	}
+	for i := 0; i < 5; i++ {
+		server.RegisterResource(&protocol.Resource{Name: fmt.Sprintf("file%d", i), URI: fmt.Sprintf("file:///%d.txt", i)}, handler)
+	}
+
+	// the registrations are coalesced into a single notification
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationResourcesListChanged) {
+		t.Fatalf("expected resources list changed notification, got %v", notify)
+	}
+	testWriteRequest(t, in, "ping", protocol.Ping, protocol.NewPingRequest())
+	if resp := testReadMessage(t, outScan); resp["id"] != "ping" {
+		t.Fatalf("expected a single list changed notification, got %v", resp)
+	}
+
+	var names []string
+	cursor := protocol.Cursor("")
+	for page := 0; ; page++ {
+		testWriteRequest(t, in, page, protocol.ResourcesList, protocol.ListResourcesRequest{Cursor: cursor})
+		result, _ := testReadMessage(t, outScan)["result"].(map[string]interface{})
+		resources, _ := result["resources"].([]interface{})
+		if len(resources) > 2 {
+			t.Fatalf("expected at most 2 resources per page, got %d", len(resources))
+		}
+		for _, r := range resources {
+			names = append(names, r.(map[string]interface{})["name"].(string))
+		}
+		next, _ := result["nextCursor"].(string)
+		if next == "" {
+			break
+		}
+		cursor = protocol.Cursor(next)
+	}
+	if len(names) != 5 || names[0] != "file0" || names[4] != "file4" {
+		t.Fatalf("expected all resources across the pages, got %v", names)
+	}
+
+	server.NotifyResourceListChanged()
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationResourcesListChanged) {
+		t.Fatalf("expected resources list changed notification, got %v", notify)
+	}
+}
+
+func TestListPaginationWithoutParams(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithPagination(2))
+	server.RegisterResource(&protocol.Resource{Name: "file", URI: "file:///file.txt"},
+		func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+			return &protocol.ReadResourceResult{}, nil
+		})
+	testServerInit(t, server, in, outScan)
+
+	for i, method := range []protocol.Method{protocol.ResourcesList, protocol.ResourceListTemplates, protocol.PromptsList} {
+		testWriteRequest(t, in, i, method, nil)
+		if resp := testReadMessage(t, outScan); resp["result"] == nil {
+			t.Fatalf("%s without params: expected the first page, got %v", method, resp)
+		}
+	}
+}
+
+func TestPromptListPaginationAndListChanged(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithPagination(2))
+	testServerInit(t, server, in, outScan)
+
+	handler := func(context.Context, *protocol.GetPromptRequest) (*protocol.GetPromptResult, error) {
+		return &protocol.GetPromptResult{}, nil
+	}
+	for i := 0; i < 3; i++ {
+		server.RegisterPrompt(&protocol.Prompt{Name: fmt.Sprintf("prompt%d", i)}, handler)
+	}
+	server.UnregisterPrompt("prompt2")
+
+	// the registrations and the removal are coalesced into a single notification
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationPromptsListChanged) {
+		t.Fatalf("expected prompts list changed notification, got %v", notify)
+	}
+	testWriteRequest(t, in, "ping", protocol.Ping, protocol.NewPingRequest())
+	if resp := testReadMessage(t, outScan); resp["id"] != "ping" {
+		t.Fatalf("expected a single list changed notification, got %v", resp)
+	}
+
+	testWriteRequest(t, in, 1, protocol.PromptsList, protocol.ListPromptsRequest{})
+	result, _ := testReadMessage(t, outScan)["result"].(map[string]interface{})
+	if prompts, _ := result["prompts"].([]interface{}); len(prompts) != 2 || result["nextCursor"] == nil {
+		t.Fatalf("expected a full first page with a next cursor, got %v", result)
+	}
+	testWriteRequest(t, in, 2, protocol.PromptsList, protocol.ListPromptsRequest{Cursor: protocol.Cursor(result["nextCursor"].(string))})
+	result, _ = testReadMessage(t, outScan)["result"].(map[string]interface{})
+	if prompts, _ := result["prompts"].([]interface{}); len(prompts) != 0 || result["nextCursor"] != nil {
+		t.Fatalf("expected an empty last page, got %v", result)
+	}
+}
+
+func TestToolListChangedCoalesced(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+	testServerInit(t, server, in, outScan)
+
+	handler := func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	}
+	for i := 0; i < 3; i++ {
+		server.RegisterTool(protocol.NewToolWithInputSchema(fmt.Sprintf("tool%d", i), "", protocol.InputSchema{Type: protocol.Object}), handler)
+	}
+	server.UnregisterTool("tool2")
+
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationToolsListChanged) {
+		t.Fatalf("expected tools list changed notification, got %v", notify)
+	}
+	testWriteRequest(t, in, "ping", protocol.Ping, protocol.NewPingRequest())
+	if resp := testReadMessage(t, outScan); resp["id"] != "ping" {
+		t.Fatalf("expected a single list changed notification, got %v", resp)
+	}
+}
+
+func TestListChangedWithoutCapability(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithCapabilities(protocol.ServerCapabilities{
+		Prompts: &protocol.PromptsCapability{},
+	}))
+	testServerInit(t, server, in, outScan)
+
+	server.RegisterPrompt(&protocol.Prompt{Name: "prompt"}, func(context.Context, *protocol.GetPromptRequest) (*protocol.GetPromptResult, error) {
+		return &protocol.GetPromptResult{}, nil
+	})
+	time.Sleep(2 * defaultListChangedDebounce)
+
+	testWriteRequest(t, in, "ping", protocol.Ping, protocol.NewPingRequest())
+	if resp := testReadMessage(t, outScan); resp["id"] != "ping" {
+		t.Fatalf("expected no list changed notification without the listChanged capability, got %v", resp)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestListToolsTruncation(t *testing.T) {
+	const (
+		total          = 50
+		maxMessageSize = 2000
+	)
+	server, in, outScan := newTestServer(t, WithMaxMessageSize(maxMessageSize))
+	for i := 0; i < total; i++ {
+		tool := protocol.NewToolWithInputSchema(fmt.Sprintf("tool_%02d", i), "a tool with a somewhat long description",
+			protocol.InputSchema{Type: protocol.Object})
+		server.RegisterTool(tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			return protocol.NewCallToolResult(nil, false), nil
+		})
+	}
+	testServerInit(t, server, in, outScan)
+
+	listTools := func(id int, cursor protocol.Cursor) *protocol.ListToolsResult {
+		testWriteRequest(t, in, id, protocol.ToolsList, protocol.ListToolsRequest{Cursor: cursor})
+		if !outScan.Scan() {
+			t.Fatalf("outScan: %+v", outScan.Err())
+		}
+		if size := len(outScan.Bytes()); size > maxMessageSize {
+			t.Fatalf("expected the response to fit %d bytes, got %d", maxMessageSize, size)
+		}
+		var resp struct {
+			Result protocol.ListToolsResult `json:"result"`
+		}
+		if err := json.Unmarshal(outScan.Bytes(), &resp); err != nil {
+			t.Fatalf("json Unmarshal: %+v", err)
+		}
+		return &resp.Result
+	}
+
+	// a non-paginating client gets the tools that fit, with a warning
+	result := listTools(1, "")
+	if len(result.Tools) == 0 || len(result.Tools) >= total {
+		t.Fatalf("expected the listing to be truncated, got %d tools", len(result.Tools))
+	}
+	if result.Meta[protocol.WarningKey] == nil || result.NextCursor == "" {
+		t.Fatalf("expected a truncation warning and a next cursor, got meta %v cursor %q", result.Meta, result.NextCursor)
+	}
+
+	// a paginating client gets all tools across pages
+	seen := make(map[string]struct{}, total)
+	for id := 2; ; id++ {
+		for _, tool := range result.Tools {
+			if _, ok := seen[tool.Name]; ok {
+				t.Fatalf("tool %s listed twice", tool.Name)
+			}
+			seen[tool.Name] = struct{}{}
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		result = listTools(id, result.NextCursor)
+	}
+	if len(seen) != total {
+		t.Fatalf("expected %d tools across pages, got %d", total, len(seen))
+	}
+	if result.Meta != nil {
+		t.Fatalf("expected the last page to fit without a warning, got %v", result.Meta)
+	}
+}
+
+func TestListToolsTruncationOversizedTool(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithMaxMessageSize(200))
+	tool := protocol.NewToolWithInputSchema("huge", strings.Repeat("a very long description ", 20),
+		protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+	testServerInit(t, server, in, outScan)
+
+	// an empty page would tell the client there are no tools
+	testWriteRequest(t, in, 1, protocol.ToolsList, protocol.ListToolsRequest{})
+	resp := testReadMessage(t, outScan)
+	errObj, ok := resp["error"].(map[string]interface{})
+	if !ok || errObj["code"] != float64(protocol.InternalError) {
+		t.Fatalf("expected an error for a tool exceeding the max message size, got %v", resp)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// StreamLogs returns a writer turning each line written to it into a notifications/message log notification
+// sent to the session of the request in ctx, e.g. to pipe a subprocess's output to the client in real time.
+// A partial line is buffered until its newline is written, Close sends a trailing partial line.
+func (server *Server) StreamLogs(ctx context.Context) io.WriteCloser {
+	return &logStream{ctx: ctx, server: server}
+}
+
+type logStream struct {
+	ctx    context.Context
+	server *Server
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *logStream) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buffered := len(w.buf)
+	w.buf = append(w.buf, p...)
+	sent := 0
+	for {
+		i := bytes.IndexByte(w.buf[sent:], '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimSuffix(w.buf[sent:sent+i], []byte{'\r'})
+		if err := w.send(string(line)); err != nil {
+			// only the bytes of p in the lines already sent count as written, the rest is left to the caller
+			n := sent - buffered
+			if n < 0 {
+				n = 0
+			}
+			w.buf = w.buf[sent : buffered+n]
+			return n, err
+		}
+		sent += i + 1
+	}
+	w.buf = w.buf[sent:]
+	return len(p), nil
+}
+
+func (w *logStream) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := string(w.buf)
+	w.buf = nil
+	return w.send(line)
+}
+
+func (w *logStream) send(line string) error {
+	sessionID, err := GetSessionIDFromCtx(w.ctx)
+	if err != nil {
+		return err
+	}
+	return w.server.sendMsgWithNotification(w.ctx, sessionID, protocol.NotificationLogMessage,
+		protocol.NewLogMessageNotification(protocol.LogInfo, line, nil))
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+// Logger is the structured logger of the server, every call takes a message followed by alternating keys and values,
+// e.g. Warn("send notification fail", "method", method, "error", err). A *slog.Logger implements it as is.
+type Logger interface {
+	Debug(msg string, keysAndValues ...any)
+	Info(msg string, keysAndValues ...any)
+	Warn(msg string, keysAndValues ...any)
+	Error(msg string, keysAndValues ...any)
+}
+
+// WithLogger sets the logger all internal logging of the server goes through, e.g. dispatch errors,
+// recovered panics and malformed messages. By default the server logs through the stdlib log package.
+func WithLogger(logger Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// NewPrintfLogger adapts a printf-style pkg.Logger, e.g. pkg.DebugLogger, to Logger,
+// the keys and values are appended to the message as key=value
+func NewPrintfLogger(logger pkg.Logger) Logger {
+	return &printfLogger{logger: logger}
+}
+
+type printfLogger struct {
+	logger pkg.Logger
+}
+
+func (l *printfLogger) Debug(msg string, keysAndValues ...any) {
+	l.logger.Debugf("%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+func (l *printfLogger) Info(msg string, keysAndValues ...any) {
+	l.logger.Infof("%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+func (l *printfLogger) Warn(msg string, keysAndValues ...any) {
+	l.logger.Warnf("%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+func (l *printfLogger) Error(msg string, keysAndValues ...any) {
+	l.logger.Errorf("%s", formatKeysAndValues(msg, keysAndValues))
+}
+
+// formatKeysAndValues renders msg followed by the pairs as key=value, a value without a key is rendered
+// as !BADKEY=value like log/slog does
+func formatKeysAndValues(msg string, keysAndValues []any) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fmt.Fprintf(&b, " !BADKEY=%+v", keysAndValues[i])
+			break
+		}
+		fmt.Fprintf(&b, " %v=%+v", keysAndValues[i], keysAndValues[i+1])
+	}
+	return b.String()
+}
+
+// sessionLogger adapts the Logger of the server to the printf-style logger of the session manager
+type sessionLogger struct {
+	logger Logger
+}
+
+func (l *sessionLogger) Debugf(format string, a ...any) {
+	l.logger.Debug(fmt.Sprintf(format, a...))
+}
+
+func (l *sessionLogger) Infof(format string, a ...any) {
+	l.logger.Info(fmt.Sprintf(format, a...))
+}
+
+func (l *sessionLogger) Warnf(format string, a ...any) {
+	l.logger.Warn(fmt.Sprintf(format, a...))
+}
+
+func (l *sessionLogger) Errorf(format string, a ...any) {
+	l.logger.Error(fmt.Sprintf(format, a...))
+}
+
+// recoverPanic recovers a panic of a goroutine of the server and logs it, it must be deferred directly
+func (server *Server) recoverPanic() {
+	if r := recover(); r != nil {
+		server.logger.Error("panic recovered", "panic", r, "stack", string(debug.Stack()))
+	}
+}
@@ -0,0 +1,11 @@
+//go:build go1.21
+
+package server
+
+import "log/slog"
+
+// NewSlogLogger returns a Logger logging through logger, the keys and values become attributes of the record.
+// A *slog.Logger can also be passed to WithLogger as is.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return logger
+}
@@ -0,0 +1,20 @@
+//go:build go1.21
+
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	logger.Warn("tool is already registered, replacing it", "tool", "current_time")
+	if got := buf.String(); !strings.Contains(got, `level=WARN msg="tool is already registered, replacing it" tool=current_time`) {
+		t.Fatalf("unexpected record %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+type logEntry struct {
+	msg           string
+	keysAndValues map[string]any
+}
+
+type captureLogger struct {
+	errors chan logEntry
+}
+
+func (l *captureLogger) Debug(string, ...any) {}
+
+func (l *captureLogger) Info(string, ...any) {}
+
+func (l *captureLogger) Warn(string, ...any) {}
+
+func (l *captureLogger) Error(msg string, keysAndValues ...any) {
+	entry := logEntry{msg: msg, keysAndValues: map[string]any{}}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		entry.keysAndValues[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.errors <- entry
+}
+
+var _ Logger = (*captureLogger)(nil)
+
+func TestPanicLoggedThroughLogger(t *testing.T) {
+	logger := &captureLogger{errors: make(chan logEntry, 16)}
+	server, in, outScan := newTestServer(t, WithLogger(logger))
+
+	testTool := protocol.NewToolWithInputSchema("panic_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		panic("boom")
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case entry := <-logger.errors:
+			if entry.keysAndValues["panic"] == "boom" {
+				if entry.keysAndValues["method"] != protocol.ToolsCall || entry.keysAndValues["stack"] == nil {
+					t.Fatalf("expected the method and stack of the panic as key/values, got %v", entry.keysAndValues)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected the panic to be logged through the configured logger")
+		}
+	}
+}
+
+func TestPrintfLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewPrintfLogger(&testPrintfLogger{log.New(&buf, "", 0)})
+
+	logger.Warn("send notification fail", "method", "notifications/message", "error", "closed", "dangling")
+	if got := strings.TrimSpace(buf.String()); got != "send notification fail method=notifications/message error=closed !BADKEY=dangling" {
+		t.Fatalf("unexpected log line %q", got)
+	}
+}
+
+type testPrintfLogger struct {
+	*log.Logger
+}
+
+func (l *testPrintfLogger) Debugf(format string, a ...any) { l.Printf(format, a...) }
+
+func (l *testPrintfLogger) Infof(format string, a ...any) { l.Printf(format, a...) }
+
+func (l *testPrintfLogger) Warnf(format string, a ...any) { l.Printf(format, a...) }
+
+func (l *testPrintfLogger) Errorf(format string, a ...any) { l.Printf(format, a...) }
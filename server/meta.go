@@ -0,0 +1,39 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// parseMeta returns the _meta object of the request params, nil if the request carries none
+func parseMeta(rawParams json.RawMessage) map[string]interface{} {
+	r := gjson.GetBytes(rawParams, "_meta")
+	if !r.IsObject() {
+		return nil
+	}
+	meta, _ := r.Value().(map[string]interface{})
+	return meta
+}
+
+// deadlineFromMeta returns the earliest deadline hinted by the _meta deadline and timeout keys
+func deadlineFromMeta(meta map[string]interface{}, now time.Time) (time.Time, bool) {
+	var (
+		deadline time.Time
+		ok       bool
+	)
+	if s, isString := meta[protocol.DeadlineKey].(string); isString {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			deadline, ok = t, true
+		}
+	}
+	if ms, isNumber := meta[protocol.TimeoutKey].(float64); isNumber && ms > 0 {
+		if t := now.Add(time.Duration(ms * float64(time.Millisecond))); !ok || t.Before(deadline) {
+			deadline, ok = t, true
+		}
+	}
+	return deadline, ok
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestRequestMetaPropagation(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	type observed struct {
+		deadline    time.Time
+		hasDeadline bool
+		meta        map[string]interface{}
+		hasMeta     bool
+	}
+	observedCh := make(chan observed, 1)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		deadline, hasDeadline := ctx.Deadline()
+		meta, hasMeta := MetaFromContext(ctx)
+		observedCh <- observed{deadline: deadline, hasDeadline: hasDeadline, meta: meta, hasMeta: hasMeta}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	request := protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{})
+	request.Meta = map[string]interface{}{
+		protocol.TimeoutKey:       500,
+		protocol.DeadlineKey:      time.Now().Add(time.Hour).Format(time.RFC3339Nano),
+		protocol.ProgressTokenKey: "token",
+		"traceId":                 "abc",
+	}
+	sent := time.Now()
+	testWriteRequest(t, in, 1, protocol.ToolsCall, request)
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("unexpected error %v", resp["error"])
+	}
+
+	got := <-observedCh
+	if !got.hasDeadline || got.deadline.Before(sent) || got.deadline.After(time.Now().Add(500*time.Millisecond)) {
+		t.Fatalf("expected the timeout hint to bound the handler deadline, got %v (has=%v)", got.deadline, got.hasDeadline)
+	}
+	if !got.hasMeta || got.meta["traceId"] != "abc" || got.meta[protocol.ProgressTokenKey] != "token" {
+		t.Fatalf("unexpected meta %v", got.meta)
+	}
+
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	testReadMessage(t, outScan)
+	if got = <-observedCh; got.hasDeadline || got.hasMeta {
+		t.Fatalf("expected no deadline or meta without _meta, got %+v", got)
+	}
+}
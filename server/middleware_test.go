@@ -0,0 +1,365 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestResourceAndPromptMiddleware(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	var calls []string
+	resourceMiddleware := func(name string) ResourceMiddleware {
+		return func(next ResourceHandlerFunc) ResourceHandlerFunc {
+			return func(ctx context.Context, req *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+				calls = append(calls, name)
+				return next(ctx, req)
+			}
+		}
+	}
+	promptMiddleware := func(name string) PromptMiddleware {
+		return func(next PromptHandlerFunc) PromptHandlerFunc {
+			return func(ctx context.Context, req *protocol.GetPromptRequest) (*protocol.GetPromptResult, error) {
+				calls = append(calls, name)
+				return next(ctx, req)
+			}
+		}
+	}
+
+	server.UseResourceMiddleware(resourceMiddleware("resource_global_1"), resourceMiddleware("resource_global_2"))
+	server.UsePromptMiddleware(promptMiddleware("prompt_global"))
+
+	server.RegisterResource(&protocol.Resource{URI: "file:///test.txt", Name: "test.txt"},
+		func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+			calls = append(calls, "resource_handler")
+			return protocol.NewReadResourceResult(nil), nil
+		}, resourceMiddleware("resource_local"))
+	server.RegisterPrompt(&protocol.Prompt{Name: "test_prompt"},
+		func(context.Context, *protocol.GetPromptRequest) (*protocol.GetPromptResult, error) {
+			calls = append(calls, "prompt_handler")
+			return protocol.NewGetPromptResult(nil, ""), nil
+		}, promptMiddleware("prompt_local"))
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "file:///test.txt"})
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("read resource: unexpected error %v", resp["error"])
+	}
+
+	testWriteRequest(t, in, 2, protocol.PromptsGet, protocol.GetPromptRequest{Name: "test_prompt"})
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("get prompt: unexpected error %v", resp["error"])
+	}
+
+	want := []string{
+		"resource_global_1", "resource_global_2", "resource_local", "resource_handler",
+		"prompt_global", "prompt_local", "prompt_handler",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("middleware order not as expected.\ngot  = %v\nwant = %v", calls, want)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	server.Use(func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			if req.Name == "panic_in_middleware" {
+				panic("middleware boom")
+			}
+			return next(ctx, req)
+		}
+	})
+
+	panicHandler := func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		panic("handler boom")
+	}
+	server.RegisterTool(protocol.NewToolWithInputSchema("panic_in_handler", "", protocol.InputSchema{Type: protocol.Object}), panicHandler)
+	server.RegisterTool(protocol.NewToolWithInputSchema("panic_in_middleware", "", protocol.InputSchema{Type: protocol.Object}), panicHandler)
+
+	testServerInit(t, server, in, outScan)
+
+	for i, name := range []string{"panic_in_handler", "panic_in_middleware"} {
+		testWriteRequest(t, in, i, protocol.ToolsCall, protocol.CallToolRequest{Name: name})
+		resp := testReadMessage(t, outScan)
+
+		errObj, ok := resp["error"].(map[string]interface{})
+		if !ok || resp["id"] != float64(i) {
+			t.Fatalf("%s: expected error response, got %v", name, resp)
+		}
+		if errObj["code"] != float64(protocol.InternalError) || errObj["message"] != "internal server error" {
+			t.Fatalf("%s: unexpected error %v", name, errObj)
+		}
+	}
+
+	// the connection keeps working after a panic
+	testWriteRequest(t, in, "ping", protocol.Ping, protocol.NewPingRequest())
+	if resp := testReadMessage(t, outScan); resp["id"] != "ping" || resp["error"] != nil {
+		t.Fatalf("ping after panic: unexpected response %v", resp)
+	}
+}
+
+func TestPanicPolicy(t *testing.T) {
+	var reported atomic.Value
+	server, in, outScan := newTestServer(t, WithPanicPolicy(PanicPolicy{
+		Mode: PanicModeToolErrorResult,
+		Report: func(recovered interface{}, stack []byte) {
+			if len(stack) == 0 {
+				t.Errorf("expected the stack of the panic")
+			}
+			reported.Store(recovered)
+		},
+	}))
+
+	server.RegisterTool(protocol.NewToolWithInputSchema("panic", "", protocol.InputSchema{Type: protocol.Object}),
+		func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			panic("handler boom")
+		})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.CallToolRequest{Name: "panic"})
+	resp := testReadMessage(t, outScan)
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok || result["isError"] != true {
+		t.Fatalf("expected isError tool result, got %v", resp)
+	}
+	content := result["content"].([]interface{})[0].(map[string]interface{})
+	if content["text"] != panicToolResultMessage {
+		t.Fatalf("unexpected content %v", content)
+	}
+	if reported.Load() != "handler boom" {
+		t.Fatalf("expected the panic to be reported, got %v", reported.Load())
+	}
+}
+
+func TestRateLimitMiddlewareWithKeyFunc(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	}, RateLimitMiddlewareWithOptions(RateLimitOptions{
+		RequestsPerSecond: 0.001,
+		Burst:             2,
+		KeyFunc: func(_ context.Context, req *protocol.CallToolRequest) string {
+			return fmt.Sprint(req.Arguments["token"])
+		},
+	}))
+
+	testServerInit(t, server, in, outScan)
+
+	call := func(id int, token string) map[string]interface{} {
+		testWriteRequest(t, in, id, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{"token": token}))
+		return testReadMessage(t, outScan)
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := call(i, "a"); resp["error"] != nil {
+			t.Fatalf("call %d: unexpected error %v", i, resp["error"])
+		}
+	}
+
+	resp := call(2, "a")
+	errObj, _ := resp["error"].(map[string]interface{})
+	if errObj["code"] != float64(protocol.RateLimitExceeded) {
+		t.Fatalf("expected rate limit error, got %v", resp)
+	}
+	if data, _ := errObj["data"].(map[string]interface{}); data["retryable"] != true {
+		t.Fatalf("expected retryable error data, got %v", errObj["data"])
+	}
+
+	// another key has its own bucket
+	if resp = call(3, "b"); resp["error"] != nil {
+		t.Fatalf("call with another key: unexpected error %v", resp["error"])
+	}
+}
+
+func TestMiddlewareWithTimeout(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	server.Use(MiddlewareWithTimeout("slow_auth", 50*time.Millisecond, func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			if req.Name == "slow" {
+				select {
+				case <-time.After(time.Minute):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			return next(ctx, req)
+		}
+	}))
+
+	handler := func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		// the wrapped handler may take longer than the middleware timeout
+		time.Sleep(100 * time.Millisecond)
+		return protocol.NewCallToolResult(nil, false), nil
+	}
+	server.RegisterTool(protocol.NewToolWithInputSchema("slow", "", protocol.InputSchema{Type: protocol.Object}), handler)
+	server.RegisterTool(protocol.NewToolWithInputSchema("fast", "", protocol.InputSchema{Type: protocol.Object}), handler)
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest("slow", map[string]interface{}{}))
+	resp := testReadMessage(t, outScan)
+	errObj, _ := resp["error"].(map[string]interface{})
+	if msg, _ := errObj["message"].(string); !strings.Contains(msg, pkg.ErrMiddlewareTimeout.Error()) || !strings.Contains(msg, "slow_auth") {
+		t.Fatalf("expected middleware timeout error naming slow_auth, got %v", resp)
+	}
+
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest("fast", map[string]interface{}{}))
+	if resp = testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("unexpected error %v", resp["error"])
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	var trace []string
+	record := func(name string) ToolMiddleware {
+		return func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+				trace = append(trace, name+">")
+				defer func() { trace = append(trace, "<"+name) }()
+				return next(ctx, req)
+			}
+		}
+	}
+
+	// successive calls accumulate
+	server.Use(record("recovery"))
+	server.Use(record("auth"), record("metrics"))
+
+	tool := protocol.NewToolWithInputSchema("ordered_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		trace = append(trace, "handler")
+		return protocol.NewCallToolResult(nil, false), nil
+	}, record("tool1"), record("tool2"))
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(tool.Name, map[string]interface{}{}))
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("unexpected error response %v", resp)
+	}
+
+	want := []string{
+		"recovery>", "auth>", "metrics>", "tool1>", "tool2>",
+		"handler",
+		"<tool2", "<tool1", "<metrics", "<auth", "<recovery",
+	}
+	if !reflect.DeepEqual(trace, want) {
+		t.Fatalf("unexpected middleware order\n got: %v\nwant: %v", trace, want)
+	}
+}
+
+func TestToolFromContext(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	// authentication is skipped for read-only tools
+	server.Use(func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			tool := ToolFromContext(ctx)
+			if tool == nil {
+				return nil, fmt.Errorf("no tool in context")
+			}
+			if tool.Annotations != nil && tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint {
+				return next(ctx, req)
+			}
+			if req.Arguments["token"] != "secret" {
+				return nil, protocol.NewError(protocol.InvalidRequest, "unauthorized", nil)
+			}
+			return next(ctx, req)
+		}
+	})
+
+	handler := func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	}
+	server.RegisterTool(protocol.NewToolWithInputSchema("read_tool", "", protocol.InputSchema{Type: protocol.Object}).SetReadOnlyHint(true), handler)
+	server.RegisterTool(protocol.NewToolWithInputSchema("write_tool", "", protocol.InputSchema{Type: protocol.Object}), handler)
+
+	testServerInit(t, server, in, outScan)
+
+	tests := []struct {
+		tool    string
+		args    map[string]interface{}
+		wantErr bool
+	}{
+		{tool: "read_tool", args: map[string]interface{}{}},
+		{tool: "write_tool", args: map[string]interface{}{}, wantErr: true},
+		{tool: "write_tool", args: map[string]interface{}{"token": "secret"}},
+	}
+	for i, tt := range tests {
+		testWriteRequest(t, in, i, protocol.ToolsCall, protocol.NewCallToolRequest(tt.tool, tt.args))
+		resp := testReadMessage(t, outScan)
+		if gotErr := resp["error"] != nil; gotErr != tt.wantErr {
+			t.Fatalf("%s with %v: unexpected response %v", tt.tool, tt.args, resp)
+		}
+	}
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+
+	server, in, outScan := newTestServer(t)
+
+	var calls int32
+	weatherTool := protocol.NewToolWithInputSchema("weather", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(weatherTool, func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if req.Arguments["city"] == "Atlantis" {
+			return protocol.NewErrorResult("city %v not found", req.Arguments["city"]), nil
+		}
+		return protocol.NewResultBuilder().AddText(fmt.Sprint(n)).Build(), nil
+	}, CacheMiddleware(CacheOptions{TTL: ttl, Size: 2}))
+
+	testServerInit(t, server, in, outScan)
+
+	id := 0
+	call := func(arguments string) string {
+		id++
+		params := json.RawMessage(fmt.Sprintf(`{"name":"weather","arguments":%s}`, arguments))
+		testWriteRequest(t, in, id, protocol.ToolsCall, params)
+		resp := testReadMessage(t, outScan)
+		content := resp["result"].(map[string]interface{})["content"].([]interface{})
+		return content[0].(map[string]interface{})["text"].(string)
+	}
+
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+	}{
+		{name: "first call", arguments: `{"city":"Paris","days":3}`, want: "1"},
+		{name: "argument order doesn't matter", arguments: `{"days":3,"city":"Paris"}`, want: "1"},
+		{name: "other arguments", arguments: `{"city":"Tokyo","days":3}`, want: "2"},
+		{name: "error results aren't cached", arguments: `{"city":"Atlantis"}`, want: "city Atlantis not found"},
+		{name: "error results aren't cached again", arguments: `{"city":"Atlantis"}`, want: "city Atlantis not found"},
+		{name: "least recently used is evicted", arguments: `{"city":"Oslo"}`, want: "5"},
+		{name: "evicted result is computed again", arguments: `{"city":"Paris","days":3}`, want: "6"},
+	}
+	for _, tt := range tests {
+		if got := call(tt.arguments); got != tt.want {
+			t.Fatalf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	time.Sleep(ttl)
+	if got := call(`{"city":"Paris","days":3}`); got != "7" {
+		t.Fatalf("expired result: got %s, want 7", got)
+	}
+}
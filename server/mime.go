@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+const defaultMimeType = "application/octet-stream"
+
+// detectMimeTypes sets the MIME type of the contents of result that have none
+func detectMimeTypes(result *protocol.ReadResourceResult) {
+	if result == nil {
+		return
+	}
+	for _, contents := range result.Contents {
+		switch c := contents.(type) {
+		case *protocol.TextResourceContents:
+			if c.MimeType == "" {
+				c.MimeType = detectMimeType(c.URI, func() []byte { return []byte(c.Text) })
+			}
+		case *protocol.BlobResourceContents:
+			if c.MimeType == "" {
+				c.MimeType = detectMimeType(c.URI, func() []byte {
+					data, err := c.Data()
+					if err != nil {
+						return nil
+					}
+					return data
+				})
+			}
+		}
+	}
+}
+
+// detectMimeType detects the MIME type from the extension of uri, data is only sniffed if the extension is unknown
+func detectMimeType(uri string, data func() []byte) string {
+	p := uri
+	if u, err := url.Parse(uri); err == nil && u.Path != "" {
+		p = u.Path
+	}
+	if mimeType, ok := protocol.MimeTypeByExtension(p); ok {
+		return mimeType
+	}
+
+	b := data()
+	if len(b) == 0 {
+		return defaultMimeType
+	}
+	// DetectContentType falls back to application/octet-stream as well when inconclusive
+	return http.DetectContentType(b)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestWithMimeDetection(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	contents := map[string]protocol.ResourceContents{
+		"file:///notes.md":     protocol.NewTextResourceContents("file:///notes.md", "", "# notes"),
+		"file:///logo":         protocol.NewBlobResourceContents("file:///logo", "", png),
+		"file:///readme":       protocol.NewTextResourceContents("file:///readme", "", "hello"),
+		"file:///data.bin":     protocol.NewBlobResourceContents("file:///data.bin", "", []byte{0x00, 0x01, 0x02}),
+		"file:///explicit.txt": protocol.NewTextResourceContents("file:///explicit.txt", "text/x-custom", "custom"),
+	}
+	want := map[string]string{
+		"file:///notes.md":     "text/markdown",
+		"file:///logo":         "image/png",
+		"file:///readme":       "text/plain; charset=utf-8",
+		"file:///data.bin":     "application/octet-stream",
+		"file:///explicit.txt": "text/x-custom",
+	}
+
+	setup := func(s *Server) {
+		for uri, c := range contents {
+			c := c
+			s.RegisterResource(&protocol.Resource{Name: uri, URI: uri},
+				func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+					return protocol.NewReadResourceResult([]protocol.ResourceContents{c}), nil
+				})
+		}
+	}
+	_, mcpClient, _ := newTestServerAndClient(t, []Option{WithMimeDetection()}, nil, setup)
+
+	for uri, mimeType := range want {
+		result, err := mcpClient.ReadResource(context.Background(), protocol.NewReadResourceRequest(uri))
+		if err != nil {
+			t.Fatalf("ReadResource %s: %+v", uri, err)
+		}
+		if got := result.Contents[0].GetMimeType(); got != mimeType {
+			t.Errorf("expected MIME type %q for %s, got %q", mimeType, uri, got)
+		}
+	}
+}
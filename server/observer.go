@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// Observer is notified around every request and notification the server dispatches,
+// giving metrics and tracing backends a single integration point
+type Observer interface {
+	OnRequestStart(ctx context.Context, method protocol.Method, id protocol.RequestID)
+	// OnRequestEnd is called once the request is handled, err carries the JSON-RPC error returned to the client if any
+	OnRequestEnd(ctx context.Context, method protocol.Method, id protocol.RequestID, err error, duration time.Duration)
+	OnNotification(ctx context.Context, method protocol.Method)
+	// ObserveToolResult is called once per tool call with a structured record of the call, e.g. for analytics
+	ObserveToolResult(ctx context.Context, record ToolCallRecord)
+}
+
+// ToolCallRecord describes a tool call and its result
+type ToolCallRecord struct {
+	ToolName string
+	// ArgumentCount is the number of top level arguments
+	ArgumentCount int
+	// ArgumentBytes is the size of the JSON encoded arguments
+	ArgumentBytes int
+	// ContentCount is the number of content blocks of the result
+	ContentCount int
+	// ContentBytes is the size of the JSON encoded content blocks of the result
+	ContentBytes int
+	// ErrorClass classifies the failure of the call, empty on success. See the ToolErrorClass constants.
+	ErrorClass string
+	Err        error
+	Duration   time.Duration
+}
+
+const (
+	ToolErrorClassToolError   = "tool_error" // the tool returned a result with IsError set
+	ToolErrorClassCanceled    = "canceled"
+	ToolErrorClassTimeout     = "timeout"
+	ToolErrorClassRateLimited = "rate_limited"
+	ToolErrorClassInternal    = "internal"
+)
+
+func newToolCallRecord(req *protocol.CallToolRequest, result *protocol.CallToolResult, err error, duration time.Duration) ToolCallRecord {
+	record := ToolCallRecord{
+		ToolName:      req.Name,
+		ArgumentCount: len(req.Arguments),
+		ArgumentBytes: len(req.RawArguments),
+		Err:           err,
+		Duration:      duration,
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		record.ErrorClass = ToolErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, pkg.ErrMiddlewareTimeout):
+		record.ErrorClass = ToolErrorClassTimeout
+	case errors.Is(err, pkg.ErrRateLimitExceeded):
+		record.ErrorClass = ToolErrorClassRateLimited
+	default:
+		record.ErrorClass = ToolErrorClassInternal
+	}
+
+	if result != nil {
+		if result.IsError && record.ErrorClass == "" {
+			record.ErrorClass = ToolErrorClassToolError
+		}
+		record.ContentCount = len(result.Content)
+		for _, content := range result.Content {
+			if b, e := json.Marshal(content); e == nil {
+				record.ContentBytes += len(b)
+			}
+		}
+	}
+	return record
+}
+
+// BaseObserver implements Observer with no-ops, embed it to only override the hooks of interest
+type BaseObserver struct{}
+
+func (BaseObserver) OnRequestStart(context.Context, protocol.Method, protocol.RequestID) {}
+
+func (BaseObserver) OnRequestEnd(context.Context, protocol.Method, protocol.RequestID, error, time.Duration) {
+}
+
+func (BaseObserver) OnNotification(context.Context, protocol.Method) {}
+
+func (BaseObserver) ObserveToolResult(context.Context, ToolCallRecord) {}
+
+// WithObserver adds an observer, multiple observers are invoked in the order they were added
+func WithObserver(o Observer) Option {
+	return func(s *Server) {
+		s.observers = append(s.observers, o)
+	}
+}
+
+// observers fans out every hook to all registered observers
+type observers []Observer
+
+func (os observers) OnRequestStart(ctx context.Context, method protocol.Method, id protocol.RequestID) {
+	for _, o := range os {
+		o.OnRequestStart(ctx, method, id)
+	}
+}
+
+func (os observers) OnRequestEnd(ctx context.Context, method protocol.Method, id protocol.RequestID, err error, duration time.Duration) {
+	for _, o := range os {
+		o.OnRequestEnd(ctx, method, id, err, duration)
+	}
+}
+
+func (os observers) OnNotification(ctx context.Context, method protocol.Method) {
+	for _, o := range os {
+		o.OnNotification(ctx, method)
+	}
+}
+
+func (os observers) ObserveToolResult(ctx context.Context, record ToolCallRecord) {
+	for _, o := range os {
+		o.ObserveToolResult(ctx, record)
+	}
+}
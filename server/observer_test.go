@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+type recordObserver struct {
+	BaseObserver
+
+	mu            sync.Mutex
+	started       []protocol.Method
+	ended         []protocol.Method
+	errs          []error
+	notifications []protocol.Method
+	toolRecords   []ToolCallRecord
+}
+
+func (o *recordObserver) OnRequestStart(_ context.Context, method protocol.Method, _ protocol.RequestID) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.started = append(o.started, method)
+}
+
+func (o *recordObserver) OnRequestEnd(_ context.Context, method protocol.Method, _ protocol.RequestID, err error, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ended = append(o.ended, method)
+	o.errs = append(o.errs, err)
+}
+
+func (o *recordObserver) OnNotification(_ context.Context, method protocol.Method) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.notifications = append(o.notifications, method)
+}
+
+func (o *recordObserver) ObserveToolResult(_ context.Context, record ToolCallRecord) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.toolRecords = append(o.toolRecords, record)
+}
+
+func TestObserver(t *testing.T) {
+	metrics, tracing := &recordObserver{}, &recordObserver{}
+	server, in, outScan := newTestServer(t, WithObserver(metrics), WithObserver(tracing))
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsList, protocol.ListToolsRequest{})
+	testReadMessage(t, outScan)
+	testWriteRequest(t, in, 2, "unknown/method", nil)
+	testReadMessage(t, outScan)
+
+	for _, o := range []*recordObserver{metrics, tracing} {
+		o.mu.Lock()
+		wantMethods := []protocol.Method{protocol.Initialize, protocol.ToolsList, "unknown/method"}
+		if len(o.started) != len(wantMethods) || len(o.ended) != len(wantMethods) {
+			t.Fatalf("expected %v to be observed, got start=%v end=%v", wantMethods, o.started, o.ended)
+		}
+		for i, method := range wantMethods {
+			if o.started[i] != method || o.ended[i] != method {
+				t.Fatalf("expected %v to be observed, got start=%v end=%v", wantMethods, o.started, o.ended)
+			}
+		}
+		if o.errs[1] != nil || o.errs[2] == nil {
+			t.Fatalf("unexpected observed errors %v", o.errs)
+		}
+		if len(o.notifications) != 1 || o.notifications[0] != protocol.NotificationInitialized {
+			t.Fatalf("expected initialized notification to be observed, got %v", o.notifications)
+		}
+		o.mu.Unlock()
+	}
+}
+
+func TestObserveToolResult(t *testing.T) {
+	observer := &recordObserver{}
+	server, in, outScan := newTestServer(t, WithObserver(observer))
+
+	contents := []protocol.Content{
+		&protocol.TextContent{Type: "text", Text: "hello"},
+		&protocol.TextContent{Type: "text", Text: "world"},
+	}
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(contents, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{"a": 1, "b": "x"}))
+	testReadMessage(t, outScan)
+
+	wantBytes := 0
+	for _, content := range contents {
+		b, err := json.Marshal(content)
+		if err != nil {
+			t.Fatalf("json Marshal: %+v", err)
+		}
+		wantBytes += len(b)
+	}
+
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	if len(observer.toolRecords) != 1 {
+		t.Fatalf("expected 1 tool record, got %v", observer.toolRecords)
+	}
+	record := observer.toolRecords[0]
+	if record.ToolName != testTool.Name || record.ArgumentCount != 2 || record.ContentCount != 2 ||
+		record.ContentBytes != wantBytes || record.ErrorClass != "" {
+		t.Fatalf("unexpected tool record %+v, want content bytes %d", record, wantBytes)
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"runtime/debug"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// PanicMode selects what a panic raised while handling a request becomes
+type PanicMode int
+
+const (
+	// PanicModeErrorResponse replies with a JSON-RPC internal error with a sanitized message, the default
+	PanicModeErrorResponse PanicMode = iota
+	// PanicModeToolErrorResult answers a tools/call with a result with isError set and a generic message,
+	// so that the model learns the tool failed, other requests are answered with an internal error
+	PanicModeToolErrorResult
+	// PanicModeCrash re-panics and crashes the process, e.g. in development so that panics aren't overlooked
+	PanicModeCrash
+)
+
+// PanicPolicy configures the handling of panics raised while handling a request, including panics in user
+// middlewares, see WithPanicPolicy
+type PanicPolicy struct {
+	Mode PanicMode
+	// Report is called with the recovered value and the stack of every panic, e.g. to report it to Sentry,
+	// in PanicModeCrash before the process crashes. The panic is logged in any case.
+	Report func(recovered interface{}, stack []byte)
+}
+
+// WithPanicPolicy sets what a panic raised while handling a request becomes, by default it is logged and answered
+// with an internal error response, so that neither the request is left unanswered nor the process crashes
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(s *Server) {
+		s.panicPolicy = policy
+	}
+}
+
+const panicToolResultMessage = "the tool failed with an internal error"
+
+// handlePanic logs and reports the panic r recovered while handling request and returns the response to send,
+// in PanicModeCrash it panics again
+func (server *Server) handlePanic(request *protocol.JSONRPCRequest, r interface{}) *protocol.JSONRPCResponse {
+	stack := debug.Stack()
+	server.logger.Error("panic while handling request", "method", request.Method, "id", request.ID,
+		"panic", r, "stack", string(stack))
+	if report := server.panicPolicy.Report; report != nil {
+		func() {
+			// a failing reporter must not turn the recovered panic into a crash
+			defer server.recoverPanic()
+			report(r, stack)
+		}()
+	}
+
+	switch server.panicPolicy.Mode {
+	case PanicModeCrash:
+		panic(r)
+	case PanicModeToolErrorResult:
+		if request.Method == protocol.ToolsCall {
+			return protocol.NewJSONRPCSuccessResponse(request.ID, protocol.NewCallToolResult(
+				[]protocol.Content{&protocol.TextContent{Type: "text", Text: panicToolResultMessage}}, true))
+		}
+	}
+	return protocol.NewJSONRPCErrorResponse(request.ID, protocol.InternalError, "internal server error")
+}
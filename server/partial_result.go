@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// PartialResultWriter sends pieces of the output of a tool call, e.g. the lines of a build log, before the handler
+// returns its final result. They reach the client in order and ahead of the final result.
+//
+// On streaming transports (SSE, streamable HTTP and WebSocket) every piece is sent as a
+// notifications/x-partial_result notification carrying the content. On stdio, which is treated as plain
+// request/response, pieces degrade to progress notifications whose message describes the content, so they
+// are only delivered if the client asked for progress with a progress token.
+type PartialResultWriter struct {
+	server    *Server
+	ctx       context.Context
+	requestID protocol.RequestID
+	sequence  int32
+}
+
+// Send sends content as the next partial result
+func (w *PartialResultWriter) Send(content protocol.Content) error {
+	sequence := atomic.AddInt32(&w.sequence, 1)
+
+	if w.server.streaming {
+		return w.server.sendMsgWithNotification(w.ctx, "", protocol.NotificationToolPartialResult,
+			protocol.NewPartialToolResultNotification(w.requestID, int(sequence), content))
+	}
+
+	message := fmt.Sprintf("[%s]", content.GetType())
+	if text, ok := content.(*protocol.TextContent); ok {
+		message = text.Text
+	}
+	return w.server.SendProgressNotification(w.ctx, &protocol.ProgressNotification{Progress: float64(sequence), Message: message})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+// streamingTransport is a user transport wrapping another one that opts in to streaming
+type streamingTransport struct {
+	transport.ServerTransport
+}
+
+func (streamingTransport) SupportsStreaming() bool {
+	return true
+}
+
+func TestPartialResultsStreamingTransport(t *testing.T) {
+	server, err := NewServer(streamingTransport{transport.NewMockServerTransport(io.NopCloser(strings.NewReader("")), io.Discard)})
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	if !server.streaming {
+		t.Fatal("expected a transport implementing StreamingTransport to stream partial results")
+	}
+}
+
+func TestPartialResults(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		server, in, outScan := newTestServer(t)
+		// the mock transport is stdio-like, streaming is forced to cover both paths
+		server.streaming = streaming
+
+		buildTool := protocol.NewToolWithInputSchema("build", "", protocol.InputSchema{Type: protocol.Object})
+		server.RegisterTool(buildTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			writer := PartialResultWriterFromContext(ctx)
+			for _, line := range []string{"compiling", "linking"} {
+				if err := writer.Send(&protocol.TextContent{Type: "text", Text: line}); err != nil {
+					return nil, err
+				}
+			}
+			return protocol.NewResultBuilder().AddText("build succeeded").Build(), nil
+		})
+
+		testServerInit(t, server, in, outScan)
+
+		request := protocol.NewCallToolRequest(buildTool.Name, map[string]interface{}{})
+		request.Meta = map[string]interface{}{protocol.ProgressTokenKey: "build-1"}
+		testWriteRequest(t, in, 1, protocol.ToolsCall, request)
+
+		for i, want := range []string{"compiling", "linking"} {
+			msg := testReadMessage(t, outScan)
+			raw, err := json.Marshal(msg["params"])
+			if err != nil {
+				t.Fatalf("json Marshal: %+v", err)
+			}
+
+			if !streaming {
+				var progress protocol.ProgressNotification
+				if err = json.Unmarshal(raw, &progress); err != nil || msg["method"] != string(protocol.NotificationProgress) {
+					t.Fatalf("expected a progress notification, got %v", msg)
+				}
+				if progress.Message != want || progress.Progress != float64(i+1) || progress.ProgressToken != "build-1" {
+					t.Fatalf("unexpected progress %+v", progress)
+				}
+				continue
+			}
+
+			var partial protocol.PartialToolResultNotification
+			if err = json.Unmarshal(raw, &partial); err != nil || msg["method"] != string(protocol.NotificationToolPartialResult) {
+				t.Fatalf("expected a partial result notification, got %v", msg)
+			}
+			text, ok := partial.Content[0].(*protocol.TextContent)
+			if !ok || text.Text != want || partial.Sequence != i+1 || partial.RequestID != float64(1) {
+				t.Fatalf("unexpected partial result %+v", partial)
+			}
+		}
+
+		if resp := testReadMessage(t, outScan); resp["id"] != float64(1) || resp["result"] == nil {
+			t.Fatalf("expected the final result, got %v", resp)
+		}
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
+	"github.com/hhfgeg/go-mcp/transport"
 )
 
 func (server *Server) receive(ctx context.Context, sessionID string, msg []byte) (<-chan []byte, error) {
@@ -20,14 +25,26 @@ func (server *Server) receive(ctx context.Context, sessionID string, msg []byte)
 		return nil, pkg.ErrLackSession
 	}
 
+	// malformed input is answered with an error response instead of failing the transport,
+	// the id is null when it can't be determined
+	if !gjson.ValidBytes(msg) {
+		return server.replyWithError(protocol.NewJSONRPCErrorResponse(nil, protocol.ParseError, "parse error: invalid JSON"))
+	}
+	if !gjson.ParseBytes(msg).IsObject() {
+		return server.replyWithError(protocol.NewJSONRPCErrorResponse(nil, protocol.InvalidRequest, "invalid request: not a JSON object"))
+	}
+
 	if !gjson.GetBytes(msg, "id").Exists() {
+		if !gjson.GetBytes(msg, "method").Exists() {
+			return server.replyWithError(protocol.NewJSONRPCErrorResponse(nil, protocol.InvalidRequest, "invalid request: missing method"))
+		}
 		notify := &protocol.JSONRPCNotification{}
 		if err := pkg.JSONUnmarshal(msg, &notify); err != nil {
-			return nil, err
+			return server.replyWithError(protocol.NewJSONRPCErrorResponse(nil, protocol.InvalidRequest, fmt.Sprintf("invalid request: %v", err)))
 		}
+		server.observers.OnNotification(ctx, notify.Method)
 		if err := server.receiveNotify(sessionID, notify); err != nil {
-			notify.RawParams = nil // simplified log
-			server.logger.Errorf("receive notify:%+v error: %s", notify, err.Error())
+			server.logger.Error("receive notification fail", "method", notify.Method, "sessionID", sessionID, "error", err)
 			return nil, err
 		}
 		return nil, nil
@@ -35,14 +52,17 @@ func (server *Server) receive(ctx context.Context, sessionID string, msg []byte)
 
 	// case request or response
 	if !gjson.GetBytes(msg, "method").Exists() {
+		if !gjson.GetBytes(msg, "result").Exists() && !gjson.GetBytes(msg, "error").Exists() {
+			return server.replyWithError(protocol.NewJSONRPCErrorResponse(requestIDOf(msg), protocol.InvalidRequest,
+				"invalid request: missing method"))
+		}
 		resp := &protocol.JSONRPCResponse{}
 		if err := pkg.JSONUnmarshal(msg, &resp); err != nil {
 			return nil, err
 		}
 
 		if err := server.receiveResponse(sessionID, resp); err != nil {
-			resp.RawResult = nil // simplified log
-			server.logger.Errorf("receive response:%+v error: %s", resp, err.Error())
+			server.logger.Error("receive response fail", "id", resp.ID, "sessionID", sessionID, "error", err)
 			return nil, err
 		}
 		return nil, nil
@@ -50,30 +70,36 @@ func (server *Server) receive(ctx context.Context, sessionID string, msg []byte)
 
 	req := &protocol.JSONRPCRequest{}
 	if err := pkg.JSONUnmarshal(msg, &req); err != nil {
-		return nil, err
+		return server.replyWithError(protocol.NewJSONRPCErrorResponse(requestIDOf(msg), protocol.InvalidRequest,
+			fmt.Sprintf("invalid request: %v", err)))
 	}
 	if !req.IsValid() {
-		return nil, pkg.ErrRequestInvalid
+		return server.replyWithError(protocol.NewJSONRPCErrorResponse(requestIDOf(msg), protocol.InvalidRequest,
+			pkg.ErrRequestInvalid.Error()+`: jsonrpc must be "2.0" and method must be set`))
 	}
 
-	// if sessionID != "" && req.Method != protocol.Initialize && req.Method != protocol.Ping {
-	// 	if s, ok := server.sessionManager.GetSession(sessionID); !ok {
-	// 		return nil, pkg.ErrLackSession
-	// 	} else if !s.GetReady() {
-	// 		return nil, pkg.ErrSessionHasNotInitialized
-	// 	}
-	// }
+	if sessionID != "" && req.Method != protocol.Ping {
+		if s, ok := server.sessionManager.GetSession(sessionID); ok {
+			if err := server.checkInitPhase(s.GetInitPhase(), req.Method); err != nil {
+				return server.replyWithError(protocol.NewJSONRPCErrorResponse(req.ID, protocol.InvalidRequest, err.Error()))
+			}
+		}
+	}
 
 	server.inFlyRequest.Add(1)
 
 	if server.inShutdown.Load() {
 		server.inFlyRequest.Done()
-		return nil, errors.New("server already shutdown")
+		// in-flight requests are still draining, tell the client to retry elsewhere or later
+		return server.replyWithError(protocol.NewJSONRPCErrorResponseWithData(req.ID, protocol.ServerShuttingDown,
+			pkg.ErrServerShuttingDown.Error(), map[string]interface{}{"retryable": true}))
 	}
 
 	ch := make(chan []byte, 5)
 	go func(ctx context.Context) {
-		defer pkg.Recover()
+		if server.panicPolicy.Mode != PanicModeCrash {
+			defer server.recoverPanic()
+		}
 		defer server.inFlyRequest.Done()
 		defer close(ch)
 
@@ -83,28 +109,172 @@ func (server *Server) receive(ctx context.Context, sessionID string, msg []byte)
 			requestID := fmt.Sprint(req.ID)
 			s.GetClientReqID2cancelFunc().Set(requestID, cancel)
 			defer s.GetClientReqID2cancelFunc().Remove(requestID)
+
+			if req.Method != protocol.Ping {
+				release, err := s.AcquireHandlerSlot(ctx, server.maxConcurrencyPerSession)
+				if err != nil {
+					// the request was cancelled while queued
+					return
+				}
+				defer release()
+			}
 		}
 
-		if r := gjson.GetBytes(req.RawParams, fmt.Sprintf("_meta.%s", protocol.ProgressTokenKey)); r.Exists() {
-			ctx = setProgressTokenToCtx(ctx, r.Value())
+		if server.requestSlots != nil && req.Method != protocol.Ping {
+			select {
+			case server.requestSlots <- struct{}{}:
+				defer func() { <-server.requestSlots }()
+			case <-ctx.Done():
+				// the request was cancelled while queued
+				return
+			}
 		}
 
-		ctx = setSendChanToCtx(ctx, ch)
+		meta := parseMeta(req.RawParams)
+		if meta != nil {
+			ctx = setMetaToCtx(ctx, meta)
+			if deadline, ok := deadlineFromMeta(meta, time.Now()); ok {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, deadline)
+				defer cancel()
+			}
+		}
 
-		resp := server.receiveRequest(ctx, sessionID, req)
-		if errors.Is(ctx.Err(), context.Canceled) {
-			return
+		// a request from the client made on behalf of an earlier one, e.g. while serving sampling,
+		// carries the root call id of the chain, any other request is the root of its own chain
+		rootCallID, _ := meta[protocol.RootCallIDKey].(string)
+		if rootCallID == "" {
+			rootCallID = uuid.NewString()
 		}
-		message, err := json.Marshal(resp)
-		if err != nil {
-			server.logger.Errorf("receive json marshal response:%+v error: %s", resp, err.Error())
-			return
+		ctx = setRootCallIDToCtx(ctx, rootCallID)
+
+		if progressToken, ok := meta[protocol.ProgressTokenKey]; ok {
+			ctx = setProgressTokenToCtx(ctx, progressToken)
+		}
+
+		traceParent, traced := parseTraceParent(ctx, meta)
+		if traced && server.tracer == nil {
+			// the handler runs in a child span of the incoming trace
+			ctx = setTraceParentToCtx(ctx, traceParent.NewChild())
+		}
+
+		if sessionID != "" {
+			ctx = setSessionIDToCtx(ctx, sessionID)
+		}
+		ctx = setSendChanToCtx(ctx, sessionID, ch)
+		ctx = setServerToCtx(ctx, server)
+		ctx = setRequestIDToCtx(ctx, req.ID)
+
+		var span trace.Span
+		if server.tracer != nil {
+			ctx, span = server.startSpan(ctx, req, traceParent, traced)
 		}
-		ch <- message
+
+		start := time.Now()
+		server.observers.OnRequestStart(ctx, req.Method, req.ID)
+		resp := server.receiveRequestWithRecovery(ctx, sessionID, req)
+		if span != nil {
+			endSpan(span, resp)
+		}
+		var respErr error
+		if resp.Error != nil {
+			respErr = pkg.NewResponseError(resp.Error.Code, resp.Error.Message, resp.Error.Data)
+		}
+		server.observers.OnRequestEnd(ctx, req.Method, req.ID, respErr, time.Since(start))
+		server.writeResponse(ctx, ch, resp)
 	}(pkg.NewCancelShieldContext(ctx))
 	return ch, nil
 }
 
+// writeResponse writes the response of the request handled with ctx. The response of a request the client cancelled
+// is dropped since the client no longer expects one, and a result that comes after the deadline of the request
+// is replaced by a timeout error since the client has moved on.
+func (server *Server) writeResponse(ctx context.Context, ch chan<- []byte, resp *protocol.JSONRPCResponse) {
+	switch err := ctx.Err(); {
+	case errors.Is(err, context.Canceled):
+		return
+	case err != nil && resp.Error == nil:
+		resp = protocol.NewJSONRPCErrorResponse(resp.ID, protocol.InternalError, fmt.Sprintf("request timed out: %v", err))
+	}
+
+	message, err := json.Marshal(resp)
+	if err != nil {
+		server.logger.Error("marshal response fail", "id", resp.ID, "error", err)
+		return
+	}
+	ch <- message
+}
+
+// checkInitPhase enforces the initialization handshake: initialize is only accepted once per session and other
+// requests only after it, or with WithStrictInitialization only after the initialized notification
+func (server *Server) checkInitPhase(phase session.InitPhase, method protocol.Method) error {
+	switch {
+	case method == protocol.Initialize:
+		if phase != session.InitPhaseUninitialized {
+			return fmt.Errorf("%w: initialize sent more than once", pkg.ErrSessionAlreadyInitialized)
+		}
+	case phase == session.InitPhaseUninitialized:
+		return fmt.Errorf("%w: method=%s sent before initialize", pkg.ErrSessionHasNotInitialized, method)
+	case phase == session.InitPhaseInitializing && server.strictInitialization:
+		return fmt.Errorf("%w: method=%s sent before the initialized notification", pkg.ErrSessionHasNotInitialized, method)
+	}
+	return nil
+}
+
+// parseTraceParent reads the W3C traceparent of a request from its _meta, falling back to the HTTP header
+func parseTraceParent(ctx context.Context, meta map[string]interface{}) (pkg.TraceParent, bool) {
+	var value string
+	if v, ok := meta[protocol.TraceParentKey]; ok {
+		value = fmt.Sprint(v)
+	} else if header, ok := ctx.Value(transport.TraceParentKey{}).(string); ok {
+		value = header
+	} else {
+		return pkg.TraceParent{}, false
+	}
+
+	traceParent, err := pkg.ParseTraceParent(value)
+	if err != nil {
+		return pkg.TraceParent{}, false
+	}
+	return traceParent, true
+}
+
+// requestIDOf returns the id of a malformed request if it is a string or a number, and nil otherwise
+func requestIDOf(msg []byte) protocol.RequestID {
+	switch id := gjson.GetBytes(msg, "id"); id.Type {
+	case gjson.String:
+		return id.String()
+	case gjson.Number:
+		return json.Number(id.Raw)
+	default:
+		return nil
+	}
+}
+
+// replyWithError logs the rejected message and returns a channel that only carries the given error response
+func (server *Server) replyWithError(resp *protocol.JSONRPCResponse) (<-chan []byte, error) {
+	server.logger.Warn("reject message", "id", resp.ID, "code", resp.Error.Code, "error", resp.Error.Message)
+	message, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan []byte, 1)
+	ch <- message
+	close(ch)
+	return ch, nil
+}
+
+// receiveRequestWithRecovery is the outermost wrapper of request handling,
+// a panic is converted to the response of the panic policy, see WithPanicPolicy.
+func (server *Server) receiveRequestWithRecovery(ctx context.Context, sessionID string, request *protocol.JSONRPCRequest) (resp *protocol.JSONRPCResponse) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp = server.handlePanic(request, r)
+		}
+	}()
+	return server.receiveRequest(ctx, sessionID, request)
+}
+
 func (server *Server) receiveRequest(ctx context.Context, sessionID string, request *protocol.JSONRPCRequest) *protocol.JSONRPCResponse {
 	if sessionID != "" {
 		ctx = setSessionIDToCtx(ctx, sessionID)
@@ -138,27 +308,53 @@ func (server *Server) receiveRequest(ctx context.Context, sessionID string, requ
 		result, err = server.handleRequestWithSubscribeResourceChange(sessionID, request.RawParams)
 	case protocol.ResourcesUnsubscribe:
 		result, err = server.handleRequestWithUnSubscribeResourceChange(sessionID, request.RawParams)
+	case protocol.CompletionComplete:
+		result, err = server.handleRequestWithComplete(ctx, request.RawParams)
 	case protocol.ToolsList:
-		result, err = server.handleRequestWithListTools(request.RawParams)
+		result, err = server.handleRequestWithListTools(ctx, request.RawParams)
 	case protocol.ToolsCall:
-		result, err = server.handleRequestWithCallTool(ctx, request.RawParams)
+		var callToolResult *protocol.CallToolResult
+		if callToolResult, err = server.handleRequestWithCallTool(ctx, request.RawParams); err == nil {
+			result = server.formatCallToolResult(sessionID, callToolResult)
+		}
 	default:
 		err = fmt.Errorf("%w: method=%s", pkg.ErrMethodNotSupport, request.Method)
 	}
 
 	if err != nil {
-		var code int
+		server.emitEvent(ServerEvent{Type: EventErrorOccurred, SessionID: sessionID, Method: string(request.Method), Err: err})
+
+		var (
+			code     int
+			message  = err.Error()
+			data     interface{}
+			protoErr *protocol.Error
+			validErr *protocol.ValidationError
+		)
 		switch {
+		case errors.As(err, &protoErr):
+			code, message, data = protoErr.Code, protoErr.Message, protoErr.Data
+		case errors.As(err, &validErr):
+			code, data = protocol.InvalidParams, validErr
+		case errors.Is(err, pkg.ErrRateLimitExceeded):
+			code = protocol.RateLimitExceeded
+			data = map[string]interface{}{"retryable": true}
+		case errors.Is(err, pkg.ErrUnsupportedProtocolVersion):
+			code = protocol.InvalidParams
+			data = map[string]interface{}{"supported": server.supportedProtocolVersions}
+		case errors.Is(err, pkg.ErrUnsupportedResourceScheme):
+			code = protocol.InvalidParams
+			data = map[string]interface{}{"supportedSchemes": server.resourceSchemes}
 		case errors.Is(err, pkg.ErrMethodNotSupport):
 			code = protocol.MethodNotFound
-		case errors.Is(err, pkg.ErrRequestInvalid):
+		case errors.Is(err, pkg.ErrRequestInvalid), errors.Is(err, pkg.ErrSessionAlreadyInitialized):
 			code = protocol.InvalidRequest
 		case errors.Is(err, pkg.ErrJSONUnmarshal):
 			code = protocol.ParseError
 		default:
 			code = protocol.InternalError
 		}
-		return protocol.NewJSONRPCErrorResponse(request.ID, code, err.Error())
+		return protocol.NewJSONRPCErrorResponseWithData(request.ID, code, message, data)
 	}
 	return protocol.NewJSONRPCSuccessResponse(request.ID, result)
 }
@@ -177,6 +373,9 @@ func (server *Server) receiveNotify(sessionID string, notify *protocol.JSONRPCNo
 		return server.handleNotifyWithInitialized(sessionID, notify.RawParams)
 	case protocol.NotificationCancelled:
 		return server.handleNotifyWithCancelled(sessionID, notify.RawParams)
+	case protocol.NotificationRootsListChanged:
+		server.invalidateRoots(sessionID)
+		return nil
 	default:
 		return fmt.Errorf("%w: method=%s", pkg.ErrMethodNotSupport, notify.Method)
 	}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestDuplicateRegistration(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	hello := protocol.NewToolWithInputSchema("hello", "", protocol.InputSchema{Type: protocol.Object})
+	handler := func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	}
+	if err := server.RegisterToolErr(hello, handler); err != nil {
+		t.Fatalf("RegisterToolErr: %+v", err)
+	}
+	if err := server.RegisterToolErr(hello, handler); !errors.Is(err, pkg.ErrAlreadyRegistered) {
+		t.Fatalf("expected second registration of hello to be rejected, got %v", err)
+	}
+
+	prompt := &protocol.Prompt{Name: "hello"}
+	promptHandler := func(context.Context, *protocol.GetPromptRequest) (*protocol.GetPromptResult, error) {
+		return &protocol.GetPromptResult{}, nil
+	}
+	if err := server.RegisterPromptErr(prompt, promptHandler); err != nil {
+		t.Fatalf("RegisterPromptErr: %+v", err)
+	}
+	if err := server.RegisterPromptErr(prompt, promptHandler); !errors.Is(err, pkg.ErrAlreadyRegistered) {
+		t.Fatalf("expected second registration of prompt hello to be rejected, got %v", err)
+	}
+
+	resource := &protocol.Resource{Name: "hello", URI: "file:///hello.txt"}
+	resourceHandler := func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+		return &protocol.ReadResourceResult{}, nil
+	}
+	if err := server.RegisterResourceErr(resource, resourceHandler); err != nil {
+		t.Fatalf("RegisterResourceErr: %+v", err)
+	}
+	if err := server.RegisterResourceErr(resource, resourceHandler); !errors.Is(err, pkg.ErrAlreadyRegistered) {
+		t.Fatalf("expected second registration of resource hello to be rejected, got %v", err)
+	}
+
+	// the void variant keeps replacing for compatibility
+	replaced := protocol.NewToolWithInputSchema("hello", "replaced", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(replaced, handler)
+	if entry, _ := server.tools.Load("hello"); entry.tool != replaced {
+		t.Fatalf("expected RegisterTool to replace the registered tool")
+	}
+}
+
+func TestUnregisterTool(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testServerInit(t, server, in, outScan)
+
+	plugin := protocol.NewToolWithInputSchema("plugin_tool", "", protocol.InputSchema{Type: protocol.Object})
+	// the list changed notification is written synchronously to the pipe, so register while reading it
+	go server.RegisterTool(plugin, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationToolsListChanged) {
+		t.Fatalf("expected tools list changed notification on register, got %v", notify)
+	}
+
+	removed := make(chan bool, 1)
+	go func() {
+		removed <- server.UnregisterTool(plugin.Name)
+	}()
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationToolsListChanged) {
+		t.Fatalf("expected tools list changed notification on unregister, got %v", notify)
+	}
+	if !<-removed {
+		t.Fatal("expected registered tool to be removed")
+	}
+	if server.UnregisterTool(plugin.Name) {
+		t.Fatal("expected unregistering an unknown tool to report false")
+	}
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(plugin.Name, map[string]interface{}{}))
+	resp := testReadMessage(t, outScan)
+	if errObj, _ := resp["error"].(map[string]interface{}); errObj["code"] != float64(protocol.MethodNotFound) {
+		t.Fatalf("expected method not found calling an unregistered tool, got %v", resp)
+	}
+}
+
+func TestToolRegistration(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	reg := server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	listTools := func(id int) []interface{} {
+		testWriteRequest(t, in, id, protocol.ToolsList, protocol.ListToolsRequest{})
+		resp := testReadMessage(t, outScan)
+		result, _ := resp["result"].(map[string]interface{})
+		tools, _ := result["tools"].([]interface{})
+		return tools
+	}
+	expectListChanged := func(mutate func()) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			mutate()
+		}()
+		if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationToolsListChanged) {
+			t.Fatalf("expected tool list changed notification, got %v", notify)
+		}
+		<-done
+	}
+
+	expectListChanged(reg.Disable)
+	if reg.Enabled() {
+		t.Fatal("expected the tool to be disabled")
+	}
+	if tools := listTools(1); len(tools) != 0 {
+		t.Fatalf("expected disabled tool to be hidden, got %v", tools)
+	}
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.CallToolRequest{Name: testTool.Name})
+	if resp := testReadMessage(t, outScan); resp["error"] == nil {
+		t.Fatalf("expected call of disabled tool to fail, got %v", resp)
+	}
+
+	expectListChanged(reg.Enable)
+	if tools := listTools(3); len(tools) != 1 {
+		t.Fatalf("expected enabled tool to be listed, got %v", tools)
+	}
+
+	schema := protocol.InputSchema{Type: protocol.Object, Required: []string{"name"}}
+	expectListChanged(func() {
+		if err := reg.UpdateSchema(schema); err != nil {
+			t.Errorf("UpdateSchema: %v", err)
+		}
+	})
+	tools := listTools(4)
+	if len(tools) != 1 {
+		t.Fatalf("expected 1 tool, got %v", tools)
+	}
+	inputSchema, _ := tools[0].(map[string]interface{})["inputSchema"].(map[string]interface{})
+	if required, _ := inputSchema["required"].([]interface{}); len(required) != 1 || required[0] != "name" {
+		t.Fatalf("expected updated input schema, got %v", inputSchema)
+	}
+
+	expectListChanged(func() { server.UnregisterTool(testTool.Name) })
+	if err := reg.UpdateSchema(schema); err == nil {
+		t.Fatal("expected UpdateSchema of an unregistered tool to fail")
+	}
+}
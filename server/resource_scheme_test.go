@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestUnsupportedResourceScheme(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithResourceSchemes("file"))
+
+	server.RegisterResource(&protocol.Resource{Name: "readme", URI: "file:///README.md"},
+		func(_ context.Context, req *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+			return protocol.NewReadResourceResult([]protocol.ResourceContents{
+				protocol.NewTextResourceContents(req.URI, "text/markdown", "# readme"),
+			}), nil
+		})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "http://example.com/README.md"})
+	resp := testReadMessage(t, outScan)
+	errObj, _ := resp["error"].(map[string]interface{})
+	data, _ := errObj["data"].(map[string]interface{})
+	if errObj["code"] != float64(protocol.InvalidParams) || !reflect.DeepEqual(data["supportedSchemes"], []interface{}{"file"}) {
+		t.Fatalf("expected scheme unsupported error listing file, got %v", resp)
+	}
+
+	testWriteRequest(t, in, 2, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "file:///missing.md"})
+	resp = testReadMessage(t, outScan)
+	errObj, _ = resp["error"].(map[string]interface{})
+	if errObj == nil || errObj["code"] == float64(protocol.InvalidParams) {
+		t.Fatalf("expected a not found error distinct from scheme unsupported, got %v", resp)
+	}
+
+	testWriteRequest(t, in, 3, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "file:///README.md"})
+	if resp = testReadMessage(t, outScan); resp["result"] == nil {
+		t.Fatalf("expected file resource to be read, got %v", resp)
+	}
+}
+
+func TestReadResourceRange(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	log := []byte("line 1\nline 2\nline 3\n")
+	server.RegisterResource(&protocol.Resource{Name: "log", URI: "file:///app.log"},
+		func(_ context.Context, req *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+			result := protocol.NewReadResourceResult([]protocol.ResourceContents{
+				protocol.NewBlobResourceContents(req.URI, "text/plain", req.Range.Apply(log)),
+			})
+			result.TotalSize = int64(len(log))
+			return result, nil
+		})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "file:///app.log", Range: &protocol.ByteRange{Offset: 7, Length: 6}})
+	resp := testReadMessage(t, outScan)
+	raw, err := json.Marshal(resp["result"])
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	var result protocol.ReadResourceResult
+	if err = json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("json Unmarshal: %+v", err)
+	}
+	blob, ok := result.Contents[0].(*protocol.BlobResourceContents)
+	if !ok {
+		t.Fatalf("unexpected contents %+v", result.Contents[0])
+	}
+	if data, _ := base64.StdEncoding.DecodeString(blob.Blob); string(data) != "line 2" || result.TotalSize != int64(len(log)) {
+		t.Fatalf("unexpected range read %q of %d bytes", data, result.TotalSize)
+	}
+
+	testWriteRequest(t, in, 2, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "file:///app.log", Range: &protocol.ByteRange{Offset: -1}})
+	resp = testReadMessage(t, outScan)
+	if errObj, _ := resp["error"].(map[string]interface{}); errObj["code"] != float64(protocol.InvalidParams) {
+		t.Fatalf("expected an invalid params error for a negative offset, got %v", resp)
+	}
+}
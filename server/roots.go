@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// WithRequiresRoots returns a tool middleware rejecting calls from clients that declared no roots,
+// e.g. for filesystem tools that only operate within the roots of the client:
+// server.RegisterTool(tool, handler, WithRequiresRoots())
+// The roots are listed on every call, so roots declared after initialization are taken into account.
+func WithRequiresRoots() ToolMiddleware {
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			server, ok := getServerFromCtx(ctx)
+			if !ok {
+				return nil, errors.New("no server found")
+			}
+
+			result, err := server.ListRoots(ctx)
+			if err != nil && !errors.Is(err, pkg.ErrClientNotSupport) {
+				return nil, err
+			}
+			if result == nil || len(result.Roots) == 0 {
+				return nil, protocol.NewError(protocol.InvalidRequest,
+					fmt.Sprintf("tool %s requires roots, declare the roots capability with at least one root", req.Name), nil)
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// rootsCache holds the roots of a session between roots/list_changed notifications
+type rootsCache struct {
+	mu    sync.Mutex
+	roots []protocol.Root
+	valid bool
+	// gen is bumped by every roots/list_changed notification, so that roots listed before it aren't cached
+	gen uint64
+}
+
+// RootsFromContext returns the roots the client of the session in ctx granted, e.g. for a filesystem tool to
+// check with PathInRoots that a requested path is within them. The roots are listed on first access and cached
+// for the session until the client notifies that they changed. It fails with pkg.ErrClientNotSupport
+// if the client did not declare the roots capability.
+func RootsFromContext(ctx context.Context) ([]protocol.Root, error) {
+	server, ok := getServerFromCtx(ctx)
+	if !ok {
+		return nil, errors.New("no server found")
+	}
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok = server.sessionManager.GetSession(sessionID); !ok {
+		return nil, pkg.ErrLackSession
+	}
+
+	cache, _ := server.sessionRoots.LoadOrStore(sessionID, &rootsCache{})
+	cache.mu.Lock()
+	if cache.valid {
+		roots := append([]protocol.Root(nil), cache.roots...)
+		cache.mu.Unlock()
+		return roots, nil
+	}
+	gen := cache.gen
+	cache.mu.Unlock()
+
+	result, err := server.ListRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]protocol.Root, 0, len(result.Roots))
+	for _, root := range result.Roots {
+		if root != nil {
+			roots = append(roots, *root)
+		}
+	}
+
+	cache.mu.Lock()
+	// roots listed while a change notification arrived may be outdated already, they are returned but not cached
+	if cache.gen == gen {
+		cache.roots, cache.valid = roots, true
+	}
+	cache.mu.Unlock()
+	return append([]protocol.Root(nil), roots...), nil
+}
+
+// invalidateRoots drops the cached roots of the session, they are listed again on next access
+func (server *Server) invalidateRoots(sessionID string) {
+	cache, ok := server.sessionRoots.Load(sessionID)
+	if !ok {
+		return
+	}
+	cache.mu.Lock()
+	cache.gen++
+	cache.roots, cache.valid = nil, false
+	cache.mu.Unlock()
+}
+
+// PathInRoots reports whether path, once cleaned, is one of the file:// roots or inside one of them, so that a
+// traversal like /project/../etc/passwd is rejected. Symbolic links are not resolved, callers that follow them
+// should pass the result of filepath.EvalSymlinks.
+func PathInRoots(path string, roots []protocol.Root) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		u, err := url.Parse(root.URI)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			continue
+		}
+		rootPath := filepath.Clean(filepath.FromSlash(u.Path))
+		rel, err := filepath.Rel(rootPath, path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
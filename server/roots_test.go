@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/client"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestWithRequiresRoots(t *testing.T) {
+	tool := protocol.NewToolWithInputSchema("read_file", "", protocol.InputSchema{Type: protocol.Object})
+	setup := func(s *Server) {
+		s.RegisterTool(tool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: "ok"}}, false), nil
+		}, WithRequiresRoots())
+	}
+	call := func(mcpClient *client.Client) (*protocol.CallToolResult, error) {
+		return mcpClient.CallTool(context.Background(), protocol.NewCallToolRequest(tool.Name, map[string]interface{}{}))
+	}
+
+	tests := []struct {
+		name    string
+		opts    []client.Option
+		wantErr bool
+	}{
+		{name: "no roots capability", opts: nil, wantErr: true},
+		{name: "no roots", opts: []client.Option{client.WithRoots()}, wantErr: true},
+		{name: "roots", opts: []client.Option{client.WithRoots(&protocol.Root{Name: "project", URI: "file:///project"})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mcpClient, _ := newTestServerAndClient(t, nil, tt.opts, setup)
+
+			result, err := call(mcpClient)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "requires roots") {
+					t.Fatalf("expected the call to be rejected for missing roots, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CallTool: %+v", err)
+			}
+			if text := result.Content[0].(*protocol.TextContent).Text; text != "ok" {
+				t.Fatalf("unexpected tool result %q", text)
+			}
+		})
+	}
+}
+
+func TestRootsFromContext(t *testing.T) {
+	tool := protocol.NewToolWithInputSchema("list_roots", "", protocol.InputSchema{Type: protocol.Object})
+	setup := func(s *Server) {
+		s.RegisterTool(tool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			roots, err := RootsFromContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			uris := make([]string, 0, len(roots))
+			for _, root := range roots {
+				uris = append(uris, root.URI)
+			}
+			return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: strings.Join(uris, ",")}}, false), nil
+		})
+	}
+	server, mcpClient, sessionID := newTestServerAndClient(t, nil,
+		[]client.Option{client.WithRoots(&protocol.Root{Name: "project", URI: "file:///project"})}, setup)
+
+	listRoots := func() string {
+		result, err := mcpClient.CallTool(context.Background(), protocol.NewCallToolRequest(tool.Name, map[string]interface{}{}))
+		if err != nil {
+			t.Fatalf("CallTool: %+v", err)
+		}
+		return result.Content[0].(*protocol.TextContent).Text
+	}
+
+	if roots := listRoots(); roots != "file:///project" {
+		t.Fatalf("unexpected roots %q", roots)
+	}
+	cache, ok := server.sessionRoots.Load(sessionID)
+	if !ok {
+		t.Fatal("expected the roots to be cached for the session")
+	}
+	cache.mu.Lock()
+	valid := cache.valid
+	cache.mu.Unlock()
+	if !valid {
+		t.Fatal("expected the roots to be cached for the session")
+	}
+
+	if err := mcpClient.SetRoots(context.Background(), &protocol.Root{URI: "file:///project"}, &protocol.Root{URI: "file:///docs"}); err != nil {
+		t.Fatalf("SetRoots: %+v", err)
+	}
+	if roots := listRoots(); roots != "file:///project,file:///docs" {
+		t.Fatalf("expected the roots to be listed again once changed, got %q", roots)
+	}
+}
+
+func TestPathInRoots(t *testing.T) {
+	roots := []protocol.Root{{URI: "file:///project"}, {URI: "https://example.com/project"}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/project", want: true},
+		{path: "/project/src/main.go", want: true},
+		{path: "/project/../etc/passwd", want: false},
+		{path: "/projects/other", want: false},
+		{path: "/etc/passwd", want: false},
+	}
+	for _, tt := range tests {
+		if got := PathInRoots(filepath.FromSlash(tt.path), roots); got != tt.want {
+			t.Errorf("PathInRoots(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestToolSchemaVersion(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object}).SetSchemaVersion("1")
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	listVersion := func(id int) interface{} {
+		testWriteRequest(t, in, id, protocol.ToolsList, protocol.ListToolsRequest{})
+		resp := testReadMessage(t, outScan)
+		result, _ := resp["result"].(map[string]interface{})
+		tools, _ := result["tools"].([]interface{})
+		if len(tools) != 1 {
+			t.Fatalf("expected 1 tool, got %v", resp)
+		}
+		return tools[0].(map[string]interface{})["schemaVersion"]
+	}
+
+	if v := listVersion(1); v != "1" {
+		t.Fatalf("expected schema version 1, got %v", v)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.SetToolSchemaVersion(testTool.Name, "2")
+	}()
+	if notify := testReadMessage(t, outScan); notify["method"] != string(protocol.NotificationToolsListChanged) {
+		t.Fatalf("expected tool list changed notification, got %v", notify)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("SetToolSchemaVersion: %v", err)
+	}
+
+	if v := listVersion(2); v != "2" {
+		t.Fatalf("expected schema version 2, got %v", v)
+	}
+
+	if err := server.SetToolSchemaVersion("missing", "1"); err == nil {
+		t.Fatal("expected error for unknown tool")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
 )
 
@@ -16,13 +17,24 @@ func (server *Server) sendMsgWithRequest(ctx context.Context, sessionID string,
 	}
 
 	req := protocol.NewJSONRPCRequest(requestID, method, params)
+	if traceParent, err := GetTraceParentFromCtx(ctx); err == nil {
+		if req.Params, err = pkg.JSONWithMeta(req.Params, protocol.TraceParentKey, traceParent.String()); err != nil {
+			return err
+		}
+	}
+	if rootCallID, ok := RootCallID(ctx); ok {
+		var err error
+		if req.Params, err = pkg.JSONWithMeta(req.Params, protocol.RootCallIDKey, rootCallID); err != nil {
+			return err
+		}
+	}
 
 	message, err := json.Marshal(req)
 	if err != nil {
 		return err
 	}
 
-	if ch, err := getSendChanFromCtx(ctx); err == nil {
+	if ch, err := getSendChanFromCtx(ctx, sessionID); err == nil {
 		ch <- message
 		return nil
 	}
@@ -41,7 +53,7 @@ func (server *Server) sendMsgWithNotification(ctx context.Context, sessionID str
 		return err
 	}
 
-	if ch, err := getSendChanFromCtx(ctx); err == nil {
+	if ch, err := getSendChanFromCtx(ctx, sessionID); err == nil {
 		ch <- message
 		return nil
 	}
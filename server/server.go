@@ -2,11 +2,17 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
@@ -16,33 +22,58 @@ import (
 
 type Option func(*Server)
 
+// WithCapabilities sets the capabilities advertised in the initialize result.
+//
+// Explicit configuration takes precedence over auto-derivation: the capabilities are advertised exactly as
+// configured, a nil capability disables it and listChanged is only advertised, and the corresponding list changed
+// notifications only sent, when set. Without this option capabilities are derived from what is registered,
+// e.g. registering a tool advertises the tools capability with listChanged.
 func WithCapabilities(capabilities protocol.ServerCapabilities) Option {
 	return func(s *Server) {
 		s.capabilities = &capabilities
+		s.capabilitiesConfigured = true
 	}
 }
 
+// WithServerInfo sets the serverInfo of the initialize result, an empty name or version falls back to
+// the executable name and the main module version respectively
 func WithServerInfo(serverInfo protocol.Implementation) Option {
 	return func(s *Server) {
 		s.serverInfo = &serverInfo
 	}
 }
 
+// WithInstructions sets the instructions of the initialize result, clients may surface them to the model
 func WithInstructions(instructions string) Option {
 	return func(s *Server) {
 		s.instructions = instructions
 	}
 }
 
+// WithKeepAlive pings every session at the given interval to keep idle connections open behind proxies,
+// a session whose client fails to answer three pings in a row within timeout each is closed.
+// An interval of 0 disables the pings. By default sessions are pinged every minute with a timeout of 3 seconds.
+func WithKeepAlive(interval, timeout time.Duration) Option {
+	return func(s *Server) {
+		s.sessionManager.SetHeartbeatInterval(interval)
+		s.keepAliveTimeout = timeout
+	}
+}
+
 func WithSessionMaxIdleTime(maxIdleTime time.Duration) Option {
 	return func(s *Server) {
 		s.sessionManager.SetMaxIdleTime(maxIdleTime)
 	}
 }
 
-func WithLogger(logger pkg.Logger) Option {
+// WithSupportedProtocolVersions restricts the protocol versions the server negotiates during initialize,
+// by default every version the library implements is supported
+func WithSupportedProtocolVersions(versions ...string) Option {
 	return func(s *Server) {
-		s.logger = logger
+		if len(versions) == 0 {
+			return
+		}
+		s.supportedProtocolVersions = sortVersionsDesc(versions)
 	}
 }
 
@@ -50,11 +81,64 @@ func WithLogger(logger pkg.Logger) Option {
 // Allow ToolHandlerFunc to be wrapped like a chain call
 type ToolMiddleware func(ToolHandlerFunc) ToolHandlerFunc
 
-// RateLimitMiddleware Return a rate-limiting middleware
+// RateLimitKeyFunc computes the key a request is rate limited by, requests sharing a key share a token bucket
+type RateLimitKeyFunc func(ctx context.Context, req *protocol.CallToolRequest) string
+
+// RateLimitByTool rate limits each tool independently
+func RateLimitByTool(_ context.Context, req *protocol.CallToolRequest) string {
+	return req.Name
+}
+
+// RateLimitBySession rate limits each session independently, across all tools
+func RateLimitBySession(ctx context.Context, _ *protocol.CallToolRequest) string {
+	sessionID, _ := GetSessionIDFromCtx(ctx)
+	return sessionID
+}
+
+// RateLimitBySessionAndTool rate limits each tool of each session independently
+func RateLimitBySessionAndTool(ctx context.Context, req *protocol.CallToolRequest) string {
+	sessionID, _ := GetSessionIDFromCtx(ctx)
+	return sessionID + "/" + req.Name
+}
+
+// RateLimitOptions configures RateLimitMiddlewareWithOptions
+type RateLimitOptions struct {
+	// RequestsPerSecond is the rate tokens are refilled at
+	RequestsPerSecond float64
+	// Burst is the maximum number of requests allowed at once
+	Burst int
+	// KeyFunc selects the bucket of a request, e.g. by auth token or client ID. Defaults to RateLimitByTool.
+	KeyFunc RateLimitKeyFunc
+	// Limiter overrides the built-in token bucket limiter built from RequestsPerSecond and Burst
+	Limiter pkg.RateLimiter
+}
+
+// RateLimitMiddleware Return a rate-limiting middleware, limiting each tool by its name. A nil limiter allows all requests.
+// Requests over the limit fail with the protocol.RateLimitExceeded error code, which clients may retry later.
 func RateLimitMiddleware(limiter pkg.RateLimiter) ToolMiddleware {
+	if limiter == nil {
+		return func(next ToolHandlerFunc) ToolHandlerFunc {
+			return next
+		}
+	}
+	return RateLimitMiddlewareWithOptions(RateLimitOptions{Limiter: limiter})
+}
+
+// RateLimitMiddlewareWithOptions is like RateLimitMiddleware but builds the limiter from opts
+// and selects the bucket of a request by opts.KeyFunc, e.g. to limit each session independently
+func RateLimitMiddlewareWithOptions(opts RateLimitOptions) ToolMiddleware {
+	limiter := opts.Limiter
+	if limiter == nil {
+		limiter = pkg.NewTokenBucketLimiter(pkg.Rate{Limit: opts.RequestsPerSecond, Burst: opts.Burst})
+	}
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = RateLimitByTool
+	}
+
 	return func(next ToolHandlerFunc) ToolHandlerFunc {
 		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
-			if limiter != nil && !limiter.Allow(req.Name) {
+			if !limiter.Allow(keyFunc(ctx, req)) {
 				return nil, pkg.ErrRateLimitExceeded
 			}
 			return next(ctx, req)
@@ -62,12 +146,182 @@ func RateLimitMiddleware(limiter pkg.RateLimiter) ToolMiddleware {
 	}
 }
 
+// MiddlewareWithTimeout bounds the time the named middleware may spend on a request, excluding the time spent
+// in the handlers it wraps. A middleware exceeding it has its context canceled and the request fails with
+// pkg.ErrMiddlewareTimeout naming the middleware, e.g. server.Use(MiddlewareWithTimeout("auth", time.Second, auth)).
+// The middleware keeps running in the background until it observes the cancellation.
+func MiddlewareWithTimeout(name string, timeout time.Duration, middleware ToolMiddleware) ToolMiddleware {
+	type outcome struct {
+		result   *protocol.CallToolResult
+		err      error
+		panicked interface{}
+	}
+
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			enterNext := make(chan struct{}, 1)
+			exitNext := make(chan struct{}, 1)
+			signal := func(ch chan<- struct{}) {
+				select {
+				case ch <- struct{}{}:
+				case <-ctx.Done():
+				}
+			}
+			handler := middleware(func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+				signal(enterNext)
+				defer signal(exitNext)
+				return next(ctx, req)
+			})
+
+			done := make(chan outcome, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- outcome{panicked: r}
+					}
+				}()
+				result, err := handler(ctx, req)
+				done <- outcome{result: result, err: err}
+			}()
+
+			remaining := timeout
+			start := time.Now()
+			timer := time.NewTimer(remaining)
+			defer timer.Stop()
+			for {
+				select {
+				case o := <-done:
+					if o.panicked != nil {
+						// re-panic in the request goroutine so that the panic policy applies
+						panic(o.panicked)
+					}
+					return o.result, o.err
+				case <-enterNext:
+					if !timer.Stop() {
+						return nil, fmt.Errorf("%w: middleware %q did not complete within %v", pkg.ErrMiddlewareTimeout, name, timeout)
+					}
+					// the wrapped handlers are not accounted to the middleware
+					remaining -= time.Since(start)
+				case <-exitNext:
+					start = time.Now()
+					timer.Reset(remaining)
+				case <-timer.C:
+					return nil, fmt.Errorf("%w: middleware %q did not complete within %v", pkg.ErrMiddlewareTimeout, name, timeout)
+				}
+			}
+		}
+	}
+}
+
+// WithMaxMessageSize bounds the size in bytes of the messages the server sends. A full tools/list result
+// exceeding it, e.g. thousands of tools listed without WithPagination, is truncated with a warning in its _meta
+// and a nextCursor paginating clients can continue from, rather than failing. Only a listing whose first tool
+// alone exceeds it fails. 0 means no limit.
+func WithMaxMessageSize(size int) Option {
+	return func(s *Server) {
+		s.maxMessageSize = size
+	}
+}
+
+// WithPagination sets the page size of tools/list, prompts/list, resources/list and resources/templates/list,
+// clients continue from the nextCursor of a page. 0 means no paging.
 func WithPagination(limit int) Option {
 	return func(s *Server) {
 		s.paginationLimit = limit
 	}
 }
 
+// WithArgumentCoercion sets how BindArguments treats tool and prompt arguments whose type doesn't match their field.
+// By default numeric fields accept numeric strings and integral floats, protocol.ArgumentCoercionStrict rejects them.
+func WithArgumentCoercion(coercion protocol.ArgumentCoercion) Option {
+	return func(s *Server) {
+		s.argumentCoercion = coercion
+	}
+}
+
+// WithListChangedDebounce sets how long list changes are coalesced into a single list changed notification,
+// 50ms by default. 0 sends a notification for every change.
+func WithListChangedDebounce(d time.Duration) Option {
+	return func(s *Server) {
+		s.listChangedDebounce = d
+	}
+}
+
+// WithMaxSubscriptionsPerSession limits the number of resources a single session can subscribe to,
+// subscribe requests beyond the limit are rejected. 0 means no limit.
+func WithMaxSubscriptionsPerSession(n int) Option {
+	return func(s *Server) {
+		s.maxSubscriptionsPerSession = n
+	}
+}
+
+// WithMaxConcurrencyPerSession limits the number of requests a single session can have in flight,
+// requests beyond the limit are queued until a slot frees up while other sessions proceed. 0 means no limit.
+func WithMaxConcurrencyPerSession(n int) Option {
+	return func(s *Server) {
+		s.maxConcurrencyPerSession = n
+	}
+}
+
+// WithMaxConcurrentRequests limits the number of requests handled concurrently across all sessions,
+// requests beyond the limit are queued until a slot frees up. Each request is handled in its own goroutine,
+// so without a limit slow handlers never block other requests. 0 means no limit.
+func WithMaxConcurrentRequests(n int) Option {
+	return func(s *Server) {
+		s.requestSlots = nil
+		if n > 0 {
+			s.requestSlots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithToolTimeout bounds the time a tool call may take, the context of the tool handler is canceled
+// once it elapses. 0 means no timeout.
+func WithToolTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.toolTimeout = d
+	}
+}
+
+// WithResourceTimeout bounds the time a resource read may take independently of WithToolTimeout,
+// since disk or network reads may warrant a different budget. 0 means no timeout.
+func WithResourceTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.resourceTimeout = d
+	}
+}
+
+// WithResourceSchemes registers the uri schemes the server serves resources for, e.g. "file".
+// Reading a resource with another scheme is rejected with an error listing the supported schemes,
+// distinct from reading an unknown resource. By default any scheme is routed.
+func WithResourceSchemes(schemes ...string) Option {
+	return func(s *Server) {
+		for _, scheme := range schemes {
+			s.resourceSchemes = append(s.resourceSchemes, strings.ToLower(scheme))
+		}
+	}
+}
+
+// WithMimeDetection fills in the MIME type of resource contents read without one, detected from the extension
+// of their uri or else by sniffing their data, falling back to application/octet-stream.
+// MIME types set by handlers are left untouched.
+func WithMimeDetection() Option {
+	return func(s *Server) {
+		s.mimeDetection = true
+	}
+}
+
+// WithStrictInitialization rejects requests other than initialize and ping until the client
+// has sent the initialized notification, by default such requests are served for compatibility
+func WithStrictInitialization() Option {
+	return func(s *Server) {
+		s.strictInitialization = true
+	}
+}
+
 func WithGenSessionIDFunc(genSessionID func(context.Context) string) Option {
 	return func(s *Server) {
 		s.genSessionID = genSessionID
@@ -76,44 +330,118 @@ func WithGenSessionIDFunc(genSessionID func(context.Context) string) Option {
 
 type Server struct {
 	transport transport.ServerTransport
+	// streaming is set if the transport delivers partial tool results as they are sent
+	streaming bool
 
 	tools             pkg.SyncMap[*toolEntry]
 	prompts           pkg.SyncMap[*promptEntry]
 	resources         pkg.SyncMap[*resourceEntry]
 	resourceTemplates pkg.SyncMap[*resourceTemplateEntry]
+	completions       pkg.SyncMap[CompletionHandlerFunc] // keyed by completionKey
 
 	sessionManager *session.Manager
 
 	inShutdown   *pkg.AtomicBool // true when server is in shutdown
 	inFlyRequest sync.WaitGroup
 
+	shutdownOnce sync.Once
+	shutdownDone chan struct{} // closed once the first Shutdown call has completed
+	shutdownErr  error
+
 	capabilities *protocol.ServerCapabilities
 	serverInfo   *protocol.Implementation
 	instructions string
 
+	capabilitiesConfigured bool // capabilities were set explicitly by WithCapabilities
+
+	supportedProtocolVersions []string // newest first
+
 	paginationLimit int
 
-	logger pkg.Logger
+	listChangedDebounce time.Duration
+	toolListChanged     *listChangedNotifier
+	promptListChanged   *listChangedNotifier
+	resourceListChanged *listChangedNotifier
+
+	maxMessageSize int
+
+	maxSubscriptionsPerSession int
+
+	maxConcurrencyPerSession int
+
+	requestSlots chan struct{} // bounds the requests handled concurrently across sessions, nil means no limit
+
+	toolTimeout     time.Duration
+	resourceTimeout time.Duration
+
+	resourceSchemes []string // uri schemes resources/read is served for, empty means any
+
+	mimeDetection bool
+
+	argumentCoercion protocol.ArgumentCoercion
+
+	panicPolicy PanicPolicy
+
+	strictInitialization bool
+
+	keepAliveTimeout time.Duration
+
+	logger Logger
 
 	genSessionID func(ctx context.Context) string
 
-	globalMiddlewares []ToolMiddleware
+	globalMiddlewares         []ToolMiddleware
+	globalResourceMiddlewares []ResourceMiddleware
+	globalPromptMiddlewares   []PromptMiddleware
+
+	resultTransformers []ResultTransformer
+
+	unknownToolHandler atomic.Value // ToolHandlerFunc, serves calls to tools that are not registered
+
+	idempotencyStore ResultCache // nil unless WithIdempotency is used
+	idempotencyTTL   time.Duration
+	idempotentCalls  pkg.SyncMap[*idempotentCall] // running calls by idempotency key
+
+	sessionScopedTools func(ctx context.Context) []*ToolRegistration
+	sessionTools       pkg.SyncMap[*sessionToolSet] // keyed by session ID
+
+	sessionRoots pkg.SyncMap[*rootsCache] // keyed by session ID, see RootsFromContext
+
+	legacyResultClients *regexp.Regexp // clients served tool results in the legacy format
+
+	events chan ServerEvent
+
+	observers observers
+
+	tracer trace.Tracer // nil unless WithTracer is used, no span is started then
 }
 
 func NewServer(t transport.ServerTransport, opts ...Option) (*Server, error) {
 	server := &Server{
 		transport: t,
 		capabilities: &protocol.ServerCapabilities{
-			Prompts:   &protocol.PromptsCapability{ListChanged: true},
-			Resources: &protocol.ResourcesCapability{ListChanged: true, Subscribe: true},
-			Tools:     &protocol.ToolsCapability{ListChanged: true},
+			Prompts:     &protocol.PromptsCapability{ListChanged: true},
+			Resources:   &protocol.ResourcesCapability{ListChanged: true, Subscribe: true},
+			Tools:       &protocol.ToolsCapability{ListChanged: true},
+			Completions: &protocol.CompletionsCapability{},
 		},
 		inShutdown:   pkg.NewAtomicBool(),
+		shutdownDone: make(chan struct{}),
 		serverInfo:   &protocol.Implementation{},
-		logger:       pkg.DefaultLogger,
+		logger:       NewPrintfLogger(pkg.DefaultLogger),
 		genSessionID: func(context.Context) string { return uuid.NewString() },
+		events:       make(chan ServerEvent, defaultEventBufferSize),
+
+		supportedProtocolVersions: defaultSupportedProtocolVersions(),
+
+		keepAliveTimeout: 3 * time.Second,
+
+		listChangedDebounce: defaultListChangedDebounce,
 	}
 
+	if st, ok := t.(transport.StreamingTransport); ok {
+		server.streaming = st.SupportsStreaming()
+	}
 	t.SetReceiver(transport.ServerReceiverF(server.receive))
 
 	server.sessionManager = session.NewManager(server.sessionDetection, server.genSessionID)
@@ -122,7 +450,23 @@ func NewServer(t transport.ServerTransport, opts ...Option) (*Server, error) {
 		opt(server)
 	}
 
-	server.sessionManager.SetLogger(server.logger)
+	server.serverInfo = withDefaultServerInfo(*server.serverInfo)
+	server.toolListChanged = server.newListChangedNotifier("tool", server.sendNotification4ToolListChanges)
+	server.promptListChanged = server.newListChangedNotifier("prompt", server.sendNotification4PromptListChanges)
+	server.resourceListChanged = server.newListChangedNotifier("resource", server.sendNotification4ResourceListChanges)
+
+	server.sessionManager.SetLogger(&sessionLogger{logger: server.logger})
+	server.sessionManager.SetOnSessionCreated(func(sessionID string) {
+		server.emitEvent(ServerEvent{Type: EventSessionStarted, SessionID: sessionID})
+	})
+	server.sessionManager.SetOnSessionClosed(func(sessionID string) {
+		server.sessionTools.Delete(sessionID)
+		server.sessionRoots.Delete(sessionID)
+		if h, ok := t.(transport.SessionClosedHandler); ok {
+			h.OnSessionClosed(sessionID)
+		}
+		server.emitEvent(ServerEvent{Type: EventSessionEnded, SessionID: sessionID})
+	})
 
 	t.SetSessionManager(server.sessionManager)
 
@@ -131,7 +475,7 @@ func NewServer(t transport.ServerTransport, opts ...Option) (*Server, error) {
 
 func (server *Server) Run() error {
 	go func() {
-		defer pkg.Recover()
+		defer server.recoverPanic()
 
 		server.sessionManager.StartHeartbeatAndCleanInvalidSessions()
 	}()
@@ -142,37 +486,125 @@ func (server *Server) Run() error {
 	return nil
 }
 
+// advertisedCapabilities returns the capabilities sent in the initialize result.
+// Unless configured with WithCapabilities, a capability is only advertised once something providing it is registered.
+func (server *Server) advertisedCapabilities() *protocol.ServerCapabilities {
+	if server.capabilitiesConfigured {
+		return server.capabilities
+	}
+
+	capabilities := *server.capabilities
+	if !hasEntries(&server.tools) && server.sessionScopedTools == nil {
+		capabilities.Tools = nil
+	}
+	if !hasEntries(&server.prompts) {
+		capabilities.Prompts = nil
+	}
+	if !hasEntries(&server.resources) && !hasEntries(&server.resourceTemplates) {
+		capabilities.Resources = nil
+	}
+	if !hasEntries(&server.completions) {
+		capabilities.Completions = nil
+	}
+	return &capabilities
+}
+
+func hasEntries[V any](m *pkg.SyncMap[V]) bool {
+	has := false
+	m.Range(func(string, V) bool {
+		has = true
+		return false
+	})
+	return has
+}
+
 type toolEntry struct {
 	tool    *protocol.Tool
 	handler ToolHandlerFunc
+	reg     *ToolRegistration
 }
 
 type ToolHandlerFunc func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error)
 
-func (server *Server) RegisterTool(tool *protocol.Tool, toolHandler ToolHandlerFunc, middlewares ...ToolMiddleware) {
+// RegisterTool registers the tool, a tool already registered with the same name is replaced and a warning is logged.
+// The returned handle allows disabling the tool or updating its input schema later, callers not needing it can
+// ignore it as before, only code using RegisterTool as a function value is affected by the added result.
+func (server *Server) RegisterTool(tool *protocol.Tool, toolHandler ToolHandlerFunc, middlewares ...ToolMiddleware) *ToolRegistration {
+	reg, _ := server.registerTool(tool, toolHandler, middlewares, true)
+	return reg
+}
+
+// RegisterToolErr is like RegisterTool but rejects the registration with pkg.ErrAlreadyRegistered
+// if a tool with the same name is already registered.
+func (server *Server) RegisterToolErr(tool *protocol.Tool, toolHandler ToolHandlerFunc, middlewares ...ToolMiddleware) error {
+	_, err := server.registerTool(tool, toolHandler, middlewares, false)
+	return err
+}
+
+func (server *Server) registerTool(tool *protocol.Tool, toolHandler ToolHandlerFunc, middlewares []ToolMiddleware, replace bool) (*ToolRegistration, error) {
 	for i := len(middlewares) - 1; i >= 0; i-- {
 		toolHandler = middlewares[i](toolHandler)
 	}
 
 	finalHandler := server.buildMiddlewareChain(toolHandler)
 
-	server.tools.Store(tool.Name, &toolEntry{tool: tool, handler: finalHandler})
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4ToolListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification toll list changes fail: %v", err)
-			return
+	reg := &ToolRegistration{server: server, name: tool.Name, disabled: pkg.NewAtomicBool()}
+	entry := &toolEntry{tool: tool, handler: finalHandler, reg: reg}
+	if _, loaded := server.tools.LoadOrStore(tool.Name, entry); loaded {
+		if !replace {
+			return nil, fmt.Errorf("%w: toolName=%s", pkg.ErrAlreadyRegistered, tool.Name)
 		}
+		server.logger.Warn("tool is already registered, replacing it", "tool", tool.Name)
+		server.tools.Store(tool.Name, entry)
 	}
+	server.toolsChanged()
+	return reg, nil
 }
 
-func (server *Server) UnregisterTool(name string) {
-	server.tools.Delete(name)
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4ToolListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification toll list changes fail: %v", err)
-			return
-		}
+// toolsChanged emits the capability changed event and notifies clients that the tool list changed,
+// notifications are coalesced as for prompts and resources
+func (server *Server) toolsChanged() {
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "tools"})
+	server.toolListChanged.notify()
+}
+
+// UnregisterTool removes the tool at runtime and reports whether it was registered, clients are notified
+// that the tool list changed. Calls of the tool dispatched afterward are answered with method not found,
+// calls already dispatched run to completion.
+func (server *Server) UnregisterTool(name string) bool {
+	if _, ok := server.tools.LoadAndDelete(name); !ok {
+		return false
+	}
+	server.toolsChanged()
+	return true
+}
+
+// SetUnknownToolHandler sets a catch-all handler for calls to tools that are not registered, e.g. so a proxy
+// server can forward them to a backend. The global middlewares apply to it as to registered tools.
+// Passing nil restores the default, replying with method not found.
+func (server *Server) SetUnknownToolHandler(toolHandler ToolHandlerFunc) {
+	if toolHandler != nil {
+		toolHandler = server.buildMiddlewareChain(toolHandler)
+	}
+	server.unknownToolHandler.Store(toolHandler)
+}
+
+// SetToolSchemaVersion bumps the schema version of a registered tool and notifies clients that the tool list changed
+func (server *Server) SetToolSchemaVersion(name, version string) error {
+	entry, ok := server.tools.Load(name)
+	if !ok {
+		return fmt.Errorf("missing tool, toolName=%s", name)
+	}
+	if entry.tool.SchemaVersion == version {
+		return nil
 	}
+
+	// copy the tool, the registered one may be read concurrently by tools/list
+	tool := *entry.tool
+	tool.SchemaVersion = version
+	server.tools.Store(name, &toolEntry{tool: &tool, handler: entry.handler, reg: entry.reg})
+	server.toolsChanged()
+	return nil
 }
 
 type promptEntry struct {
@@ -182,24 +614,37 @@ type promptEntry struct {
 
 type PromptHandlerFunc func(context.Context, *protocol.GetPromptRequest) (*protocol.GetPromptResult, error)
 
-func (server *Server) RegisterPrompt(prompt *protocol.Prompt, promptHandler PromptHandlerFunc) {
-	server.prompts.Store(prompt.Name, &promptEntry{prompt: prompt, handler: promptHandler})
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4PromptListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification prompt list changes fail: %v", err)
-			return
+// RegisterPrompt registers the prompt, a prompt already registered with the same name is replaced and a warning is logged.
+func (server *Server) RegisterPrompt(prompt *protocol.Prompt, promptHandler PromptHandlerFunc, middlewares ...PromptMiddleware) {
+	_ = server.registerPrompt(prompt, promptHandler, middlewares, true)
+}
+
+// RegisterPromptErr is like RegisterPrompt but rejects the registration with pkg.ErrAlreadyRegistered
+// if a prompt with the same name is already registered.
+func (server *Server) RegisterPromptErr(prompt *protocol.Prompt, promptHandler PromptHandlerFunc, middlewares ...PromptMiddleware) error {
+	return server.registerPrompt(prompt, promptHandler, middlewares, false)
+}
+
+func (server *Server) registerPrompt(prompt *protocol.Prompt, promptHandler PromptHandlerFunc, middlewares []PromptMiddleware, replace bool) error {
+	promptHandler = server.buildPromptMiddlewareChain(promptHandler, middlewares)
+
+	entry := &promptEntry{prompt: prompt, handler: promptHandler}
+	if _, loaded := server.prompts.LoadOrStore(prompt.Name, entry); loaded {
+		if !replace {
+			return fmt.Errorf("%w: promptName=%s", pkg.ErrAlreadyRegistered, prompt.Name)
 		}
+		server.logger.Warn("prompt is already registered, replacing it", "prompt", prompt.Name)
+		server.prompts.Store(prompt.Name, entry)
 	}
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "prompts"})
+	server.NotifyPromptListChanged()
+	return nil
 }
 
 func (server *Server) UnregisterPrompt(name string) {
 	server.prompts.Delete(name)
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4PromptListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification prompt list changes fail: %v", err)
-			return
-		}
-	}
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "prompts"})
+	server.NotifyPromptListChanged()
 }
 
 type resourceEntry struct {
@@ -209,24 +654,37 @@ type resourceEntry struct {
 
 type ResourceHandlerFunc func(context.Context, *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error)
 
-func (server *Server) RegisterResource(resource *protocol.Resource, resourceHandler ResourceHandlerFunc) {
-	server.resources.Store(resource.URI, &resourceEntry{resource: resource, handler: resourceHandler})
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4ResourceListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification resource list changes fail: %v", err)
-			return
+// RegisterResource registers the resource, a resource already registered with the same uri is replaced and a warning is logged.
+func (server *Server) RegisterResource(resource *protocol.Resource, resourceHandler ResourceHandlerFunc, middlewares ...ResourceMiddleware) {
+	_ = server.registerResource(resource, resourceHandler, middlewares, true)
+}
+
+// RegisterResourceErr is like RegisterResource but rejects the registration with pkg.ErrAlreadyRegistered
+// if a resource with the same uri is already registered.
+func (server *Server) RegisterResourceErr(resource *protocol.Resource, resourceHandler ResourceHandlerFunc, middlewares ...ResourceMiddleware) error {
+	return server.registerResource(resource, resourceHandler, middlewares, false)
+}
+
+func (server *Server) registerResource(resource *protocol.Resource, resourceHandler ResourceHandlerFunc, middlewares []ResourceMiddleware, replace bool) error {
+	resourceHandler = server.buildResourceMiddlewareChain(resourceHandler, middlewares)
+
+	entry := &resourceEntry{resource: resource, handler: resourceHandler}
+	if _, loaded := server.resources.LoadOrStore(resource.URI, entry); loaded {
+		if !replace {
+			return fmt.Errorf("%w: resourceURI=%s", pkg.ErrAlreadyRegistered, resource.URI)
 		}
+		server.logger.Warn("resource is already registered, replacing it", "uri", resource.URI)
+		server.resources.Store(resource.URI, entry)
 	}
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "resources"})
+	server.NotifyResourceListChanged()
+	return nil
 }
 
 func (server *Server) UnregisterResource(uri string) {
 	server.resources.Delete(uri)
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4ResourceListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification resource list changes fail: %v", err)
-			return
-		}
-	}
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "resources"})
+	server.NotifyResourceListChanged()
 }
 
 type resourceTemplateEntry struct {
@@ -234,34 +692,168 @@ type resourceTemplateEntry struct {
 	handler          ResourceHandlerFunc
 }
 
-func (server *Server) RegisterResourceTemplate(resource *protocol.ResourceTemplate, resourceHandler ResourceHandlerFunc) error {
+func (server *Server) RegisterResourceTemplate(resource *protocol.ResourceTemplate, resourceHandler ResourceHandlerFunc,
+	middlewares ...ResourceMiddleware,
+) error { //nolint:whitespace
 	if err := resource.ParseURITemplate(); err != nil {
 		return err
 	}
+	resourceHandler = server.buildResourceMiddlewareChain(resourceHandler, middlewares)
 	server.resourceTemplates.Store(resource.URITemplate, &resourceTemplateEntry{resourceTemplate: resource, handler: resourceHandler})
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4ResourceListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification resource list changes fail: %v", err)
-			return nil
-		}
-	}
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "resources"})
+	server.NotifyResourceListChanged()
 	return nil
 }
 
 func (server *Server) UnregisterResourceTemplate(uriTemplate string) {
 	server.resourceTemplates.Delete(uriTemplate)
-	if !server.sessionManager.IsEmpty() {
-		if err := server.sendNotification4ResourceListChanges(context.Background()); err != nil {
-			server.logger.Warnf("send notification resource list changes fail: %v", err)
-			return
-		}
-	}
+	server.emitEvent(ServerEvent{Type: EventCapabilityChanged, Capability: "resources"})
+	server.NotifyResourceListChanged()
 }
 
+// Use registers global tool middlewares. They run in registration order: the first registered is the outermost,
+// it runs first before the handler and last after it, so e.g. recovery should be registered before auth.
+// Successive calls accumulate, global middlewares wrap the middlewares passed to RegisterTool and
+// only apply to tools registered afterward.
 func (server *Server) Use(middlewares ...ToolMiddleware) {
 	server.globalMiddlewares = append(server.globalMiddlewares, middlewares...)
 }
 
+// ResourceMiddleware defines the middleware type of the resource handler, it applies to resources and resource templates
+type ResourceMiddleware func(ResourceHandlerFunc) ResourceHandlerFunc
+
+// PromptMiddleware defines the middleware type of the prompt handler
+type PromptMiddleware func(PromptHandlerFunc) PromptHandlerFunc
+
+// UseResourceMiddleware registers global resource middlewares, like Use the first registered runs outermost.
+// They only apply to resources registered afterward.
+func (server *Server) UseResourceMiddleware(middlewares ...ResourceMiddleware) {
+	server.globalResourceMiddlewares = append(server.globalResourceMiddlewares, middlewares...)
+}
+
+// UsePromptMiddleware registers global prompt middlewares, like Use the first registered runs outermost.
+// They only apply to prompts registered afterward.
+func (server *Server) UsePromptMiddleware(middlewares ...PromptMiddleware) {
+	server.globalPromptMiddlewares = append(server.globalPromptMiddlewares, middlewares...)
+}
+
+func (server *Server) buildResourceMiddlewareChain(handler ResourceHandlerFunc, middlewares []ResourceMiddleware) ResourceHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	for i := len(server.globalResourceMiddlewares) - 1; i >= 0; i-- {
+		handler = server.globalResourceMiddlewares[i](handler)
+	}
+	return handler
+}
+
+func (server *Server) buildPromptMiddlewareChain(handler PromptHandlerFunc, middlewares []PromptMiddleware) PromptHandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	for i := len(server.globalPromptMiddlewares) - 1; i >= 0; i-- {
+		handler = server.globalPromptMiddlewares[i](handler)
+	}
+	return handler
+}
+
+// ResultTransformer post-processes a successful tool call result, e.g. redaction or citation normalization.
+// Unlike ToolMiddleware, transformers only see results and are applied to every tool.
+type ResultTransformer func(ctx context.Context, req *protocol.CallToolRequest, result *protocol.CallToolResult) (*protocol.CallToolResult, error)
+
+// AddResultTransformer appends transformers to the result pipeline, they are applied in the order they were added.
+func (server *Server) AddResultTransformer(transformers ...ResultTransformer) {
+	server.resultTransformers = append(server.resultTransformers, transformers...)
+}
+
+func (server *Server) transformResult(ctx context.Context, req *protocol.CallToolRequest, result *protocol.CallToolResult) (*protocol.CallToolResult, error) {
+	if result == nil || result.IsError {
+		return result, nil
+	}
+
+	var err error
+	for _, transformer := range server.resultTransformers {
+		if result, err = transformer(ctx, req, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// WithContentDeduplication drops content blocks identical to an earlier block of the same tool call result,
+// keeping the order of the first occurrences
+func WithContentDeduplication() Option {
+	return func(s *Server) {
+		s.resultTransformers = append(s.resultTransformers, deduplicateContent)
+	}
+}
+
+func deduplicateContent(_ context.Context, _ *protocol.CallToolRequest, result *protocol.CallToolResult) (*protocol.CallToolResult, error) {
+	if len(result.Content) < 2 {
+		return result, nil
+	}
+
+	seen := make(map[string]struct{}, len(result.Content))
+	contents := make([]protocol.Content, 0, len(result.Content))
+	for _, content := range result.Content {
+		b, err := json.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[string(b)]; ok {
+			continue
+		}
+		seen[string(b)] = struct{}{}
+		contents = append(contents, content)
+	}
+	result.Content = contents
+	return result, nil
+}
+
+// WithStructuredTextConsistency keeps the text content of tool results consistent with their structured content:
+// a result with structured content but no text content gains a text block with its JSON rendering, a result whose
+// text content does not include the JSON rendering of its structured content fails with pkg.ErrStructuredContentMismatch.
+func WithStructuredTextConsistency() Option {
+	return func(s *Server) {
+		s.resultTransformers = append(s.resultTransformers, ensureStructuredTextConsistency)
+	}
+}
+
+func ensureStructuredTextConsistency(_ context.Context, _ *protocol.CallToolRequest, result *protocol.CallToolResult) (*protocol.CallToolResult, error) {
+	if result.StructuredContent == nil {
+		return result, nil
+	}
+
+	structured, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return nil, err
+	}
+	var want interface{}
+	if err = pkg.JSONUnmarshal(structured, &want); err != nil {
+		return nil, err
+	}
+
+	hasText := false
+	for _, content := range result.Content {
+		text, ok := content.(*protocol.TextContent)
+		if !ok {
+			continue
+		}
+		hasText = true
+		// the rendering may differ in formatting, e.g. indentation or key order
+		var got interface{}
+		if json.Unmarshal([]byte(text.Text), &got) == nil && reflect.DeepEqual(got, want) {
+			return result, nil
+		}
+	}
+	if hasText {
+		return nil, fmt.Errorf("%w: structuredContent=%s", pkg.ErrStructuredContentMismatch, structured)
+	}
+
+	result.Content = append(result.Content, &protocol.TextContent{Type: "text", Text: string(structured)})
+	return result, nil
+}
+
 func (server *Server) buildMiddlewareChain(finalHandler ToolHandlerFunc) ToolHandlerFunc {
 	if len(server.globalMiddlewares) == 0 {
 		return finalHandler
@@ -274,14 +866,36 @@ func (server *Server) buildMiddlewareChain(finalHandler ToolHandlerFunc) ToolHan
 	return handler
 }
 
+// Shutdown gracefully shuts down the server, it is safe to call concurrently and more than once.
+// Only the first call drains in-flight requests and shuts the transport down, every caller
+// observes its result unless its own context is done first.
 func (server *Server) Shutdown(userCtx context.Context) error {
+	first := false
+	server.shutdownOnce.Do(func() {
+		first = true
+	})
+	if first {
+		server.shutdownErr = server.shutdown(userCtx)
+		close(server.shutdownDone)
+		return server.shutdownErr
+	}
+
+	select {
+	case <-server.shutdownDone:
+		return server.shutdownErr
+	case <-userCtx.Done():
+		return userCtx.Err()
+	}
+}
+
+func (server *Server) shutdown(userCtx context.Context) error {
 	server.inShutdown.Store(true)
 
 	serverCtx, cancel := context.WithCancel(userCtx)
 	defer cancel()
 
 	go func() {
-		defer pkg.Recover()
+		defer server.recoverPanic()
 
 		server.inFlyRequest.Wait()
 		cancel()
@@ -297,7 +911,7 @@ func (server *Server) sessionDetection(ctx context.Context, sessionID string) er
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, server.keepAliveTimeout)
 	defer cancel()
 
 	if _, err := server.Ping(setSessionIDToCtx(ctx, sessionID), protocol.NewPingRequest()); err != nil {
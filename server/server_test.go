@@ -13,8 +13,10 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/hhfgeg/go-mcp/client"
 	"github.com/hhfgeg/go-mcp/pkg"
 	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
 	"github.com/hhfgeg/go-mcp/transport"
 )
 
@@ -484,7 +486,7 @@ func testServerInit(t *testing.T, server *Server, in io.Writer, outScan *bufio.S
 
 	expectedResp := protocol.NewJSONRPCSuccessResponse(uuid, protocol.InitializeResult{
 		ProtocolVersion: protocol.Version,
-		Capabilities:    server.capabilities,
+		Capabilities:    server.advertisedCapabilities(),
 		ServerInfo:      server.serverInfo,
 	})
 	expectedRespBytes, err := json.Marshal(expectedResp)
@@ -890,7 +892,7 @@ func testServerRateLimiter(t *testing.T, tt testLimiter) {
 			errorObj, ok := errObj.(map[string]interface{})
 			if ok {
 				// Check if it's a rate limit error
-				if code, codeExists := errorObj["code"].(float64); codeExists && code == float64(-32603) {
+				if code, codeExists := errorObj["code"].(float64); codeExists && code == float64(protocol.RateLimitExceeded) {
 					errorCount++
 				}
 			}
@@ -1003,3 +1005,99 @@ func TestNoMiddleware(t *testing.T) {
 	}
 	s.RegisterTool(testTool, testHandler)
 }
+
+// newTestServer creates a server over a mock transport and starts it,
+// the returned writer and scanner are the client side of the connection.
+func newTestServer(t *testing.T, opts ...Option) (*Server, io.WriteCloser, *bufio.Scanner) {
+	reader1, writer1 := io.Pipe()
+	reader2, writer2 := io.Pipe()
+
+	opts = append([]Option{WithServerInfo(protocol.Implementation{
+		Name:    "ExampleServer",
+		Version: "1.0.0",
+	})}, opts...)
+
+	server, err := NewServer(transport.NewMockServerTransport(reader1, writer2), opts...)
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+
+	go func() {
+		if err := server.Run(); err != nil {
+			t.Errorf("server start: %+v", err)
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = writer1.Close()
+		_ = reader2.Close()
+	})
+
+	return server, writer1, bufio.NewScanner(reader2)
+}
+
+func testWriteRequest(t *testing.T, in io.Writer, id protocol.RequestID, method protocol.Method, params interface{}) {
+	reqBytes, err := json.Marshal(protocol.NewJSONRPCRequest(id, method, params))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if _, err = in.Write(append(reqBytes, "\n"...)); err != nil {
+		t.Fatalf("in Write: %+v", err)
+	}
+}
+
+func testReadMessage(t *testing.T, outScan *bufio.Scanner) map[string]interface{} {
+	if !outScan.Scan() {
+		t.Fatalf("outScan: %+v", outScan.Err())
+	}
+
+	var msg map[string]interface{}
+	if err := pkg.JSONUnmarshal(outScan.Bytes(), &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// newTestServerAndClient connects a started server and an initialized client over mock transports,
+// the returned session id is the one the server allocated for the client.
+func newTestServerAndClient(t *testing.T, serverOpts []Option, clientOpts []client.Option, setup func(*Server)) (*Server, *client.Client, string) {
+	c2sReader, c2sWriter := io.Pipe()
+	s2cReader, s2cWriter := io.Pipe()
+
+	serverOpts = append([]Option{WithServerInfo(protocol.Implementation{
+		Name:    "ExampleServer",
+		Version: "1.0.0",
+	})}, serverOpts...)
+
+	server, err := NewServer(transport.NewMockServerTransport(c2sReader, s2cWriter), serverOpts...)
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	if setup != nil {
+		setup(server)
+	}
+
+	go func() {
+		if err := server.Run(); err != nil {
+			t.Errorf("server start: %+v", err)
+		}
+	}()
+
+	mcpClient, err := client.NewClient(transport.NewMockClientTransport(s2cReader, c2sWriter), clientOpts...)
+	if err != nil {
+		t.Fatalf("NewClient: %+v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = mcpClient.Close()
+		_ = c2sWriter.Close()
+		_ = s2cWriter.Close()
+	})
+
+	var sessionID string
+	server.sessionManager.RangeSessions(func(id string, _ *session.State) bool {
+		sessionID = id
+		return false
+	})
+	return server, mcpClient, sessionID
+}
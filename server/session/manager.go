@@ -19,6 +19,11 @@ type Manager struct {
 
 	detection   func(ctx context.Context, sessionID string) error
 	maxIdleTime time.Duration
+
+	heartbeatInterval time.Duration // 0 disables the detection, idle sessions are still cleaned every minute
+
+	onSessionCreated func(sessionID string)
+	onSessionClosed  func(sessionID string)
 }
 
 func NewManager(detection func(ctx context.Context, sessionID string) error, genSessionID func(ctx context.Context) string) *Manager {
@@ -27,6 +32,8 @@ func NewManager(detection func(ctx context.Context, sessionID string) error, gen
 		detection:     detection,
 		stopHeartbeat: make(chan struct{}),
 		logger:        pkg.DefaultLogger,
+
+		heartbeatInterval: time.Minute,
 	}
 }
 
@@ -34,14 +41,32 @@ func (m *Manager) SetMaxIdleTime(d time.Duration) {
 	m.maxIdleTime = d
 }
 
+// SetHeartbeatInterval sets the interval sessions are detected at, 0 disables the detection
+func (m *Manager) SetHeartbeatInterval(d time.Duration) {
+	m.heartbeatInterval = d
+}
+
 func (m *Manager) SetLogger(logger pkg.Logger) {
 	m.logger = logger
 }
 
+// SetOnSessionCreated sets the callback invoked after a session is created
+func (m *Manager) SetOnSessionCreated(f func(sessionID string)) {
+	m.onSessionCreated = f
+}
+
+// SetOnSessionClosed sets the callback invoked after a session is closed
+func (m *Manager) SetOnSessionClosed(f func(sessionID string)) {
+	m.onSessionClosed = f
+}
+
 func (m *Manager) CreateSession(ctx context.Context) string {
 	sessionID := m.genSessionID(ctx)
 	state := NewState()
 	m.activeSessions.Store(sessionID, state)
+	if m.onSessionCreated != nil {
+		m.onSessionCreated(sessionID)
+	}
 	return sessionID
 }
 
@@ -106,6 +131,9 @@ func (m *Manager) CloseSession(sessionID string) {
 	}
 	state.Close()
 	m.closedSessions.Store(sessionID, struct{}{})
+	if m.onSessionClosed != nil {
+		m.onSessionClosed(sessionID)
+	}
 }
 
 func (m *Manager) CloseAllSessions() {
@@ -117,7 +145,11 @@ func (m *Manager) CloseAllSessions() {
 }
 
 func (m *Manager) StartHeartbeatAndCleanInvalidSessions() {
-	ticker := time.NewTicker(time.Minute)
+	interval := m.heartbeatInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -127,12 +159,16 @@ func (m *Manager) StartHeartbeatAndCleanInvalidSessions() {
 		case <-ticker.C:
 			now := time.Now()
 			m.activeSessions.Range(func(sessionID string, state *State) bool {
-				if m.maxIdleTime != 0 && now.Sub(state.lastActiveAt) > m.maxIdleTime {
+				if m.maxIdleTime != 0 && now.Sub(state.getLastActiveAt()) > m.maxIdleTime {
 					m.logger.Infof("session expire, session id: %v", sessionID)
 					m.CloseSession(sessionID)
 					return true
 				}
 
+				if m.heartbeatInterval <= 0 {
+					return true
+				}
+
 				var err error
 				for i := 0; i < 3; i++ {
 					if err = m.detection(context.Background(), sessionID); err == nil {
@@ -155,6 +191,13 @@ func (m *Manager) RangeSessions(f func(sessionID string, state *State) bool) {
 	m.activeSessions.Range(f)
 }
 
+// RangeSessionIDs calls f with the id of every active session until f returns false
+func (m *Manager) RangeSessionIDs(f func(sessionID string) bool) {
+	m.activeSessions.Range(func(sessionID string, _ *State) bool {
+		return f(sessionID)
+	})
+}
+
 func (m *Manager) IsEmpty() bool {
 	isEmpty := true
 	m.activeSessions.Range(func(string, *State) bool {
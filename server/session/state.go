@@ -15,8 +15,20 @@ import (
 
 var ErrQueueNotOpened = errors.New("queue has not been opened")
 
+// InitPhase is the phase of the initialization handshake a session is in
+type InitPhase int32
+
+const (
+	// InitPhaseUninitialized is the phase of a session that has not received the initialize request yet
+	InitPhaseUninitialized InitPhase = iota
+	// InitPhaseInitializing is the phase between the initialize request and the initialized notification
+	InitPhaseInitializing
+	// InitPhaseReady is the phase of a session that received the initialized notification
+	InitPhaseReady
+)
+
 type State struct {
-	lastActiveAt time.Time
+	lastActiveAt int64 // unix nano, accessed atomically as concurrent requests of the session update it
 
 	mu       sync.RWMutex
 	sendChan chan []byte
@@ -31,22 +43,32 @@ type State struct {
 	clientInfo         *protocol.Implementation
 	clientCapabilities *protocol.ClientCapabilities
 
+	// protocol version negotiated during initialize
+	protocolVersion string
+
 	// subscribed resources
+	subscribeMu         sync.Mutex
 	subscribedResources cmap.ConcurrentMap[string, struct{}]
 
-	receivedInitRequest *pkg.AtomicBool
-	ready               *pkg.AtomicBool
-	closed              *pkg.AtomicBool
+	// values handlers keep for the session, see Store
+	store *Store
+
+	// in-flight handler slots, created on first use
+	handlerSlotsOnce sync.Once
+	handlerSlots     chan struct{}
+
+	initPhase int32 // InitPhase, accessed atomically
+
+	closed *pkg.AtomicBool
 }
 
 func NewState() *State {
 	return &State{
-		lastActiveAt:           time.Now(),
+		lastActiveAt:           time.Now().UnixNano(),
 		serverReqID2respChan:   cmap.New[chan *protocol.JSONRPCResponse](),
 		clientReqID2cancelFunc: cmap.New[context.CancelFunc](),
 		subscribedResources:    cmap.New[struct{}](),
-		receivedInitRequest:    pkg.NewAtomicBool(),
-		ready:                  pkg.NewAtomicBool(),
+		store:                  newStore(),
 		closed:                 pkg.NewAtomicBool(),
 	}
 }
@@ -56,24 +78,51 @@ func (s *State) SetClientInfo(ClientInfo *protocol.Implementation, ClientCapabil
 	s.clientCapabilities = ClientCapabilities
 }
 
+// GetClientInfo returns the client info sent in the initialize request,
+// a zero value is returned if the client did not provide it.
+func (s *State) GetClientInfo() protocol.Implementation {
+	if s.clientInfo == nil {
+		return protocol.Implementation{}
+	}
+	return *s.clientInfo
+}
+
 func (s *State) GetClientCapabilities() *protocol.ClientCapabilities {
 	return s.clientCapabilities
 }
 
-func (s *State) SetReceivedInitRequest() {
-	s.receivedInitRequest.Store(true)
+func (s *State) SetProtocolVersion(version string) {
+	s.protocolVersion = version
 }
 
-func (s *State) GetReceivedInitRequest() bool {
-	return s.receivedInitRequest.Load()
+// GetProtocolVersion returns the protocol version negotiated during initialize
+func (s *State) GetProtocolVersion() string {
+	return s.protocolVersion
+}
+
+// GetInitPhase returns the phase of the initialization handshake the session is in
+func (s *State) GetInitPhase() InitPhase {
+	return InitPhase(atomic.LoadInt32(&s.initPhase))
+}
+
+// BeginInitialize moves an uninitialized session to initializing,
+// it reports false if the session already received an initialize request
+func (s *State) BeginInitialize() bool {
+	return atomic.CompareAndSwapInt32(&s.initPhase, int32(InitPhaseUninitialized), int32(InitPhaseInitializing))
 }
 
-func (s *State) SetReady() {
-	s.ready.Store(true)
+// CompleteInitialize moves an initializing session to ready,
+// it reports false if the session has not received an initialize request or is already ready
+func (s *State) CompleteInitialize() bool {
+	return atomic.CompareAndSwapInt32(&s.initPhase, int32(InitPhaseInitializing), int32(InitPhaseReady))
+}
+
+func (s *State) GetReceivedInitRequest() bool {
+	return s.GetInitPhase() != InitPhaseUninitialized
 }
 
 func (s *State) GetReady() bool {
-	return s.ready.Load()
+	return s.GetInitPhase() == InitPhaseReady
 }
 
 func (s *State) IncRequestID() int64 {
@@ -92,11 +141,50 @@ func (s *State) GetSubscribedResources() cmap.ConcurrentMap[string, struct{}] {
 	return s.subscribedResources
 }
 
+// SubscribeResource records the subscription of uri, limit is the maximum number of
+// subscriptions allowed for the session (0 means no limit). Re-subscribing an already
+// subscribed uri always succeeds. It returns false if the limit has been reached.
+func (s *State) SubscribeResource(uri string, limit int) bool {
+	s.subscribeMu.Lock()
+	defer s.subscribeMu.Unlock()
+
+	if limit > 0 && !s.subscribedResources.Has(uri) && s.subscribedResources.Count() >= limit {
+		return false
+	}
+	s.subscribedResources.Set(uri, struct{}{})
+	return true
+}
+
+// AcquireHandlerSlot blocks until the session has fewer than limit in-flight handlers (0 means no limit)
+// or ctx is done. The returned function releases the slot.
+func (s *State) AcquireHandlerSlot(ctx context.Context, limit int) (func(), error) {
+	if limit <= 0 {
+		return func() {}, nil
+	}
+
+	s.handlerSlotsOnce.Do(func() {
+		s.handlerSlots = make(chan struct{}, limit)
+	})
+
+	select {
+	case s.handlerSlots <- struct{}{}:
+		return func() { <-s.handlerSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// GetStore returns the key/value store of the session, it is emptied when the session is closed
+func (s *State) GetStore() *Store {
+	return s.store
+}
+
 func (s *State) Close() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.closed.Store(true)
+	s.store.clear()
 
 	if s.sendChan != nil {
 		close(s.sendChan)
@@ -104,7 +192,11 @@ func (s *State) Close() {
 }
 
 func (s *State) updateLastActiveAt() {
-	s.lastActiveAt = time.Now()
+	atomic.StoreInt64(&s.lastActiveAt, time.Now().UnixNano())
+}
+
+func (s *State) getLastActiveAt() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&s.lastActiveAt))
 }
 
 func (s *State) openMessageQueueForSend() {
@@ -144,6 +236,11 @@ func (s *State) dequeueMessage(ctx context.Context) ([]byte, error) {
 	}
 	s.mu.RUnlock()
 
+	// a closed stream must not take a message off the queue, it stays buffered for the next stream
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -0,0 +1,43 @@
+package session
+
+import (
+	cmap "github.com/orcaman/concurrent-map/v2"
+)
+
+// Store holds values handlers keep for the lifetime of a session, such as the authenticated principal
+// or cached roots. It is safe for concurrent use. A nil Store holds nothing and ignores Set.
+type Store struct {
+	values cmap.ConcurrentMap[string, interface{}]
+}
+
+func newStore() *Store {
+	return &Store{values: cmap.New[interface{}]()}
+}
+
+// Get returns the value stored under key
+func (s *Store) Get(key string) (interface{}, bool) {
+	if s == nil {
+		return nil, false
+	}
+	return s.values.Get(key)
+}
+
+// Set stores value under key, replacing any previous value
+func (s *Store) Set(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.values.Set(key, value)
+}
+
+// Delete removes the value stored under key
+func (s *Store) Delete(key string) {
+	if s == nil {
+		return
+	}
+	s.values.Remove(key)
+}
+
+func (s *Store) clear() {
+	s.values.Clear()
+}
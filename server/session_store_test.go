@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
+)
+
+func TestSessionStore(t *testing.T) {
+	const calls = 20
+
+	server, in, outScan := newTestServer(t)
+
+	loginTool := protocol.NewToolWithInputSchema("login", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(loginTool, func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		SessionStore(ctx).Set(fmt.Sprint("user", req.Arguments["n"]), req.Arguments["n"])
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+	countTool := protocol.NewToolWithInputSchema("count", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(countTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		n := 0
+		for i := 0; i < calls; i++ {
+			if _, ok := SessionStore(ctx).Get(fmt.Sprint("user", i)); ok {
+				n++
+			}
+		}
+		return protocol.NewResultBuilder().AddText(fmt.Sprint(n)).Build(), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	// handlers of the session run concurrently
+	for i := 0; i < calls; i++ {
+		testWriteRequest(t, in, i, protocol.ToolsCall, protocol.NewCallToolRequest(loginTool.Name, map[string]interface{}{"n": i}))
+	}
+	for i := 0; i < calls; i++ {
+		testReadMessage(t, outScan)
+	}
+
+	testWriteRequest(t, in, "count", protocol.ToolsCall, protocol.NewCallToolRequest(countTool.Name, map[string]interface{}{}))
+	resp := testReadMessage(t, outScan)
+	content := resp["result"].(map[string]interface{})["content"].([]interface{})
+	if text := content[0].(map[string]interface{})["text"]; text != fmt.Sprint(calls) {
+		t.Fatalf("expected %d stored values, got %v", calls, text)
+	}
+
+	var sessionID string
+	var store *session.Store
+	server.sessionManager.RangeSessions(func(id string, state *session.State) bool {
+		sessionID, store = id, state.GetStore()
+		return false
+	})
+	server.sessionManager.CloseSession(sessionID)
+	if _, ok := store.Get("user0"); ok {
+		t.Fatal("expected the store to be emptied when the session is closed")
+	}
+
+	if store := SessionStore(context.Background()); store != nil {
+		t.Fatal("expected no store outside of a session")
+	}
+}
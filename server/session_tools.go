@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// WithSessionScopedTools presents additional tools to some sessions only, e.g. tools of the tenant of the
+// authenticated principal in a process serving many tenants. resolve is called with the context of the first
+// tools/list or tools/call request of a session, which carries the values set by the transport's authentication,
+// and returns registrations made with NewToolRegistration. The result is cached for the lifetime of the session,
+// outside of a session, e.g. in stateless mode, it is resolved for each request.
+// Global tools remain available to all sessions, a session scoped tool shadows a global tool with the same name.
+func WithSessionScopedTools(resolve func(ctx context.Context) []*ToolRegistration) Option {
+	return func(s *Server) {
+		s.sessionScopedTools = resolve
+	}
+}
+
+// sessionToolSet is the set of session scoped tools of a session, resolved once
+type sessionToolSet struct {
+	once  sync.Once
+	tools []*sessionTool
+}
+
+type sessionTool struct {
+	reg     *ToolRegistration
+	handler ToolHandlerFunc // the handler of the registration wrapped by the global middlewares
+}
+
+// entry returns the current tool of the registration with the wrapped handler
+func (t *sessionTool) entry() *toolEntry {
+	entry := t.reg.scoped.Load().(*toolEntry)
+	return &toolEntry{tool: entry.tool, handler: t.handler, reg: t.reg}
+}
+
+// sessionToolsOf returns the session scoped tools of the session in ctx, nil if WithSessionScopedTools is not used
+func (server *Server) sessionToolsOf(ctx context.Context) []*sessionTool {
+	if server.sessionScopedTools == nil {
+		return nil
+	}
+
+	sessionID, err := GetSessionIDFromCtx(ctx)
+	if err != nil {
+		return server.resolveSessionTools(ctx)
+	}
+	if _, ok := server.sessionManager.GetSession(sessionID); !ok {
+		// not cached, the set would outlive the session
+		return server.resolveSessionTools(ctx)
+	}
+	set, _ := server.sessionTools.LoadOrStore(sessionID, &sessionToolSet{})
+	set.once.Do(func() {
+		set.tools = server.resolveSessionTools(ctx)
+	})
+	return set.tools
+}
+
+func (server *Server) resolveSessionTools(ctx context.Context) []*sessionTool {
+	tools := make([]*sessionTool, 0)
+	for _, reg := range server.sessionScopedTools(ctx) {
+		if reg == nil || reg.server != nil {
+			server.logger.Warn("session scoped tools must be created by NewToolRegistration, skipping a registered tool")
+			continue
+		}
+		entry := reg.scoped.Load().(*toolEntry)
+		tools = append(tools, &sessionTool{reg: reg, handler: server.buildMiddlewareChain(entry.handler)})
+	}
+	return tools
+}
+
+// lookupTool returns the tool named name available to the session in ctx
+func (server *Server) lookupTool(ctx context.Context, name string) (*toolEntry, bool) {
+	for _, t := range server.sessionToolsOf(ctx) {
+		if t.reg.name == name {
+			return t.entry(), true
+		}
+	}
+	return server.tools.Load(name)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+type tenantKey struct{}
+
+func TestSessionScopedTools(t *testing.T) {
+	textHandler := func(text string) ToolHandlerFunc {
+		return func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: text}}, false), nil
+		}
+	}
+	schema := protocol.InputSchema{Type: protocol.Object}
+	tenantTools := map[string][]*ToolRegistration{
+		"a": {NewToolRegistration(protocol.NewToolWithInputSchema("report", "", schema), textHandler("report of a"))},
+		"b": {
+			NewToolRegistration(protocol.NewToolWithInputSchema("billing", "", schema), textHandler("billing of b")),
+			NewToolRegistration(protocol.NewToolWithInputSchema("echo", "", schema), textHandler("echo of b")),
+		},
+	}
+
+	var resolved int32
+	server, _, _ := newTestServer(t, WithSessionScopedTools(func(ctx context.Context) []*ToolRegistration {
+		atomic.AddInt32(&resolved, 1)
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenantTools[tenant]
+	}))
+	server.RegisterTool(protocol.NewToolWithInputSchema("echo", "", schema), textHandler("echo"))
+
+	sessionCtx := func(tenant string) context.Context {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		return setSessionIDToCtx(ctx, server.sessionManager.CreateSession(ctx))
+	}
+	listNames := func(ctx context.Context) []string {
+		result, err := server.handleRequestWithListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("list tools: %+v", err)
+		}
+		names := make([]string, 0, len(result.Tools))
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	callText := func(ctx context.Context, name string) (string, error) {
+		raw, _ := json.Marshal(protocol.NewCallToolRequest(name, map[string]interface{}{}))
+		result, err := server.handleRequestWithCallTool(ctx, raw)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(*protocol.TextContent).Text, nil
+	}
+
+	ctxA, ctxB := sessionCtx("a"), sessionCtx("b")
+	if names := listNames(ctxA); len(names) != 2 || names[0] != "echo" || names[1] != "report" {
+		t.Fatalf("expected the global and tenant a tools, got %v", names)
+	}
+	if names := listNames(ctxB); len(names) != 2 || names[0] != "billing" || names[1] != "echo" {
+		t.Fatalf("expected the tenant b tools, got %v", names)
+	}
+
+	if text, err := callText(ctxA, "echo"); err != nil || text != "echo" {
+		t.Fatalf("expected the global echo tool for tenant a, got %q, %v", text, err)
+	}
+	if text, err := callText(ctxB, "echo"); err != nil || text != "echo of b" {
+		t.Fatalf("expected tenant b's echo tool to shadow the global one, got %q, %v", text, err)
+	}
+	if _, err := callText(ctxA, "billing"); !errors.Is(err, pkg.ErrMethodNotSupport) {
+		t.Fatalf("expected tenant a not to see billing, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&resolved); n != 2 {
+		t.Fatalf("expected the tools to be resolved once per session, resolved %d times", n)
+	}
+
+	tenantTools["a"][0].Disable()
+	if names := listNames(ctxA); len(names) != 1 || names[0] != "echo" {
+		t.Fatalf("expected disabled session tool to be hidden, got %v", names)
+	}
+}
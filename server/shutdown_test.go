@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/server/session"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+func TestRejectToolCallDuringShutdown(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	testTool := protocol.NewToolWithInputSchema("slow_tool", "slow tool", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		close(started)
+		<-release
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: "done"}}, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.CallToolRequest{Name: testTool.Name})
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(context.Background())
+	}()
+	for !server.inShutdown.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	var sessionID string
+	server.sessionManager.RangeSessions(func(id string, _ *session.State) bool {
+		sessionID = id
+		return false
+	})
+
+	reqBytes, err := json.Marshal(protocol.NewJSONRPCRequest(2, protocol.ToolsCall, protocol.CallToolRequest{Name: testTool.Name}))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	ch, err := server.receive(context.Background(), sessionID, reqBytes)
+	if err != nil {
+		t.Fatalf("receive: %+v", err)
+	}
+
+	var resp map[string]interface{}
+	if err = pkg.JSONUnmarshal(<-ch, &resp); err != nil {
+		t.Fatal(err)
+	}
+	errObj, _ := resp["error"].(map[string]interface{})
+	if errObj["code"] != float64(protocol.ServerShuttingDown) {
+		t.Fatalf("expected shutting down error, got %v", resp)
+	}
+	if data, _ := errObj["data"].(map[string]interface{}); data["retryable"] != true {
+		t.Fatalf("expected retryable error data, got %v", errObj["data"])
+	}
+
+	// the in-flight call drains normally
+	close(release)
+	if resp = testReadMessage(t, outScan); resp["result"] == nil || resp["id"] != float64(1) {
+		t.Fatalf("expected in-flight call result, got %v", resp)
+	}
+
+	select {
+	case err = <-shutdownErr:
+		if err != nil {
+			t.Fatalf("Shutdown: %+v", err)
+		}
+	case <-time.After(time.Second * 3):
+		t.Fatal("timeout waiting for shutdown")
+	}
+}
+
+type countingShutdownTransport struct {
+	transport.ServerTransport
+
+	shutdowns int32
+}
+
+func (t *countingShutdownTransport) Shutdown(userCtx context.Context, serverCtx context.Context) error {
+	atomic.AddInt32(&t.shutdowns, 1)
+	// give a concurrent Shutdown call the chance to race with the drain
+	time.Sleep(50 * time.Millisecond)
+	if err := t.ServerTransport.Shutdown(userCtx, serverCtx); err != nil {
+		return err
+	}
+	return errShutdownForTest
+}
+
+var errShutdownForTest = errors.New("shutdown for test")
+
+func TestConcurrentShutdown(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	defer reader.Close()
+
+	trans := &countingShutdownTransport{ServerTransport: transport.NewMockServerTransport(reader, io.Discard)}
+	server, err := NewServer(trans)
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	go func() {
+		_ = server.Run()
+	}()
+	// wait for the transport to be running
+	for server.sessionManager.IsEmpty() {
+		time.Sleep(time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = server.Shutdown(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&trans.shutdowns); n != 1 {
+		t.Fatalf("expected transport to be shut down once, got %d", n)
+	}
+	for i, err := range errs {
+		if !errors.Is(err, errShutdownForTest) {
+			t.Fatalf("Shutdown call %d: expected %v, got %v", i, errShutdownForTest, err)
+		}
+	}
+}
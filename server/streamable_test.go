@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+	"github.com/hhfgeg/go-mcp/transport"
+)
+
+func TestStreamableHTTPStreamReopen(t *testing.T) {
+	tr, handler, err := transport.NewStreamableHTTPServerTransportAndHandler(
+		transport.WithStreamableHTTPServerTransportAndHandlerOptionStateMode(transport.Stateful))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %+v", err)
+	}
+	server, err := NewServer(tr, WithServerInfo(protocol.Implementation{Name: "ExampleServer", Version: "1.0.0"}))
+	if err != nil {
+		t.Fatalf("NewServer: %+v", err)
+	}
+	go func() {
+		if err := server.Run(); err != nil {
+			t.Errorf("server start: %+v", err)
+		}
+	}()
+	// a closed connection means the GET handler of the stream on it has returned
+	closed := make(chan struct{}, 1)
+	httpServer := httptest.NewUnstartedServer(handler.HandleMCP())
+	httpServer.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			select {
+			case closed <- struct{}{}:
+			default:
+			}
+		}
+	}
+	httpServer.Start()
+	t.Cleanup(func() {
+		httpServer.Close()
+		_ = server.Shutdown(context.Background())
+	})
+
+	reqBytes, err := json.Marshal(protocol.NewJSONRPCRequest(1, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version}))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, httpServer.URL, bytes.NewReader(reqBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("initialize: %+v", err)
+	}
+	_ = resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		t.Fatal("expected a session id")
+	}
+
+	openStream := func() (*http.Response, *bufio.Scanner) {
+		req, _ := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Mcp-Session-Id", sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("open stream: %+v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("open stream: status %d", resp.StatusCode)
+		}
+		return resp, bufio.NewScanner(resp.Body)
+	}
+
+	// the client closes the stream but keeps the session
+	stream, _ := openStream()
+	_ = stream.Body.Close()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not observe the closed stream")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err = server.PushClientConfig(sessionID, map[string]any{"step": i}); err != nil {
+			t.Fatalf("PushClientConfig: %+v", err)
+		}
+	}
+
+	stream, scanner := openStream()
+	defer stream.Body.Close()
+	for i := 0; i < 3; {
+		if !scanner.Scan() {
+			t.Fatalf("stream ended before buffered notification %d: %+v", i, scanner.Err())
+		}
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var notify map[string]interface{}
+		if err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &notify); err != nil {
+			t.Fatalf("invalid notification %q: %+v", line, err)
+		}
+		params, _ := notify["params"].(map[string]interface{})
+		config, _ := params["config"].(map[string]interface{})
+		if notify["method"] != string(protocol.NotificationClientConfig) || config["step"] != float64(i) {
+			t.Fatalf("expected buffered notification %d, got %v", i, notify)
+		}
+		i++
+	}
+}
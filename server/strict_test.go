@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestStrictInitialization(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithStrictInitialization())
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testWriteRequest(t, in, 1, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version})
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("initialize: unexpected error %v", resp["error"])
+	}
+
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	resp := testReadMessage(t, outScan)
+	errObj, _ := resp["error"].(map[string]interface{})
+	if errObj["code"] != float64(protocol.InvalidRequest) {
+		t.Fatalf("expected tool call before initialized to be rejected, got %v", resp)
+	}
+
+	notifyBytes, err := json.Marshal(protocol.NewJSONRPCNotification(protocol.NotificationInitialized, nil))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if _, err = in.Write(append(notifyBytes, "\n"...)); err != nil {
+		t.Fatalf("in Write: %+v", err)
+	}
+
+	testWriteRequest(t, in, 3, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	if resp = testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("tool call after initialized: unexpected error %v", resp["error"])
+	}
+}
+
+func TestInitializeHandshake(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	expectInvalidRequest := func(resp map[string]interface{}, msg string) {
+		t.Helper()
+		errObj, _ := resp["error"].(map[string]interface{})
+		if errObj["code"] != float64(protocol.InvalidRequest) {
+			t.Fatalf("expected %s to be rejected, got %v", msg, resp)
+		}
+	}
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	expectInvalidRequest(testReadMessage(t, outScan), "tool call before initialize")
+
+	// ping is answered in every phase
+	testWriteRequest(t, in, 2, protocol.Ping, protocol.NewPingRequest())
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("ping before initialize: unexpected error %v", resp["error"])
+	}
+
+	testWriteRequest(t, in, 3, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version})
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("initialize: unexpected error %v", resp["error"])
+	}
+
+	testWriteRequest(t, in, 4, protocol.Initialize, protocol.InitializeRequest{ProtocolVersion: protocol.Version})
+	expectInvalidRequest(testReadMessage(t, outScan), "a second initialize")
+
+	// without WithStrictInitialization requests are accepted before the initialized notification
+	testWriteRequest(t, in, 5, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("tool call after initialize: unexpected error %v", resp["error"])
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestMaxSubscriptionsPerSession(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithMaxSubscriptionsPerSession(2))
+	testServerInit(t, server, in, outScan)
+
+	uris := []string{"file:///a.txt", "file:///b.txt", "file:///c.txt"}
+	for i, uri := range uris {
+		testWriteRequest(t, in, i, protocol.ResourcesSubscribe, protocol.SubscribeRequest{URI: uri})
+		resp := testReadMessage(t, outScan)
+
+		_, hasErr := resp["error"]
+		if i < 2 && hasErr {
+			t.Fatalf("subscribe %s: unexpected error %v", uri, resp["error"])
+		}
+		if i == 2 && !hasErr {
+			t.Fatalf("subscribe %s: expected subscription limit error, got %v", uri, resp)
+		}
+	}
+
+	// re-subscribing an existing resource does not count against the limit
+	testWriteRequest(t, in, 3, protocol.ResourcesSubscribe, protocol.SubscribeRequest{URI: uris[0]})
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("re-subscribe: unexpected error %v", resp["error"])
+	}
+
+	// existing subscriptions keep receiving updates
+	go func() {
+		if err := server.SendNotification4ResourcesUpdated(context.Background(),
+			&protocol.ResourceUpdatedNotification{URI: uris[1]}); err != nil {
+			t.Errorf("SendNotification4ResourcesUpdated: %+v", err)
+		}
+	}()
+
+	notify := testReadMessage(t, outScan)
+	if notify["method"] != string(protocol.NotificationResourcesUpdated) {
+		t.Fatalf("expected resources updated notification, got %v", notify)
+	}
+	if params, _ := notify["params"].(map[string]interface{}); params["uri"] != uris[1] {
+		t.Fatalf("unexpected notification params: %v", notify["params"])
+	}
+}
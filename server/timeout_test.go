@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestResourceAndToolTimeouts(t *testing.T) {
+	const (
+		resourceTimeout = 50 * time.Millisecond
+		toolTimeout     = 500 * time.Millisecond
+	)
+
+	server, in, outScan := newTestServer(t, WithResourceTimeout(resourceTimeout), WithToolTimeout(toolTimeout))
+
+	server.RegisterResource(&protocol.Resource{Name: "slow", URI: "file:///slow.txt"},
+		func(ctx context.Context, _ *protocol.ReadResourceRequest) (*protocol.ReadResourceResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	slowTool := protocol.NewToolWithInputSchema("slow_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(slowTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		// outlives the resource timeout but not the tool timeout
+		select {
+		case <-time.After(2 * resourceTimeout):
+			return protocol.NewCallToolResult(nil, false), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	start := time.Now()
+	testWriteRequest(t, in, 1, protocol.ResourcesRead, protocol.ReadResourceRequest{URI: "file:///slow.txt"})
+	if resp := testReadMessage(t, outScan); resp["error"] == nil {
+		t.Fatalf("expected slow resource read to be canceled, got %v", resp)
+	}
+	if elapsed := time.Since(start); elapsed >= toolTimeout {
+		t.Fatalf("expected the resource read to be canceled at the resource timeout, took %v", elapsed)
+	}
+
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest(slowTool.Name, map[string]interface{}{}))
+	if resp := testReadMessage(t, outScan); resp["result"] == nil {
+		t.Fatalf("expected slow tool to complete within the tool timeout, got %v", resp)
+	}
+}
+
+func TestLateResultIsNotWritten(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithToolTimeout(50*time.Millisecond))
+
+	returned := make(chan struct{}, 1)
+	lateTool := protocol.NewToolWithInputSchema("late_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(lateTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		defer func() { returned <- struct{}{} }()
+		// ignores the cancellation and succeeds anyway
+		<-ctx.Done()
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: "late"}}, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	// past its deadline the client still expects a response, an error rather than the late result
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(lateTool.Name, map[string]interface{}{}))
+	resp := testReadMessage(t, outScan)
+	if resp["id"] != float64(1) || resp["error"] == nil {
+		t.Fatalf("expected a timeout error instead of the late result, got %v", resp)
+	}
+	<-returned
+
+	// a cancelled request gets no response at all
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest(lateTool.Name, map[string]interface{}{}))
+	time.Sleep(10 * time.Millisecond)
+	notifyBytes, err := json.Marshal(protocol.NewJSONRPCNotification(protocol.NotificationCancelled, protocol.NewCancelledNotification(2, "user abort")))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if _, err = in.Write(append(notifyBytes, "\n"...)); err != nil {
+		t.Fatalf("in Write: %+v", err)
+	}
+	<-returned
+	time.Sleep(20 * time.Millisecond)
+
+	testWriteRequest(t, in, 3, protocol.Ping, protocol.NewPingRequest())
+	if resp = testReadMessage(t, outScan); resp["id"] != float64(3) {
+		t.Fatalf("expected no response to the cancelled request, got %v", resp)
+	}
+}
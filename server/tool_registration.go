@@ -0,0 +1,104 @@
+package server
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// ToolRegistration is the handle of a tool registered with RegisterTool, it allows mutating the tool at runtime.
+// A disabled tool is hidden from tools/list and its calls are answered like those of an unknown tool.
+// Once the tool is unregistered or replaced by another registration with the same name the handle has no effect.
+type ToolRegistration struct {
+	server   *Server // nil for a session scoped registration made with NewToolRegistration
+	name     string
+	disabled *pkg.AtomicBool
+
+	scoped atomic.Value // *toolEntry of a session scoped registration
+}
+
+// NewToolRegistration creates the registration of a tool that is not registered globally, to be presented
+// to some sessions only by the function passed to WithSessionScopedTools. The global middlewares wrap
+// the middlewares passed here as for tools registered with RegisterTool.
+// Disabling or updating a session scoped tool takes effect at once but does not notify clients.
+func NewToolRegistration(tool *protocol.Tool, toolHandler ToolHandlerFunc, middlewares ...ToolMiddleware) *ToolRegistration {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		toolHandler = middlewares[i](toolHandler)
+	}
+
+	r := &ToolRegistration{name: tool.Name, disabled: pkg.NewAtomicBool()}
+	r.scoped.Store(&toolEntry{tool: tool, handler: toolHandler, reg: r})
+	return r
+}
+
+// Name returns the name of the registered tool
+func (r *ToolRegistration) Name() string {
+	return r.name
+}
+
+// Disable hides the tool from clients and notifies them that the tool list changed
+func (r *ToolRegistration) Disable() {
+	r.setDisabled(true)
+}
+
+// Enable makes a disabled tool available again and notifies clients that the tool list changed
+func (r *ToolRegistration) Enable() {
+	r.setDisabled(false)
+}
+
+// Enabled reports whether the tool is listed and callable
+func (r *ToolRegistration) Enabled() bool {
+	return !r.disabled.Load()
+}
+
+func (r *ToolRegistration) setDisabled(disabled bool) {
+	if r.server == nil {
+		r.disabled.Store(disabled)
+		return
+	}
+	if _, ok := r.current(); !ok || r.disabled.Load() == disabled {
+		return
+	}
+	r.disabled.Store(disabled)
+	r.server.toolsChanged()
+}
+
+// UpdateSchema replaces the input schema of the tool and notifies clients that the tool list changed
+func (r *ToolRegistration) UpdateSchema(schema protocol.InputSchema) error {
+	entry, ok := r.current()
+	if !ok {
+		return fmt.Errorf("missing tool, toolName=%s", r.name)
+	}
+
+	// copy the tool, the registered one may be read concurrently by tools/list
+	tool := *entry.tool
+	tool.InputSchema = schema
+	tool.RawInputSchema = nil
+	if r.server == nil {
+		r.scoped.Store(&toolEntry{tool: &tool, handler: entry.handler, reg: r})
+		return nil
+	}
+	r.server.tools.Store(r.name, &toolEntry{tool: &tool, handler: entry.handler, reg: r})
+	if !r.disabled.Load() {
+		r.server.toolsChanged()
+	}
+	return nil
+}
+
+// current returns the registered entry of the tool if it still belongs to this registration
+func (r *ToolRegistration) current() (*toolEntry, bool) {
+	if r.server == nil {
+		return r.scoped.Load().(*toolEntry), true
+	}
+	entry, ok := r.server.tools.Load(r.name)
+	if !ok || entry.reg != r {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (entry *toolEntry) disabled() bool {
+	return entry.reg != nil && entry.reg.disabled.Load()
+}
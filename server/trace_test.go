@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestTraceParentPropagation(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	incoming, err := pkg.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("ParseTraceParent: %v", err)
+	}
+
+	handlerSpan := make(chan pkg.TraceParent, 1)
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		span, err := GetTraceParentFromCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		handlerSpan <- span
+		if _, err = server.Ping(ctx, protocol.NewPingRequest()); err != nil {
+			return nil, err
+		}
+		return protocol.NewCallToolResult(nil, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	request := protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{})
+	request.Meta = map[string]interface{}{protocol.TraceParentKey: incoming.String()}
+	testWriteRequest(t, in, 1, protocol.ToolsCall, request)
+
+	span := <-handlerSpan
+	if span.TraceID != incoming.TraceID {
+		t.Fatalf("expected handler span in trace %x, got %x", incoming.TraceID, span.TraceID)
+	}
+	if span.SpanID == incoming.SpanID {
+		t.Fatal("expected handler span to be a child of the incoming span")
+	}
+
+	// the outbound ping carries the handler span
+	ping := testReadMessage(t, outScan)
+	params, _ := ping["params"].(map[string]interface{})
+	meta, _ := params["_meta"].(map[string]interface{})
+	if meta[protocol.TraceParentKey] != span.String() {
+		t.Fatalf("expected outbound traceparent %s, got %v", span.String(), ping)
+	}
+
+	respBytes, err := json.Marshal(protocol.NewJSONRPCSuccessResponse(ping["id"], protocol.NewPingResult()))
+	if err != nil {
+		t.Fatalf("json Marshal: %+v", err)
+	}
+	if _, err = in.Write(append(respBytes, "\n"...)); err != nil {
+		t.Fatalf("in Write: %+v", err)
+	}
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("unexpected error %v", resp["error"])
+	}
+}
+
+func TestTracer(t *testing.T) {
+	recorder := &recordingTracer{}
+	server, in, outScan := newTestServer(t, WithTracer(recorder))
+
+	incoming, err := pkg.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("ParseTraceParent: %v", err)
+	}
+
+	handlerSpan := make(chan trace.SpanContext, 1)
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(ctx context.Context, _ *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		traceParent, err := GetTraceParentFromCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if sc := trace.SpanContextFromContext(ctx); pkg.TraceParentFromSpanContext(sc) != traceParent {
+			return nil, fmt.Errorf("traceparent %s is not the span %s", traceParent, sc.SpanID())
+		}
+		handlerSpan <- trace.SpanContextFromContext(ctx)
+		return protocol.NewCallToolResult(nil, true), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	request := protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{})
+	request.Meta = map[string]interface{}{protocol.TraceParentKey: incoming.String()}
+	testWriteRequest(t, in, 1, protocol.ToolsCall, request)
+	if resp := testReadMessage(t, outScan); resp["error"] != nil {
+		t.Fatalf("unexpected error %v", resp["error"])
+	}
+	testWriteRequest(t, in, 2, protocol.ResourcesRead, protocol.NewReadResourceRequest("file:///missing"))
+	if resp := testReadMessage(t, outScan); resp["error"] == nil {
+		t.Fatalf("expected an error, got %v", resp)
+	}
+
+	sc := <-handlerSpan
+	spans := recorder.endedSpans()
+	if len(spans) != 3 || spans[0].name != string(protocol.Initialize) {
+		t.Fatalf("expected the initialize, tools/call and resources/read spans, got %d spans", len(spans))
+	}
+	spans = spans[1:]
+
+	call := spans[0]
+	if call.name != string(protocol.ToolsCall) || call.sc.SpanID() != sc.SpanID() {
+		t.Fatalf("expected the handler to run in the tools/call span, got %s", call.name)
+	}
+	if call.parent.TraceID() != incoming.TraceID || call.parent.SpanID() != incoming.SpanID || !call.parent.IsRemote() {
+		t.Fatalf("expected the span to be a child of the incoming traceparent, got %v", call.parent)
+	}
+	attrs := make(map[string]string)
+	for _, attr := range call.attrs {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs["mcp.tool.name"] != testTool.Name || attrs["jsonrpc.request.id"] != "1" {
+		t.Fatalf("unexpected attributes %v", attrs)
+	}
+	if call.status != codes.Error {
+		t.Fatalf("expected the tool error to fail the span, got %v", call.status)
+	}
+
+	read := spans[1]
+	if read.name != string(protocol.ResourcesRead) || read.status != codes.Error || read.parent.IsValid() {
+		t.Fatalf("expected a failed root span, got %s %v", read.name, read.status)
+	}
+}
+
+// recordingTracer records the spans the server starts, so that the tests need no tracing SDK
+type recordingTracer struct {
+	mu     sync.Mutex
+	nextID uint64
+	ended  []*recordedSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	r.mu.Lock()
+	r.nextID++
+	id := r.nextID
+	r.mu.Unlock()
+
+	parent := trace.SpanContextFromContext(ctx)
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		binary.BigEndian.PutUint64(traceID[8:], id)
+	}
+	var spanID trace.SpanID
+	binary.BigEndian.PutUint64(spanID[:], id)
+
+	config := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{
+		tracer: r,
+		name:   name,
+		parent: parent,
+		sc:     trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled}),
+		attrs:  config.Attributes(),
+	}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (r *recordingTracer) endedSpans() []*recordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*recordedSpan(nil), r.ended...)
+}
+
+type recordedSpan struct {
+	trace.Span // the methods the server doesn't call are left unimplemented
+
+	tracer *recordingTracer
+	name   string
+	parent trace.SpanContext
+	sc     trace.SpanContext
+	attrs  []attribute.KeyValue
+	status codes.Code
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *recordedSpan) IsRecording() bool { return true }
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) { s.attrs = append(s.attrs, kv...) }
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.ended = append(s.tracer.ended, s)
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// WithTracer starts a span named after the method for every request the server dispatches. The span is a child
+// of the traceparent the request carries, or of the span of the HTTP request context, and records the request id,
+// the tool name and whether the request failed. Handlers find it with trace.SpanFromContext, GetTraceParentFromCtx
+// returns its span context. Without a tracer no span is created.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(s *Server) {
+		s.tracer = tracer
+	}
+}
+
+// startSpan starts the span of req, it replaces the traceparent of the handler context with the span context
+func (server *Server) startSpan(ctx context.Context, req *protocol.JSONRPCRequest, parent pkg.TraceParent, traced bool) (context.Context, trace.Span) {
+	if traced {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent.SpanContext())
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("mcp.method.name", string(req.Method)),
+		attribute.String("jsonrpc.request.id", fmt.Sprint(req.ID)),
+	}
+	if req.Method == protocol.ToolsCall {
+		attrs = append(attrs, attribute.String("mcp.tool.name", gjson.GetBytes(req.RawParams, "name").String()))
+	}
+	if sessionID, err := GetSessionIDFromCtx(ctx); err == nil {
+		attrs = append(attrs, attribute.String("mcp.session.id", sessionID))
+	}
+
+	ctx, span := server.tracer.Start(ctx, string(req.Method), trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = setTraceParentToCtx(ctx, pkg.TraceParentFromSpanContext(sc))
+	}
+	return ctx, span
+}
+
+// endSpan records the JSON-RPC error of the response if any and ends the span
+func endSpan(span trace.Span, resp *protocol.JSONRPCResponse) {
+	if resp.Error != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", resp.Error.Code))
+		span.SetStatus(codes.Error, resp.Error.Message)
+	}
+	span.End()
+}
+
+// traceToolResult marks the span of a tool call failed if the tool reported an error in its result
+func (server *Server) traceToolResult(ctx context.Context, result *protocol.CallToolResult) {
+	if server.tracer == nil || result == nil || !result.IsError {
+		return
+	}
+	trace.SpanFromContext(ctx).SetStatus(codes.Error, "tool result is an error")
+}
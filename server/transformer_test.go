@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestResultTransformer(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+
+	testTool, err := protocol.NewTool("test_tool", "test_tool", currentTimeReq{})
+	if err != nil {
+		t.Fatalf("NewTool: %+v", err)
+	}
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: "secret password"},
+		}, false), nil
+	})
+
+	server.AddResultTransformer(
+		func(_ context.Context, _ *protocol.CallToolRequest, result *protocol.CallToolResult) (*protocol.CallToolResult, error) {
+			text := result.Content[0].(*protocol.TextContent)
+			text.Text = strings.ReplaceAll(text.Text, "password", "***")
+			return result, nil
+		},
+		func(_ context.Context, _ *protocol.CallToolRequest, result *protocol.CallToolResult) (*protocol.CallToolResult, error) {
+			text := result.Content[0].(*protocol.TextContent)
+			text.Text += " [redacted]"
+			return result, nil
+		},
+	)
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.CallToolRequest{Name: testTool.Name})
+	resp := testReadMessage(t, outScan)
+
+	result, _ := resp["result"].(map[string]interface{})
+	content, _ := result["content"].([]interface{})
+	if len(content) != 1 {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+	if text := content[0].(map[string]interface{})["text"]; text != "secret *** [redacted]" {
+		t.Fatalf("transformers not applied in order, got text %q", text)
+	}
+}
+
+func TestContentDeduplication(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithContentDeduplication())
+
+	testTool := protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(testTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult([]protocol.Content{
+			&protocol.TextContent{Type: "text", Text: "a"},
+			&protocol.TextContent{Type: "text", Text: "b"},
+			&protocol.TextContent{Type: "text", Text: "a"},
+			&protocol.TextContent{Type: "text", Text: "c"},
+		}, false), nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(testTool.Name, map[string]interface{}{}))
+	result, _ := testReadMessage(t, outScan)["result"].(map[string]interface{})
+	contents, _ := result["content"].([]interface{})
+
+	want := []string{"a", "b", "c"}
+	if len(contents) != len(want) {
+		t.Fatalf("expected %d contents, got %v", len(want), contents)
+	}
+	for i, text := range want {
+		if got := contents[i].(map[string]interface{})["text"]; got != text {
+			t.Fatalf("content %d: expected %s, got %v", i, text, got)
+		}
+	}
+}
+
+func TestStructuredTextConsistency(t *testing.T) {
+	server, in, outScan := newTestServer(t, WithStructuredTextConsistency())
+
+	structuredTool := protocol.NewToolWithInputSchema("structured_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(structuredTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return &protocol.CallToolResult{StructuredContent: map[string]interface{}{"temperature": 21.5}}, nil
+	})
+	mismatchTool := protocol.NewToolWithInputSchema("mismatch_tool", "", protocol.InputSchema{Type: protocol.Object})
+	server.RegisterTool(mismatchTool, func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return &protocol.CallToolResult{
+			Content:           []protocol.Content{&protocol.TextContent{Type: "text", Text: `{"temperature": 30}`}},
+			StructuredContent: map[string]interface{}{"temperature": 21.5},
+		}, nil
+	})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest(structuredTool.Name, map[string]interface{}{}))
+	result, _ := testReadMessage(t, outScan)["result"].(map[string]interface{})
+	contents, _ := result["content"].([]interface{})
+	if len(contents) != 1 || contents[0].(map[string]interface{})["text"] != `{"temperature":21.5}` {
+		t.Fatalf("expected a generated JSON text block, got %v", result)
+	}
+
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest(mismatchTool.Name, map[string]interface{}{}))
+	if resp := testReadMessage(t, outScan); resp["error"] == nil {
+		t.Fatalf("expected disagreeing text content to be rejected, got %v", resp)
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestUnknownToolHandler(t *testing.T) {
+	server, in, outScan := newTestServer(t)
+	server.RegisterTool(protocol.NewToolWithInputSchema("test_tool", "", protocol.InputSchema{Type: protocol.Object}),
+		func(context.Context, *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			return protocol.NewCallToolResult(nil, false), nil
+		})
+
+	testServerInit(t, server, in, outScan)
+
+	testWriteRequest(t, in, 1, protocol.ToolsCall, protocol.NewCallToolRequest("unknown_tool", map[string]interface{}{}))
+	resp := testReadMessage(t, outScan)
+	errObj, _ := resp["error"].(map[string]interface{})
+	if errObj["code"] != float64(protocol.MethodNotFound) {
+		t.Fatalf("expected method not found without fallback handler, got %v", resp)
+	}
+
+	server.SetUnknownToolHandler(func(_ context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return protocol.NewCallToolResult([]protocol.Content{&protocol.TextContent{Type: "text", Text: "forwarded " + req.Name}}, false), nil
+	})
+
+	testWriteRequest(t, in, 2, protocol.ToolsCall, protocol.NewCallToolRequest("unknown_tool", map[string]interface{}{}))
+	resp = testReadMessage(t, outScan)
+	result, _ := resp["result"].(map[string]interface{})
+	content, _ := result["content"].([]interface{})
+	if len(content) != 1 || content[0].(map[string]interface{})["text"] != "forwarded unknown_tool" {
+		t.Fatalf("expected the call to be routed to the fallback handler, got %v", resp)
+	}
+}
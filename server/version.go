@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func defaultSupportedProtocolVersions() []string {
+	versions := make([]string, 0, len(protocol.SupportedVersion))
+	for version := range protocol.SupportedVersion {
+		versions = append(versions, version)
+	}
+	return sortVersionsDesc(versions)
+}
+
+// sortVersionsDesc sorts date based protocol versions, e.g. "2025-03-26", newest first
+func sortVersionsDesc(versions []string) []string {
+	sorted := append([]string(nil), versions...)
+	sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	return sorted
+}
+
+// negotiateProtocolVersion picks the highest version both sides speak: the requested version if supported,
+// otherwise the newest supported version older than it, as clients are expected to speak earlier versions.
+func (server *Server) negotiateProtocolVersion(requested string) (string, error) {
+	if requested == "" {
+		return server.supportedProtocolVersions[0], nil
+	}
+	for _, version := range server.supportedProtocolVersions {
+		if version <= requested {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("%w: requested=%s, supported=%v", pkg.ErrUnsupportedProtocolVersion, requested, server.supportedProtocolVersions)
+}
+
+const (
+	defaultServerName    = "go-mcp-server"
+	defaultServerVersion = "0.0.0"
+)
+
+// withDefaultServerInfo fills an unset name with the executable name and an unset version with the main module version
+func withDefaultServerInfo(info protocol.Implementation) *protocol.Implementation {
+	if info.Name == "" {
+		info.Name = defaultServerName
+		if len(os.Args) > 0 && os.Args[0] != "" {
+			info.Name = strings.TrimSuffix(filepath.Base(os.Args[0]), filepath.Ext(os.Args[0]))
+		}
+	}
+	if info.Version == "" {
+		info.Version = defaultServerVersion
+		if buildInfo, ok := debug.ReadBuildInfo(); ok && buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+			info.Version = buildInfo.Main.Version
+		}
+	}
+	return &info
+}
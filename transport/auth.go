@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+// BearerTokenValidator validates the OAuth2 bearer token of an HTTP request.
+// The returned context replaces the request context, so claims or the authenticated principal
+// injected into it are visible to server middlewares and handlers.
+type BearerTokenValidator func(ctx context.Context, token string) (context.Context, error)
+
+// authenticateBearer validates the Authorization header of r, on failure it replies 401 with a
+// WWW-Authenticate challenge (RFC 6750) written by writeError and returns false.
+// The validator's error may carry internal details, it is only logged and never sent to the client.
+func authenticateBearer(w http.ResponseWriter, r *http.Request, validator BearerTokenValidator, logger pkg.Logger,
+	writeError func(w http.ResponseWriter, code int, message string),
+) (*http.Request, bool) {
+	if validator == nil {
+		return r, true
+	}
+
+	const prefix = "bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+		writeError(w, http.StatusUnauthorized, "Missing bearer token")
+		return nil, false
+	}
+
+	ctx, err := validator(r.Context(), strings.TrimSpace(header[len(prefix):]))
+	if err != nil {
+		logger.Warnf("Bearer token validation failed: %v", err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="The access token is invalid"`)
+		writeError(w, http.StatusUnauthorized, "Invalid bearer token")
+		return nil, false
+	}
+	if ctx == nil {
+		ctx = r.Context()
+	}
+	return r.WithContext(ctx), true
+}
@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type principalKey struct{}
+
+func TestBearerTokenValidator(t *testing.T) {
+	validator := func(ctx context.Context, token string) (context.Context, error) {
+		if token != "good" {
+			return nil, errors.New("unknown token")
+		}
+		return context.WithValue(ctx, principalKey{}, "alice"), nil
+	}
+
+	svr, handler, err := NewStreamableHTTPServerTransportAndHandler(
+		WithStreamableHTTPServerTransportAndHandlerOptionBearerTokenValidator(validator))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+	}
+
+	principal := make(chan interface{}, 1)
+	svr.SetReceiver(ServerReceiverF(func(ctx context.Context, _ string, _ []byte) (<-chan []byte, error) {
+		principal <- ctx.Value(principalKey{})
+		return nil, nil
+	}))
+
+	httpSvr := httptest.NewServer(handler.HandleMCP())
+	defer httpSvr.Close()
+
+	post := func(authorization string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, httpSvr.URL, strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantChallenge string
+	}{
+		{name: "missing", authorization: "", wantChallenge: "Bearer"},
+		{name: "invalid", authorization: "Bearer bad", wantChallenge: `Bearer error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		resp := post(tt.authorization)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401, got %d", tt.name, resp.StatusCode)
+		}
+		got := resp.Header.Get("WWW-Authenticate")
+		if !strings.HasPrefix(got, tt.wantChallenge) {
+			t.Fatalf("%s: expected WWW-Authenticate %q, got %q", tt.name, tt.wantChallenge, got)
+		}
+		if strings.Contains(got, "unknown token") {
+			t.Fatalf("%s: expected the validator error to stay on the server, got %q", tt.name, got)
+		}
+	}
+
+	if resp := post("Bearer good"); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	if got := <-principal; got != "alice" {
+		t.Fatalf("expected principal alice in receiver context, got %v", got)
+	}
+}
+
+func TestErrorResponseFormat(t *testing.T) {
+	validator := func(context.Context, string) (context.Context, error) {
+		return nil, errors.New("unknown token")
+	}
+
+	tests := []struct {
+		name            string
+		format          ErrorResponseFormat
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "default",
+			format:          ErrorResponseFormatDefault,
+			wantContentType: "application/json",
+			wantBody:        `{"jsonrpc":"2.0","id":null,"error":{"code":-32603,"message":"Missing bearer token"}}`,
+		},
+		{
+			name:            "json",
+			format:          ErrorResponseFormatJSON,
+			wantContentType: "application/json",
+			wantBody:        `{"error":{"code":401,"message":"Missing bearer token"}}`,
+		},
+		{
+			name:            "plain text",
+			format:          ErrorResponseFormatPlainText,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "Missing bearer token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, handler, err := NewStreamableHTTPServerTransportAndHandler(
+				WithStreamableHTTPServerTransportAndHandlerOptionBearerTokenValidator(validator),
+				WithStreamableHTTPServerTransportAndHandlerOptionErrorResponseFormat(tt.format))
+			if err != nil {
+				t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			rec := httptest.NewRecorder()
+			handler.HandleMCP().ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Fatalf("expected Content-Type %q, got %q", tt.wantContentType, got)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Fatalf("expected body %s, got %s", tt.wantBody, got)
+			}
+		})
+	}
+}
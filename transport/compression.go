@@ -0,0 +1,101 @@
+package transport
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// readRequestBody reads the body of r, with compression enabled a body sent with Content-Encoding: gzip is decoded.
+// A body exceeding maxSize bytes, once decoded, fails with errMessageTooLarge, a non-positive maxSize disables the limit.
+func readRequestBody(compression bool, maxSize int, r *http.Request) ([]byte, error) {
+	if !compression || !strings.EqualFold(strings.TrimSpace(r.Header.Get("Content-Encoding")), "gzip") {
+		return io.ReadAll(newMaxSizeReader(r.Body, int64(maxSize)))
+	}
+
+	gz, err := gzip.NewReader(newMaxSizeReader(r.Body, int64(maxSize)))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(newMaxSizeReader(gz, int64(maxSize)))
+}
+
+// compressResponse wraps w to gzip the response when compression is enabled and the client accepts it,
+// the returned function must be called once the response is complete
+func compressResponse(compression bool, w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	if !compression || !acceptsGzip(r) {
+		return w, func() {}
+	}
+	gw := &gzipResponseWriter{ResponseWriter: w}
+	return gw, gw.close
+}
+
+// acceptsGzip reports whether the client accepts gzip encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding = strings.TrimSpace(encoding)
+		if i := strings.Index(encoding, ";"); i >= 0 {
+			if strings.TrimSpace(encoding[i+1:]) == "q=0" {
+				continue
+			}
+			encoding = strings.TrimSpace(encoding[:i])
+		}
+		if strings.EqualFold(encoding, "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter compresses the response body, flushing it compresses and sends
+// everything written so far, so streamed SSE frames are delivered as they are written.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	// responses that must not carry a body are left as is
+	if code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified {
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		h.Add("Vary", "Accept-Encoding")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gz.Write(p)
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+}
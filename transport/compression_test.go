@@ -0,0 +1,76 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamableHTTPCompression(t *testing.T) {
+	svr, handler, err := NewStreamableHTTPServerTransportAndHandler(WithStreamableHTTPServerTransportAndHandlerOptionCompression())
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+	}
+
+	received := make(chan string, 1)
+	svr.SetReceiver(ServerReceiverF(func(_ context.Context, _ string, msg []byte) (<-chan []byte, error) {
+		received <- string(msg)
+		ch := make(chan []byte, 1)
+		ch <- []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`)
+		close(ch)
+		return ch, nil
+	}))
+
+	httpSvr := httptest.NewServer(handler.HandleMCP())
+	defer httpSvr.Close()
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	if _, err = gz.Write([]byte(request)); err != nil {
+		t.Fatal(err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, httpSvr.URL, &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	// disable transparent decompression to observe the encoded response
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := <-received; got != request {
+		t.Fatalf("expected decoded request %s, got %s", request, got)
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip encoded response, got headers %v", resp.Header)
+	}
+
+	gr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip NewReader: %v", err)
+	}
+	decoded, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+	if !strings.Contains(string(decoded), `data: {"jsonrpc":"2.0","id":1,"result":{}}`) {
+		t.Fatalf("unexpected decoded response %q", decoded)
+	}
+}
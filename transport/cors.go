@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the CORS headers of the HTTP transports for browser-based clients.
+// Without it no CORS headers are sent, so browsers deny cross-origin requests.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to call the server, "*" allows any origin
+	AllowedOrigins []string
+	// AllowedMethods defaults to GET, POST, DELETE and OPTIONS
+	AllowedMethods []string
+	// AllowedHeaders defaults to the headers used by MCP clients
+	AllowedHeaders []string
+	// AllowCredentials allows cookies and HTTP authentication to be sent with cross-origin requests
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the result of a preflight request
+	MaxAge time.Duration
+}
+
+var (
+	defaultCORSAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions}
+	defaultCORSAllowedHeaders = []string{"Content-Type", "Accept", "Authorization", sessionIDHeader, eventIDHeader, "traceparent"}
+)
+
+func (o *CORSOptions) allowOrigin(origin string) bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCORS sets the CORS headers of a cross-origin request, exposing exposedHeaders to the client.
+// It returns true if the request was a preflight request and has been answered.
+func (o *CORSOptions) handleCORS(w http.ResponseWriter, r *http.Request, exposedHeaders ...string) bool {
+	origin := r.Header.Get("Origin")
+	if o == nil || origin == "" {
+		return false
+	}
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	if !o.allowOrigin(origin) {
+		if preflight {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return preflight
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+	if o.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(exposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(exposedHeaders, ", "))
+	}
+	if !preflight {
+		return false
+	}
+
+	methods, headers := o.AllowedMethods, o.AllowedHeaders
+	if len(methods) == 0 {
+		methods = defaultCORSAllowedMethods
+	}
+	if len(headers) == 0 {
+		headers = defaultCORSAllowedHeaders
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	if o.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(o.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
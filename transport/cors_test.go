@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamableHTTPCORS(t *testing.T) {
+	newServer := func(opts ...StreamableHTTPServerTransportAndHandlerOption) *httptest.Server {
+		_, handler, err := NewStreamableHTTPServerTransportAndHandler(opts...)
+		if err != nil {
+			t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+		}
+		httpSvr := httptest.NewServer(handler.HandleMCP())
+		t.Cleanup(httpSvr.Close)
+		return httpSvr
+	}
+	do := func(url, method, origin string, preflight bool) *http.Response {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	// cross-origin requests are denied unless CORS is configured
+	httpSvr := newServer()
+	resp := do(httpSvr.URL, http.MethodOptions, "https://app.example.com", true)
+	if resp.StatusCode == http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unexpected preflight response without CORS: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	httpSvr = newServer(WithStreamableHTTPServerTransportAndHandlerOptionStateMode(Stateful),
+		WithStreamableHTTPServerTransportAndHandlerOptionCORS(CORSOptions{
+			AllowedOrigins:   []string{"https://app.example.com"},
+			AllowCredentials: true,
+			MaxAge:           time.Hour,
+		}))
+
+	resp = do(httpSvr.URL, http.MethodOptions, "https://app.example.com", true)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected preflight status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Fatalf("unexpected Access-Control-Allow-Origin %q", got)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Headers"); !strings.Contains(got, sessionIDHeader) {
+		t.Fatalf("expected %s to be an allowed header, got %q", sessionIDHeader, got)
+	}
+	if resp.Header.Get("Access-Control-Allow-Credentials") != "true" || resp.Header.Get("Access-Control-Max-Age") != "3600" {
+		t.Fatalf("unexpected preflight headers %v", resp.Header)
+	}
+
+	resp = do(httpSvr.URL, http.MethodOptions, "https://evil.example.com", true)
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unexpected preflight response for a denied origin: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	resp = do(httpSvr.URL, http.MethodDelete, "https://app.example.com", false)
+	if got := resp.Header.Get("Access-Control-Expose-Headers"); got != sessionIDHeader {
+		t.Fatalf("expected %s to be exposed, got %q", sessionIDHeader, got)
+	}
+}
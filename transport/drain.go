@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// Drainer is implemented by the server transports that can drain their sessions before the process exits,
+// e.g. during rolling restarts of horizontally scaled deployments
+type Drainer interface {
+	// Drain stops accepting new sessions, advises every active session to reconnect elsewhere with a
+	// protocol.NotificationServerDraining notification and waits for the in-flight requests to finish before
+	// closing the sessions. It returns once the sessions are closed, or with the error of ctx once it is done,
+	// in which case the sessions are closed without waiting any longer.
+	Drain(ctx context.Context) error
+}
+
+var _ Drainer = (*streamableHTTPServerTransport)(nil)
+
+// drainPollInterval is how often Drain checks whether the in-flight requests have finished
+const drainPollInterval = 10 * time.Millisecond
+
+// drainNotifyTimeout bounds the draining notification of a session whose message queue stays full
+const drainNotifyTimeout = time.Second
+
+// drainNotifyWorkers is how many sessions Drain notifies concurrently
+const drainNotifyWorkers = 16
+
+// sessionRanger is implemented by the session managers that can list their sessions,
+// Drain only notifies the sessions of such a manager
+type sessionRanger interface {
+	RangeSessionIDs(f func(sessionID string) bool)
+}
+
+func (t *streamableHTTPServerTransport) Drain(ctx context.Context) error {
+	atomic.StoreInt32(&t.draining, 1)
+	t.health.setReady(false)
+
+	notify, err := json.Marshal(protocol.NewJSONRPCNotification(protocol.NotificationServerDraining,
+		protocol.NewDrainingNotification("server is draining, reconnect to continue")))
+	if err != nil {
+		return err
+	}
+	if ranger, ok := t.sessionManager.(sessionRanger); ok {
+		t.notifyDraining(ctx, ranger, notify)
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for atomic.LoadInt64(&t.inFlyRequest) > 0 {
+		select {
+		case <-ctx.Done():
+			t.sessionManager.CloseAllSessions()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	t.sessionManager.CloseAllSessions()
+	return nil
+}
+
+// notifyDraining sends notify to every session on a fixed number of workers,
+// so that one session with a full queue doesn't hold back the others
+func (t *streamableHTTPServerTransport) notifyDraining(ctx context.Context, ranger sessionRanger, notify []byte) {
+	sessionIDs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < drainNotifyWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sessionID := range sessionIDs {
+				t.sendDraining(ctx, sessionID, notify)
+			}
+		}()
+	}
+	ranger.RangeSessionIDs(func(sessionID string) bool {
+		sessionIDs <- sessionID
+		return true
+	})
+	close(sessionIDs)
+	wg.Wait()
+}
+
+func (t *streamableHTTPServerTransport) sendDraining(ctx context.Context, sessionID string, notify []byte) {
+	defer pkg.RecoverWithLogger(t.logger, nil)
+
+	ctx, cancel := context.WithTimeout(ctx, drainNotifyTimeout)
+	defer cancel()
+	// sessions without an open stream can't be notified, they notice once their session is closed
+	if err := t.Send(ctx, sessionID, notify); err != nil {
+		t.logger.Debugf("send draining notification fail: %v, sessionID=%s", err, sessionID)
+	}
+}
+
+func (t *streamableHTTPServerTransport) isDraining() bool {
+	return atomic.LoadInt32(&t.draining) == 1
+}
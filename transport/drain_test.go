@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+func TestStreamableHTTPDrain(t *testing.T) {
+	tr, handler, err := NewStreamableHTTPServerTransportAndHandler(WithStreamableHTTPServerTransportAndHandlerOptionStateMode(Stateful))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+	}
+	sessionManager := newMockSessionManager()
+	tr.SetSessionManager(sessionManager)
+	sessionID := sessionManager.CreateSession(context.Background())
+	// a session whose queue is never read, its notification must not hold back the others
+	stuckSessionID := sessionManager.CreateSession(context.Background())
+	if err = sessionManager.OpenMessageQueueForSend(stuckSessionID); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan struct{})
+	release := make(chan []byte)
+	tr.SetReceiver(ServerReceiverF(func(context.Context, string, []byte) (<-chan []byte, error) {
+		close(received)
+		return release, nil
+	}))
+
+	httpServer := httptest.NewServer(handler.HandleMCP())
+	defer httpServer.Close()
+
+	post := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set(sessionIDHeader, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(sessionIDHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	// a request still in flight when draining starts
+	inFlight := make(chan *http.Response, 1)
+	go func() {
+		inFlight <- post(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`)
+	}()
+	<-received
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- tr.(Drainer).Drain(context.Background())
+	}()
+
+	scanner := bufio.NewScanner(stream.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+			if !strings.Contains(line, string(protocol.NotificationServerDraining)) {
+				t.Fatalf("expected a draining notification, got %s", line)
+			}
+			break
+		}
+	}
+
+	resp := post(`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{}}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected new sessions to be rejected while draining, got %d", resp.StatusCode)
+	}
+
+	select {
+	case err := <-drained:
+		t.Fatalf("expected Drain to wait for the in-flight request, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	(<-inFlight).Body.Close()
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Fatalf("Drain: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for Drain to return")
+	}
+	if _, ok := sessionManager.Load(sessionID); ok {
+		t.Fatal("expected the session to be closed once drained")
+	}
+}
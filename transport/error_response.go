@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hhfgeg/go-mcp/protocol"
+)
+
+// ErrorResponseFormat selects the body of the HTTP error responses written before a JSON-RPC message is handled,
+// e.g. when authentication fails or the request body can not be read
+type ErrorResponseFormat int
+
+const (
+	// ErrorResponseFormatDefault keeps the transport default, JSON-RPC for streamable HTTP and plain text for SSE
+	ErrorResponseFormatDefault ErrorResponseFormat = iota
+	// ErrorResponseFormatJSONRPC writes a JSON-RPC error response without id, as application/json
+	ErrorResponseFormatJSONRPC
+	// ErrorResponseFormatJSON writes {"error":{"code":<HTTP status>,"message":<message>}}, as application/json
+	ErrorResponseFormatJSON
+	// ErrorResponseFormatPlainText writes the bare message, as text/plain
+	ErrorResponseFormatPlainText
+)
+
+// HTTPError is the body of the error responses written in ErrorResponseFormatJSON
+type HTTPError struct {
+	Error HTTPErrorDetail `json:"error"`
+}
+
+type HTTPErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeErrorResponse writes an error response with the given HTTP status code in the given format
+func writeErrorResponse(w http.ResponseWriter, format ErrorResponseFormat, code int, message string) error {
+	var (
+		body        []byte
+		contentType = "application/json"
+		err         error
+	)
+	switch format {
+	case ErrorResponseFormatJSON:
+		body, err = json.Marshal(HTTPError{Error: HTTPErrorDetail{Code: code, Message: message}})
+	case ErrorResponseFormatPlainText:
+		body, contentType = []byte(message), "text/plain; charset=utf-8"
+	default:
+		body, err = json.Marshal(protocol.NewJSONRPCErrorResponse(nil, protocol.InternalError, message))
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,47 @@
+package transport
+
+import "sync"
+
+// eventBuffer keeps the latest events sent on the GET stream of a session,
+// so that a client resuming the stream with Last-Event-ID gets the events it missed
+type eventBuffer struct {
+	mu     sync.Mutex
+	size   int
+	lastID uint64          // id of the latest event, ids start at 1 and increase by one
+	events []bufferedEvent // oldest first, at most size
+}
+
+type bufferedEvent struct {
+	id   uint64
+	data []byte
+}
+
+func newEventBuffer(size int) *eventBuffer {
+	return &eventBuffer{size: size, events: make([]bufferedEvent, 0, size)}
+}
+
+// append assigns the next event id to data and buffers it, dropping the oldest event once the buffer is full
+func (b *eventBuffer) append(data []byte) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	if len(b.events) == b.size {
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+	}
+	b.events = append(b.events, bufferedEvent{id: b.lastID, data: data})
+	return b.lastID
+}
+
+// since returns the events after lastID, ok is false if some of them were already dropped or lastID is unknown
+func (b *eventBuffer) since(lastID uint64) (events []bufferedEvent, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	firstID := b.lastID - uint64(len(b.events)) + 1
+	if lastID > b.lastID || lastID+1 < firstID {
+		return nil, false
+	}
+	return append([]bufferedEvent(nil), b.events[lastID+1-firstID:]...), true
+}
@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"net/http"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+const (
+	defaultLivenessPath  = "/healthz"
+	defaultReadinessPath = "/readyz"
+)
+
+// healthEndpoints serves the liveness and readiness probes of the HTTP server transports,
+// e.g. for Kubernetes or load balancers that don't speak MCP
+type healthEndpoints struct {
+	livenessPath  string
+	readinessPath string
+	ready         *pkg.AtomicBool // the server is accepting requests and not shutting down
+}
+
+// newHealthEndpoints serves the liveness probe on the first path and the readiness probe on the second one,
+// defaulting to /healthz and /readyz respectively
+func newHealthEndpoints(paths []string) *healthEndpoints {
+	h := &healthEndpoints{
+		livenessPath:  defaultLivenessPath,
+		readinessPath: defaultReadinessPath,
+		ready:         pkg.NewAtomicBool(),
+	}
+	if len(paths) > 0 && paths[0] != "" {
+		h.livenessPath = paths[0]
+	}
+	if len(paths) > 1 && paths[1] != "" {
+		h.readinessPath = paths[1]
+	}
+	return h
+}
+
+func (h *healthEndpoints) register(mux *http.ServeMux) {
+	if h == nil {
+		return
+	}
+	mux.HandleFunc(h.livenessPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc(h.readinessPath, func(w http.ResponseWriter, _ *http.Request) {
+		if !h.ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+}
+
+func (h *healthEndpoints) setReady(ready bool) {
+	if h == nil {
+		return
+	}
+	h.ready.Store(ready)
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	port, err := getAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to get available port: %v", err)
+	}
+	serverAddr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	svr := NewStreamableHTTPServerTransport(serverAddr, WithStreamableHTTPServerTransportOptionHealthEndpoints("/livez"))
+	svr.SetSessionManager(newMockSessionManager())
+	go func() {
+		_ = svr.Run()
+	}()
+
+	get := func(path string) int {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", serverAddr, path))
+		if err != nil {
+			return 0
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for get("/livez") != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the liveness probe to succeed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Fatalf("expected the readiness probe to succeed once listening, got %d", code)
+	}
+	if code := get("/healthz"); code != http.StatusNotFound {
+		t.Fatalf("expected the default liveness path to be replaced, got %d", code)
+	}
+
+	serverCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := svr.Shutdown(context.Background(), serverCtx); err != nil {
+		t.Fatalf("Shutdown: %+v", err)
+	}
+	if svr.(*streamableHTTPServerTransport).health.ready.Load() {
+		t.Fatal("expected the server not to be ready once shutting down")
+	}
+}
+
+func TestHealthEndpointsDisabledByDefault(t *testing.T) {
+	svr := NewStreamableHTTPServerTransport("127.0.0.1:0").(*streamableHTTPServerTransport)
+
+	rec := httptest.NewRecorder()
+	svr.httpSvr.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected no health endpoint by default, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultIdleTimeout is how long the HTTP server transports keep an idle keep-alive connection open
+const defaultIdleTimeout = time.Minute
+
+// httpTimeouts are the timeouts of the http.Server an HTTP server transport listens with
+type httpTimeouts struct {
+	read  time.Duration
+	write time.Duration
+	idle  time.Duration
+}
+
+type connKey struct{}
+
+// newHTTPServer creates the http.Server of a transport. The connection of each request is kept in its context,
+// so that streams can lift the read and write timeouts, see exemptFromTimeouts.
+func newHTTPServer(addr string, handler http.Handler, timeouts httpTimeouts) *http.Server {
+	idle := timeouts.idle
+	if idle <= 0 {
+		idle = defaultIdleTimeout
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: timeouts.read,
+		ReadTimeout:       timeouts.read,
+		WriteTimeout:      timeouts.write,
+		IdleTimeout:       idle,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, connKey{}, c)
+		},
+	}
+}
+
+// exemptFromTimeouts lifts the read and write deadlines the http.Server set on the connection of r,
+// so that a long-lived event stream isn't severed once they pass. A lapsed read deadline would also cancel
+// the request context as the server reads the connection in the background to detect the client going away.
+// It does nothing for a handler served by an http.Server the transport doesn't own.
+func exemptFromTimeouts(r *http.Request) {
+	if c, ok := r.Context().Value(connKey{}).(net.Conn); ok {
+		_ = c.SetDeadline(time.Time{})
+	}
+}
@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPTimeouts(t *testing.T) {
+	tr, err := NewSSEServerTransport("127.0.0.1:0", WithSSEServerTransportOptionHTTPTimeouts(100*time.Millisecond, 100*time.Millisecond, 0))
+	if err != nil {
+		t.Fatalf("NewSSEServerTransport: %+v", err)
+	}
+	svr := tr.(*sseServerTransport)
+	svr.SetSessionManager(newMockSessionManager())
+
+	ts := httptest.NewUnstartedServer(svr.httpSvr.Handler)
+	ts.Config = svr.httpSvr
+	ts.Start()
+	defer ts.Close()
+
+	t.Run("slow client is disconnected", func(t *testing.T) {
+		conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial: %+v", err)
+		}
+		defer conn.Close()
+		// the request headers are never completed
+		if _, err = conn.Write([]byte("GET /sse HTTP/1.1\r\nHost: localhost\r\n")); err != nil {
+			t.Fatalf("Write: %+v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		_, err = io.ReadAll(conn)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.Fatal("expected the server to close the connection once the read timeout passed")
+		}
+	})
+
+	t.Run("stream is exempt", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/sse")
+		if err != nil {
+			t.Fatalf("Get: %+v", err)
+		}
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		var sessionID string
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				sessionID = line[strings.Index(line, "sessionID=")+len("sessionID="):]
+				break
+			}
+		}
+		if sessionID == "" {
+			t.Fatalf("expected an endpoint event, scan err: %v", scanner.Err())
+		}
+
+		// outlive both timeouts before the server writes again
+		time.Sleep(300 * time.Millisecond)
+		if err = svr.Send(context.Background(), sessionID, Message(`{"jsonrpc":"2.0","method":"ping"}`)); err != nil {
+			t.Fatalf("Send: %+v", err)
+		}
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				if !strings.Contains(line, "ping") {
+					t.Fatalf("unexpected event %q", line)
+				}
+				return
+			}
+		}
+		t.Fatalf("expected the stream to deliver the message, scan err: %v", scanner.Err())
+	})
+}
@@ -0,0 +1,31 @@
+package transport
+
+// Direction tells whether an intercepted message was received or sent by the transport
+type Direction int
+
+const (
+	// DirectionInbound is a message read from the peer, before it is decoded
+	DirectionInbound Direction = iota
+	// DirectionOutbound is a message written to the peer, after it is encoded
+	DirectionOutbound
+)
+
+func (d Direction) String() string {
+	if d == DirectionOutbound {
+		return "outbound"
+	}
+	return "inbound"
+}
+
+// Interceptor observes every raw JSON-RPC message a transport reads or writes, including malformed messages
+// and notifications that never reach a handler. raw is a copy of the message without the framing of the
+// transport, e.g. the SSE data: prefix, it may be retained. Interceptors are called from the goroutines
+// reading and writing, concurrently for different sessions, and must not block.
+type Interceptor func(direction Direction, raw []byte)
+
+func (i Interceptor) intercept(direction Direction, raw []byte) {
+	if i == nil {
+		return
+	}
+	i(direction, append([]byte(nil), raw...))
+}
@@ -0,0 +1,73 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestInterceptor(t *testing.T) {
+	type frame struct {
+		direction Direction
+		raw       string
+	}
+	var (
+		mu     sync.Mutex
+		frames []frame
+	)
+	interceptor := func(direction Direction, raw []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		frames = append(frames, frame{direction: direction, raw: string(raw)})
+	}
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	server := NewStdioServerTransportWithIO(inReader, outWriter, WithStdioServerOptionInterceptor(interceptor))
+	server.SetSessionManager(newMockSessionManager())
+	server.SetReceiver(ServerReceiverF(func(_ context.Context, _ string, msg []byte) (<-chan []byte, error) {
+		msgCh := make(chan []byte, 1)
+		msgCh <- msg
+		close(msgCh)
+		return msgCh, nil
+	}))
+	go func() {
+		_ = server.Run()
+	}()
+	defer func() {
+		serverCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_ = server.Shutdown(context.Background(), serverCtx)
+	}()
+
+	out := bufio.NewReader(outReader)
+	for _, msg := range []string{`{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{malformed`} {
+		go func(msg string) {
+			_, _ = fmt.Fprintf(inWriter, "%s\n", msg)
+		}(msg)
+		got, err := StdioFramingNewline.readMessage(out, 0)
+		if err != nil {
+			t.Fatalf("readMessage: %v", err)
+		}
+		if string(got) != msg {
+			t.Fatalf("got %s, want %s", got, msg)
+		}
+	}
+
+	want := []frame{
+		{DirectionInbound, `{"jsonrpc":"2.0","id":1,"method":"ping"}`},
+		{DirectionOutbound, `{"jsonrpc":"2.0","id":1,"method":"ping"}`},
+		{DirectionInbound, `{malformed`},
+		{DirectionOutbound, `{malformed`},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(frames, want) {
+		t.Fatalf("intercepted %v, want %v", frames, want)
+	}
+}
@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxMessageSize bounds the size of a single inbound JSON-RPC message of the server transports,
+// generous enough for large resources while protecting the server from running out of memory
+const DefaultMaxMessageSize = 16 << 20
+
+var errMessageTooLarge = errors.New("message exceeds the maximum message size")
+
+// maxSizeReader reads from r, failing with errMessageTooLarge as soon as more than n bytes are read,
+// so that an oversized message is never fully buffered
+type maxSizeReader struct {
+	r io.Reader
+	n int64
+}
+
+func newMaxSizeReader(r io.Reader, n int64) io.Reader {
+	if n <= 0 {
+		return r
+	}
+	return &maxSizeReader{r: r, n: n}
+}
+
+func (l *maxSizeReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errMessageTooLarge
+	}
+	// one byte more than remains is enough to tell that the limit is exceeded
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errMessageTooLarge
+	}
+	return n, err
+}
+
+// writeMessageTooLarge answers a request whose body exceeds the maximum message size,
+// the connection is closed since the rest of the body is left unread
+func writeMessageTooLarge(w http.ResponseWriter, writeError func(http.ResponseWriter, int, string), maxSize int) {
+	w.Header().Set("Connection", "close")
+	writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds the maximum message size of %d bytes", maxSize))
+}
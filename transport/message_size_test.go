@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStdioFramingMaxMessageSize(t *testing.T) {
+	small := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	// larger than the bufio.Reader buffer, so that the line is read in several chunks
+	large := fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"ping","params":{"pad":%q}}`, strings.Repeat("x", 10000))
+
+	for _, framing := range []StdioFraming{StdioFramingNewline, StdioFramingContentLength} {
+		var buf bytes.Buffer
+		for _, msg := range []string{large, small} {
+			if err := framing.writeMessage(&buf, []byte(msg)); err != nil {
+				t.Fatalf("framing %d: writeMessage: %v", framing, err)
+			}
+		}
+
+		r := bufio.NewReader(&buf)
+		if _, err := framing.readMessage(r, 1024); !errors.Is(err, errMessageTooLarge) {
+			t.Fatalf("framing %d: expected errMessageTooLarge, got %v", framing, err)
+		}
+		// the oversized message is consumed and reading goes on with the next one
+		msg, err := framing.readMessage(r, 1024)
+		if err != nil {
+			t.Fatalf("framing %d: readMessage: %v", framing, err)
+		}
+		if string(msg) != small {
+			t.Fatalf("framing %d: got %s, want %s", framing, msg, small)
+		}
+	}
+}
+
+func TestStdioServerMaxMessageSize(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	server := NewStdioServerTransportWithIO(inReader, outWriter, WithStdioServerOptionMaxMessageSize(64))
+	server.SetSessionManager(newMockSessionManager())
+	server.SetReceiver(ServerReceiverF(func(_ context.Context, _ string, msg []byte) (<-chan []byte, error) {
+		t.Errorf("unexpected message received: %s", msg)
+		return nil, nil
+	}))
+	go func() {
+		_ = server.Run()
+	}()
+	defer func() {
+		serverCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_ = server.Shutdown(context.Background(), serverCtx)
+	}()
+
+	go func() {
+		_, _ = fmt.Fprintf(inWriter, "{\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"ping\",\"params\":{\"pad\":%q}}\n", strings.Repeat("x", 100))
+	}()
+
+	got, err := StdioFramingNewline.readMessage(bufio.NewReader(outReader), 0)
+	if err != nil {
+		t.Fatalf("readMessage: %v", err)
+	}
+	if !strings.Contains(string(got), `"code":-32600`) || !strings.Contains(string(got), "maximum message size") {
+		t.Fatalf("expected an invalid request error, got %s", got)
+	}
+}
+
+func TestStreamableHTTPMaxMessageSize(t *testing.T) {
+	svr, handler, err := NewStreamableHTTPServerTransportAndHandler(WithStreamableHTTPServerTransportAndHandlerOptionMaxMessageSize(64))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+	}
+	svr.SetReceiver(ServerReceiverF(func(_ context.Context, _ string, msg []byte) (<-chan []byte, error) {
+		ch := make(chan []byte, 1)
+		ch <- []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`)
+		close(ch)
+		return ch, nil
+	}))
+
+	httpSvr := httptest.NewServer(handler.HandleMCP())
+	defer httpSvr.Close()
+
+	post := func(body string) int {
+		req, err := http.NewRequest(http.MethodPost, httpSvr.URL, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post(`{"jsonrpc":"2.0","id":1,"method":"ping"}`); code != http.StatusOK {
+		t.Fatalf("expected a message within the limit to be accepted, got %d", code)
+	}
+	large := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"ping","params":{"pad":%q}}`, strings.Repeat("x", 100))
+	if code := post(large); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for an oversized message, got %d", code)
+	}
+}
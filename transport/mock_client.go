@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 )
@@ -15,6 +16,7 @@ type mockClientTransport struct {
 	receiver clientReceiver
 	in       io.ReadCloser
 	out      io.Writer
+	writeMu  sync.Mutex // concurrent requests and responses are written one message at a time
 
 	logger pkg.Logger
 
@@ -36,7 +38,7 @@ func (t *mockClientTransport) Start() error {
 	t.cancel = cancel
 
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 
 		t.startReceive(ctx)
 
@@ -47,6 +49,9 @@ func (t *mockClientTransport) Start() error {
 }
 
 func (t *mockClientTransport) Send(_ context.Context, msg Message) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
 	if _, err := t.out.Write(append(msg, mcpMessageDelimiter)); err != nil {
 		return fmt.Errorf("failed to write: %w", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 )
@@ -15,6 +16,7 @@ type mockServerTransport struct {
 	receiver serverReceiver
 	in       io.ReadCloser
 	out      io.Writer
+	writeMu  sync.Mutex // messages of concurrent requests are written one at a time
 
 	sessionID string
 
@@ -49,6 +51,9 @@ func (t *mockServerTransport) Run() error {
 }
 
 func (t *mockServerTransport) Send(_ context.Context, _ string, msg Message) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
 	if _, err := t.out.Write(append(msg, mcpMessageDelimiter)); err != nil {
 		return fmt.Errorf("failed to write: %w", err)
 	}
@@ -117,7 +122,7 @@ func (t *mockServerTransport) receive(ctx context.Context, line []byte) {
 	}
 
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 
 		for msg := range outputMsgCh {
 			if e := t.Send(context.Background(), t.sessionID, msg); e != nil {
@@ -0,0 +1,171 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+// maxReconnectDelay caps the exponential backoff between reconnect attempts
+const maxReconnectDelay = 30 * time.Second
+
+// ReconnectEvent describes a reconnect attempt of a client transport
+type ReconnectEvent struct {
+	// Attempt counts the consecutive attempts since the connection was lost, starting at 1
+	Attempt int
+	// Delay is the backoff waited before the attempt
+	Delay time.Duration
+	// Err is the error the connection was lost or the previous attempt failed with
+	Err error
+	// Reconnected reports the connection was restored by the attempt, no further attempt follows
+	Reconnected bool
+	// GaveUp reports the retry budget is exhausted, no further attempt follows and pending sends fail
+	GaveUp bool
+	// SessionExpired reports the server no longer knows the session, the stream is reopened
+	// once the client initialized a new session
+	SessionExpired bool
+}
+
+// reconnector restores a lost connection with exponential backoff and jitter,
+// and holds sends back while the connection is down
+type reconnector struct {
+	maxRetries  int
+	baseDelay   time.Duration
+	onReconnect func(ReconnectEvent)
+
+	mu      sync.Mutex
+	attempt int
+	ready   chan struct{} // closed while the connection is up
+	failed  chan struct{} // closed once the retry budget is exhausted
+	err     error
+}
+
+func newReconnector(maxRetries int, baseDelay time.Duration) *reconnector {
+	ready := make(chan struct{})
+	close(ready)
+	return &reconnector{
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		ready:      ready,
+		failed:     make(chan struct{}),
+	}
+}
+
+// wait blocks until the connection is up, it fails once the retry budget is exhausted
+func (r *reconnector) wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	ready := r.ready
+	r.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-r.failed:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// connected marks the connection up, releasing the held back sends
+func (r *reconnector) connected() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	attempt := r.attempt
+	r.attempt = 0
+	select {
+	case <-r.ready:
+	default:
+		close(r.ready)
+	}
+	r.mu.Unlock()
+
+	if attempt > 0 {
+		r.notify(ReconnectEvent{Attempt: attempt, Reconnected: true})
+	}
+}
+
+// sessionExpired ends the attempts to restore a connection to a session the server no longer knows,
+// releasing the held back sends so that they learn about it
+func (r *reconnector) sessionExpired() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	attempt := r.attempt
+	r.attempt = 0
+	select {
+	case <-r.ready:
+	default:
+		close(r.ready)
+	}
+	r.mu.Unlock()
+
+	r.notify(ReconnectEvent{Attempt: attempt, Err: pkg.ErrSessionClosed, SessionExpired: true})
+}
+
+// backoff marks the connection down after it was lost or an attempt failed with err,
+// and waits before the next attempt. It reports false once the retry budget is exhausted or ctx is done.
+func (r *reconnector) backoff(ctx context.Context, err error) bool {
+	r.mu.Lock()
+	select {
+	case <-r.ready:
+		r.ready = make(chan struct{})
+	default:
+	}
+	r.attempt++
+	attempt := r.attempt
+	if attempt > r.maxRetries {
+		r.err = fmt.Errorf("%w: %v", pkg.ErrReconnectFailed, err)
+		close(r.failed)
+		r.mu.Unlock()
+		r.notify(ReconnectEvent{Attempt: attempt - 1, Err: err, GaveUp: true})
+		return false
+	}
+	r.mu.Unlock()
+
+	delay := r.delay(attempt)
+	r.notify(ReconnectEvent{Attempt: attempt, Delay: delay, Err: err})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// delay doubles the base delay with every attempt, randomized into [d/2, d) to spread out reconnecting clients
+func (r *reconnector) delay(attempt int) time.Duration {
+	d := r.baseDelay
+	for i := 1; i < attempt && d < maxReconnectDelay; i++ {
+		d *= 2
+	}
+	if d > maxReconnectDelay {
+		d = maxReconnectDelay
+	}
+	if half := int64(d / 2); half > 0 {
+		d = time.Duration(half + rand.Int63n(half)) //nolint:gosec
+	}
+	return d
+}
+
+func (r *reconnector) notify(event ReconnectEvent) {
+	if r.onReconnect != nil {
+		r.onReconnect(event)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hhfgeg/go-mcp/pkg"
@@ -41,12 +42,36 @@ func WithRetryFunc(retry func(func() error)) SSEClientTransportOption {
 	}
 }
 
+// WithReconnect reconnects a lost SSE stream up to maxRetries consecutive times, waiting baseDelay doubled with every
+// attempt and randomized by jitter in between. The server assigns a new message endpoint on reconnect, sends issued
+// meanwhile are held back until it is received, and fail with pkg.ErrReconnectFailed once the retry budget is exhausted.
+// It takes precedence over WithRetryFunc.
+func WithReconnect(maxRetries int, baseDelay time.Duration) SSEClientTransportOption {
+	return func(t *sseClientTransport) {
+		t.reconnect = newReconnector(maxRetries, baseDelay)
+	}
+}
+
+// WithReconnectHandler observes the reconnect attempts enabled by WithReconnect
+func WithReconnectHandler(handler func(ReconnectEvent)) SSEClientTransportOption {
+	return func(t *sseClientTransport) {
+		t.onReconnect = handler
+	}
+}
+
 func WithSSEClientOptionHeader(header map[string][]string) SSEClientTransportOption {
 	return func(t *sseClientTransport) {
 		t.header = header
 	}
 }
 
+// WithSSEClientOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithSSEClientOptionInterceptor(interceptor Interceptor) SSEClientTransportOption {
+	return func(t *sseClientTransport) {
+		t.interceptor = interceptor
+	}
+}
+
 type sseClientTransport struct {
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -54,17 +79,24 @@ type sseClientTransport struct {
 	serverURL *url.URL
 
 	endpointChan    chan struct{}
+	endpointMu      sync.RWMutex
 	messageEndpoint *url.URL
 	receiver        clientReceiver
 
+	lastEventID *pkg.AtomicString // id of the last received event, sent as Last-Event-ID on reconnect
+
 	// options
 	logger         pkg.Logger
 	receiveTimeout time.Duration
 	client         *http.Client
 	header         map[string][]string
+	interceptor    Interceptor
 
 	retry func(func() error)
 
+	reconnect   *reconnector
+	onReconnect func(ReconnectEvent)
+
 	sseConnectClose chan struct{}
 }
 
@@ -82,6 +114,7 @@ func NewSSEClientTransport(serverURL string, opts ...SSEClientTransportOption) (
 		logger:          pkg.DefaultLogger,
 		receiveTimeout:  time.Second * 30,
 		client:          http.DefaultClient,
+		lastEventID:     pkg.NewAtomicString(),
 		sseConnectClose: make(chan struct{}),
 		retry: func(operation func() error) {
 			for {
@@ -97,6 +130,10 @@ func NewSSEClientTransport(serverURL string, opts ...SSEClientTransportOption) (
 		opt(t)
 	}
 
+	if t.reconnect != nil {
+		t.reconnect.onReconnect = t.onReconnect
+	}
+
 	return t, nil
 }
 
@@ -113,10 +150,10 @@ func (t *sseClientTransport) Start() (err error) {
 
 	errChan := make(chan error, 1)
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 		defer close(t.sseConnectClose)
 
-		t.retry(func() error {
+		operation := func() error {
 			if e := t.startSSE(); e != nil {
 				if errors.Is(e, context.Canceled) {
 					return nil
@@ -126,7 +163,18 @@ func (t *sseClientTransport) Start() (err error) {
 				return e
 			}
 			return nil
-		})
+		}
+
+		if t.reconnect == nil {
+			t.retry(operation)
+			return
+		}
+		for {
+			e := operation()
+			if e == nil || !t.reconnect.backoff(t.ctx, e) {
+				return
+			}
+		}
 	}()
 
 	// Wait for the endpoint to be received
@@ -159,6 +207,9 @@ func (t *sseClientTransport) startSSE() error {
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
+	if lastEventID := t.lastEventID.Load(); lastEventID != "" {
+		req.Header.Set(eventIDHeader, lastEventID)
+	}
 	t.addHeader(req)
 
 	resp, err := t.client.Do(req) //nolint:bodyclose
@@ -217,6 +268,8 @@ func (t *sseClientTransport) readSSE(reader io.ReadCloser) error {
 			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
 		} else if strings.HasPrefix(line, "data:") {
 			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		} else if strings.HasPrefix(line, "id:") {
+			t.lastEventID.Store(strings.TrimSpace(strings.TrimPrefix(line, "id:")))
 		}
 	}
 }
@@ -232,14 +285,18 @@ func (t *sseClientTransport) handleSSEEvent(event, data string) {
 			return
 		}
 		t.logger.Debugf("Received endpoint: %s", endpoint.String())
+		t.endpointMu.Lock()
 		t.messageEndpoint = endpoint
+		t.endpointMu.Unlock()
 		select {
 		case t.endpointChan <- struct{}{}:
 		default:
 		}
+		t.reconnect.connected()
 	case "message":
 		ctx, cancel := context.WithTimeout(t.ctx, t.receiveTimeout)
 		defer cancel()
+		t.interceptor.intercept(DirectionInbound, []byte(data))
 		if err := t.receiver.Receive(ctx, []byte(data)); err != nil {
 			t.logger.Errorf("Error receive message: %v", err)
 			return
@@ -248,7 +305,15 @@ func (t *sseClientTransport) handleSSEEvent(event, data string) {
 }
 
 func (t *sseClientTransport) Send(ctx context.Context, msg Message) error {
-	t.logger.Debugf("Sending message: %s to %s", msg, t.messageEndpoint.String())
+	if err := t.reconnect.wait(ctx); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	t.endpointMu.RLock()
+	messageEndpoint := t.messageEndpoint
+	t.endpointMu.RUnlock()
+
+	t.logger.Debugf("Sending message: %s to %s", msg, messageEndpoint.String())
 
 	var (
 		err  error
@@ -256,7 +321,8 @@ func (t *sseClientTransport) Send(ctx context.Context, msg Message) error {
 		resp *http.Response
 	)
 
-	req, err = http.NewRequestWithContext(ctx, http.MethodPost, t.messageEndpoint.String(), bytes.NewReader(msg))
+	t.interceptor.intercept(DirectionOutbound, msg)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, messageEndpoint.String(), bytes.NewReader(msg))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -2,9 +2,9 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"path"
@@ -41,6 +41,85 @@ func WithSSEServerTransportOptionURLPrefix(urlPrefix string) SSEServerTransportO
 	}
 }
 
+// WithSSEServerTransportOptionBearerTokenValidator requires every request to carry a bearer token accepted by validator
+func WithSSEServerTransportOptionBearerTokenValidator(validator BearerTokenValidator) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.bearerTokenValidator = validator
+	}
+}
+
+// WithSSEServerTransportOptionTLSConfig serves HTTPS with the given TLS configuration
+func WithSSEServerTransportOptionTLSConfig(config *tls.Config) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.tlsConfig = config
+	}
+}
+
+// WithSSEServerTransportOptionTLSCertFile serves HTTPS with the certificate and key loaded from the given files
+func WithSSEServerTransportOptionTLSCertFile(certFile, keyFile string) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.certFile = certFile
+		t.keyFile = keyFile
+	}
+}
+
+// WithSSEServerTransportOptionCompression gzips responses for clients accepting it and decodes gzip encoded request bodies
+func WithSSEServerTransportOptionCompression() SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.compression = true
+	}
+}
+
+// WithSSEServerTransportOptionErrorResponseFormat sets the body format of the HTTP error responses, plain text by default
+func WithSSEServerTransportOptionErrorResponseFormat(format ErrorResponseFormat) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.errorResponseFormat = format
+	}
+}
+
+// WithSSEServerTransportOptionCORS answers preflight requests and sets the CORS headers of cross-origin requests
+func WithSSEServerTransportOptionCORS(options CORSOptions) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.cors = &options
+	}
+}
+
+// WithSSEServerTransportOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithSSEServerTransportOptionInterceptor(interceptor Interceptor) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+// WithSSEServerTransportOptionHealthEndpoints serves a liveness probe on the first path and a readiness probe
+// on the second one, /healthz and /readyz by default. The readiness probe fails until the server listens and
+// again once it shuts down, so that traffic drains.
+func WithSSEServerTransportOptionHealthEndpoints(paths ...string) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.health = newHealthEndpoints(paths)
+	}
+}
+
+// WithSSEServerTransportOptionHTTPTimeouts sets the timeouts of the HTTP server, none by default except for
+// an idle timeout of one minute. read bounds reading a request including its body and write bounds writing the
+// response of a message post, so that slow clients can't hold connections forever. The SSE endpoint is exempt
+// from both once its stream is open, so that long-lived streams aren't severed. idle bounds how long a keep-alive
+// connection waits for the next request, a non-positive duration keeps the default.
+func WithSSEServerTransportOptionHTTPTimeouts(read, write, idle time.Duration) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.timeouts = httpTimeouts{read: read, write: write, idle: idle}
+	}
+}
+
+// WithSSEServerTransportOptionMaxMessageSize bounds the size of a single inbound message to size bytes,
+// DefaultMaxMessageSize by default. A larger request body is answered with 413 Request Entity Too Large
+// without being buffered, a non-positive size removes the limit.
+func WithSSEServerTransportOptionMaxMessageSize(size int) SSEServerTransportOption {
+	return func(t *sseServerTransport) {
+		t.maxMessageSize = size
+	}
+}
+
 type SSEServerTransportAndHandlerOption func(*sseServerTransport)
 
 func WithSSEServerTransportAndHandlerOptionCopyParamKeys(paramsKey []string) SSEServerTransportAndHandlerOption {
@@ -55,6 +134,50 @@ func WithSSEServerTransportAndHandlerOptionLogger(logger pkg.Logger) SSEServerTr
 	}
 }
 
+// WithSSEServerTransportAndHandlerOptionBearerTokenValidator requires every request to carry a bearer token accepted by validator
+func WithSSEServerTransportAndHandlerOptionBearerTokenValidator(validator BearerTokenValidator) SSEServerTransportAndHandlerOption {
+	return func(t *sseServerTransport) {
+		t.bearerTokenValidator = validator
+	}
+}
+
+// WithSSEServerTransportAndHandlerOptionCompression gzips responses for clients accepting it and decodes gzip encoded request bodies
+func WithSSEServerTransportAndHandlerOptionCompression() SSEServerTransportAndHandlerOption {
+	return func(t *sseServerTransport) {
+		t.compression = true
+	}
+}
+
+// WithSSEServerTransportAndHandlerOptionErrorResponseFormat sets the body format of the HTTP error responses, plain text by default
+func WithSSEServerTransportAndHandlerOptionErrorResponseFormat(format ErrorResponseFormat) SSEServerTransportAndHandlerOption {
+	return func(t *sseServerTransport) {
+		t.errorResponseFormat = format
+	}
+}
+
+// WithSSEServerTransportAndHandlerOptionCORS answers preflight requests and sets the CORS headers of cross-origin requests
+func WithSSEServerTransportAndHandlerOptionCORS(options CORSOptions) SSEServerTransportAndHandlerOption {
+	return func(t *sseServerTransport) {
+		t.cors = &options
+	}
+}
+
+// WithSSEServerTransportAndHandlerOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithSSEServerTransportAndHandlerOptionInterceptor(interceptor Interceptor) SSEServerTransportAndHandlerOption {
+	return func(t *sseServerTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+// WithSSEServerTransportAndHandlerOptionMaxMessageSize bounds the size of a single inbound message to size bytes,
+// DefaultMaxMessageSize by default. A larger request body is answered with 413 Request Entity Too Large
+// without being buffered, a non-positive size removes the limit.
+func WithSSEServerTransportAndHandlerOptionMaxMessageSize(size int) SSEServerTransportAndHandlerOption {
+	return func(t *sseServerTransport) {
+		t.maxMessageSize = size
+	}
+}
+
 type sseServerTransport struct {
 	// ctx is the context that controls the lifecycle of the SSE server.
 	// It is used to coordinate cancellation of all ongoing send operations when the server is shutting down.
@@ -79,6 +202,24 @@ type sseServerTransport struct {
 	messagePath   string
 	urlPrefix     string
 	copyParamKeys []string
+
+	bearerTokenValidator BearerTokenValidator
+
+	errorResponseFormat ErrorResponseFormat
+
+	compression bool
+
+	maxMessageSize int
+
+	timeouts httpTimeouts // of the HTTP server the transport listens with, unused by the AndHandler variant
+
+	cors *CORSOptions
+
+	interceptor Interceptor
+
+	health *healthEndpoints // nil unless health endpoints are enabled
+
+	tlsOptions
 }
 
 type SSEHandler struct {
@@ -111,6 +252,8 @@ func NewSSEServerTransport(addr string, opts ...SSEServerTransportOption) (Serve
 		ssePath:     "/sse",
 		messagePath: "/message",
 		urlPrefix:   "",
+
+		maxMessageSize: DefaultMaxMessageSize,
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -129,12 +272,9 @@ func NewSSEServerTransport(addr string, opts ...SSEServerTransportOption) (Serve
 	mux := http.NewServeMux()
 	mux.HandleFunc(t.ssePath, t.handleSSE)
 	mux.HandleFunc(t.messagePath, t.handleMessage)
+	t.health.register(mux)
 
-	t.httpSvr = &http.Server{
-		Addr:        addr,
-		Handler:     mux,
-		IdleTimeout: time.Minute,
-	}
+	t.httpSvr = newHTTPServer(addr, mux, t.timeouts)
 
 	return t, nil
 }
@@ -163,6 +303,7 @@ func NewSSEServerTransportAndHandler(messageEndpointURL string,
 		cancel:             cancel,
 		messageEndpointURL: messageEndpointURL,
 		logger:             pkg.DefaultLogger,
+		maxMessageSize:     DefaultMaxMessageSize,
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -177,9 +318,11 @@ func (t *sseServerTransport) Run() error {
 		return nil
 	}
 
-	fmt.Printf("starting mcp server at http://%s%s\n", t.httpSvr.Addr, t.ssePath)
+	fmt.Printf("starting mcp server at %s://%s%s\n", t.scheme(), t.httpSvr.Addr, t.ssePath)
 
-	if err := t.httpSvr.ListenAndServe(); err != nil {
+	t.health.setReady(true)
+	defer t.health.setReady(false)
+	if err := t.listenAndServe(t.httpSvr); err != nil {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
 	}
 	return nil
@@ -205,12 +348,30 @@ func (t *sseServerTransport) SetSessionManager(manager sessionManager) {
 	t.sessionManager = manager
 }
 
+func (t *sseServerTransport) SupportsStreaming() bool {
+	return true
+}
+
 // handleSSE handles incoming SSE connections from clients and sends messages to them.
 func (t *sseServerTransport) handleSSE(w http.ResponseWriter, r *http.Request) {
-	defer pkg.RecoverWithFunc(func(_ any) {
+	defer pkg.RecoverWithLogger(t.logger, func(_ any) {
 		t.writeError(w, http.StatusInternalServerError, "Internal server error")
 	})
 
+	if t.cors.handleCORS(w, r) {
+		return
+	}
+
+	r, ok := authenticateBearer(w, r, t.bearerTokenValidator, t.logger, t.writeError)
+	if !ok {
+		return
+	}
+
+	w, closeCompression := compressResponse(t.compression, w, r)
+	defer closeCompression()
+
+	exemptFromTimeouts(r)
+
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -258,6 +419,7 @@ func (t *sseServerTransport) handleSSE(w http.ResponseWriter, r *http.Request) {
 
 		t.logger.Debugf("Sending message: %s", string(msg))
 
+		t.interceptor.intercept(DirectionOutbound, msg)
 		if _, err = fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg); err != nil {
 			t.logger.Errorf("Failed to write message: %v", err)
 			continue
@@ -269,15 +431,24 @@ func (t *sseServerTransport) handleSSE(w http.ResponseWriter, r *http.Request) {
 // handleMessage processes incoming JSON-RPC messages from clients and sends responses
 // back through both the SSE connection and HTTP response.
 func (t *sseServerTransport) handleMessage(w http.ResponseWriter, r *http.Request) {
-	defer pkg.RecoverWithFunc(func(_ any) {
+	defer pkg.RecoverWithLogger(t.logger, func(_ any) {
 		t.writeError(w, http.StatusInternalServerError, "Internal server error")
 	})
 
+	if t.cors.handleCORS(w, r) {
+		return
+	}
+
 	if r.Method != http.MethodPost {
 		t.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
+	r, ok := authenticateBearer(w, r, t.bearerTokenValidator, t.logger, t.writeError)
+	if !ok {
+		return
+	}
+
 	sessionID := r.URL.Query().Get("sessionID")
 	if sessionID == "" {
 		t.writeError(w, http.StatusBadRequest, "Missing session ID")
@@ -285,13 +456,18 @@ func (t *sseServerTransport) handleMessage(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Parse message as raw JSON
-	inputMsg, err := io.ReadAll(r.Body)
+	inputMsg, err := readRequestBody(t.compression, t.maxMessageSize, r)
+	if errors.Is(err, errMessageTooLarge) {
+		writeMessageTooLarge(w, t.writeError, t.maxMessageSize)
+		return
+	}
 	if err != nil {
 		t.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
 		return
 	}
 
-	outputMsgCh, err := t.receiver.Receive(r.Context(), sessionID, inputMsg)
+	t.interceptor.intercept(DirectionInbound, inputMsg)
+	outputMsgCh, err := t.receiver.Receive(withTraceParentHeader(r), sessionID, inputMsg)
 	if err != nil {
 		t.writeError(w, http.StatusBadRequest, fmt.Sprintf("Failed to receive: %v", err))
 		return
@@ -305,7 +481,7 @@ func (t *sseServerTransport) handleMessage(w http.ResponseWriter, r *http.Reques
 	}
 
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 
 		for msg := range outputMsgCh {
 			if e := t.Send(context.Background(), sessionID, msg); e != nil {
@@ -319,14 +495,18 @@ func (t *sseServerTransport) handleMessage(w http.ResponseWriter, r *http.Reques
 func (t *sseServerTransport) writeError(w http.ResponseWriter, code int, message string) {
 	t.logger.Errorf("sseServerTransport Error: code: %d, message: %s", code, message)
 
-	w.Header().Set("Content-Type", "text/plain")
-	w.WriteHeader(code)
-	if _, err := w.Write([]byte(message)); err != nil {
+	format := t.errorResponseFormat
+	if format == ErrorResponseFormatDefault {
+		format = ErrorResponseFormatPlainText
+	}
+	if err := writeErrorResponse(w, format, code, message); err != nil {
 		t.logger.Errorf("sseServerTransport writeError: %+v", err)
 	}
 }
 
 func (t *sseServerTransport) Shutdown(userCtx context.Context, serverCtx context.Context) error {
+	t.health.setReady(false)
+
 	shutdownFunc := func() {
 		<-serverCtx.Done()
 
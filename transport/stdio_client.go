@@ -28,6 +28,20 @@ func WithStdioClientOptionEnv(env ...string) StdioClientTransportOption {
 	}
 }
 
+// WithStdioClientOptionFraming selects how messages are delimited, it must match the framing of the server
+func WithStdioClientOptionFraming(framing StdioFraming) StdioClientTransportOption {
+	return func(t *stdioClientTransport) {
+		t.framing = framing
+	}
+}
+
+// WithStdioClientOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithStdioClientOptionInterceptor(interceptor Interceptor) StdioClientTransportOption {
+	return func(t *stdioClientTransport) {
+		t.interceptor = interceptor
+	}
+}
+
 const mcpMessageDelimiter = '\n'
 
 type stdioClientTransport struct {
@@ -35,8 +49,12 @@ type stdioClientTransport struct {
 	receiver  clientReceiver
 	reader    io.Reader
 	writer    io.WriteCloser
+	writeMu   sync.Mutex // concurrent requests and responses are written one message at a time
+	framing   StdioFraming
 	errReader io.Reader
 
+	interceptor Interceptor
+
 	logger pkg.Logger
 
 	wg     sync.WaitGroup
@@ -88,7 +106,7 @@ func (t *stdioClientTransport) Start() error {
 
 	t.wg.Add(1)
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 		defer t.wg.Done()
 
 		t.startReceive(innerCtx)
@@ -96,7 +114,7 @@ func (t *stdioClientTransport) Start() error {
 
 	t.wg.Add(1)
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 		defer t.wg.Done()
 
 		t.startReceiveErr(innerCtx)
@@ -106,8 +124,11 @@ func (t *stdioClientTransport) Start() error {
 }
 
 func (t *stdioClientTransport) Send(_ context.Context, msg Message) error {
-	_, err := t.writer.Write(append(msg, mcpMessageDelimiter))
-	return err
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.interceptor.intercept(DirectionOutbound, msg)
+	return t.framing.writeMessage(t.writer, msg)
 }
 
 func (t *stdioClientTransport) SetReceiver(receiver clientReceiver) {
@@ -134,7 +155,7 @@ func (t *stdioClientTransport) startReceive(ctx context.Context) {
 	s := bufio.NewReader(t.reader)
 
 	for {
-		line, err := s.ReadBytes('\n')
+		line, err := t.framing.readMessage(s, 0)
 		if err != nil {
 			t.receiver.Interrupt(fmt.Errorf("stdout read error: %w", err))
 
@@ -146,7 +167,6 @@ func (t *stdioClientTransport) startReceive(ctx context.Context) {
 			return
 		}
 
-		line = bytes.TrimRight(line, "\n")
 		// filter empty messages
 		// filter space messages and \t messages
 		if len(bytes.TrimFunc(line, func(r rune) bool { return r == ' ' || r == '\t' })) == 0 {
@@ -158,6 +178,7 @@ func (t *stdioClientTransport) startReceive(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		default:
+			t.interceptor.intercept(DirectionInbound, line)
 			if err = t.receiver.Receive(ctx, line); err != nil {
 				t.logger.Errorf("receiver failed: %v", err)
 			}
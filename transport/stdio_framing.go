@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// StdioFraming selects how messages are delimited on the stdio streams
+type StdioFraming int
+
+const (
+	// StdioFramingNewline writes every message as a single line of JSON, this is the default
+	StdioFramingNewline StdioFraming = iota
+	// StdioFramingContentLength precedes every message with a Content-Length header block, as the Language Server Protocol does
+	StdioFramingContentLength
+)
+
+const contentLengthHeader = "Content-Length"
+
+var (
+	errMissingContentLength = errors.New("missing Content-Length header")
+	errInvalidContentLength = errors.New("invalid Content-Length header")
+)
+
+// isFramingError reports whether err is a malformed message that readMessage consumed,
+// so that reading can go on with the next message
+func isFramingError(err error) bool {
+	var protocolErr textproto.ProtocolError
+	return errors.Is(err, errMissingContentLength) || errors.Is(err, errInvalidContentLength) ||
+		errors.As(err, &protocolErr)
+}
+
+// writeMessage writes msg with a single Write call, so that the caller only has to serialize the calls
+func (f StdioFraming) writeMessage(w io.Writer, msg []byte) error {
+	var frame []byte
+	switch f {
+	case StdioFramingContentLength:
+		frame = append([]byte(fmt.Sprintf("%s: %d\r\n\r\n", contentLengthHeader, len(msg))), msg...)
+	default:
+		frame = append(msg, mcpMessageDelimiter)
+	}
+	_, err := w.Write(frame)
+	return err
+}
+
+// readMessage reads the next message, a malformed header block or a message larger than maxSize bytes
+// is reported after it has been consumed so that reading can go on with the next message.
+// A non-positive maxSize disables the limit.
+func (f StdioFraming) readMessage(r *bufio.Reader, maxSize int) ([]byte, error) {
+	if f != StdioFramingContentLength {
+		for {
+			line, err := readLine(r, maxSize)
+			// stray blank lines, common from shell-driven clients, are skipped rather than answered with a parse error
+			if err == nil && len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			return bytes.TrimRight(line, "\n"), err
+		}
+	}
+
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if len(header) == 0 && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+			// the stream ended between two messages
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	value := header.Get(contentLengthHeader)
+	if value == "" {
+		return nil, errMissingContentLength
+	}
+	length, err := strconv.Atoi(value)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("%w: %q", errInvalidContentLength, value)
+	}
+	if maxSize > 0 && length > maxSize {
+		if _, err = io.CopyN(io.Discard, r, int64(length)); err != nil {
+			return nil, err
+		}
+		return nil, errMessageTooLarge
+	}
+
+	msg := make([]byte, length)
+	if _, err = io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readLine reads up to and including the next delimiter, the part of a line beyond maxSize bytes
+// is discarded instead of being buffered
+func readLine(r *bufio.Reader, maxSize int) ([]byte, error) {
+	var (
+		line     []byte
+		tooLarge bool
+	)
+	for {
+		chunk, err := r.ReadSlice(mcpMessageDelimiter)
+		if !tooLarge {
+			line = append(line, chunk...)
+			// the delimiter does not count
+			if maxSize > 0 && len(bytes.TrimRight(line, "\n")) > maxSize {
+				tooLarge, line = true, nil
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if tooLarge && err == nil {
+			return nil, errMessageTooLarge
+		}
+		return line, err
+	}
+}
@@ -2,14 +2,16 @@ package transport
 
 import (
 	"bufio"
-	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 
 	"github.com/hhfgeg/go-mcp/pkg"
+	"github.com/hhfgeg/go-mcp/protocol"
 )
 
 type StdioServerTransportOption func(*stdioServerTransport)
@@ -20,10 +22,39 @@ func WithStdioServerOptionLogger(log pkg.Logger) StdioServerTransportOption {
 	}
 }
 
+// WithStdioServerOptionFraming selects how messages are delimited, newline-delimited JSON by default
+func WithStdioServerOptionFraming(framing StdioFraming) StdioServerTransportOption {
+	return func(t *stdioServerTransport) {
+		t.framing = framing
+	}
+}
+
+// WithStdioServerOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithStdioServerOptionInterceptor(interceptor Interceptor) StdioServerTransportOption {
+	return func(t *stdioServerTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+// WithStdioServerOptionMaxMessageSize bounds the size of a single inbound message to size bytes,
+// DefaultMaxMessageSize by default. A larger message is discarded and answered with a JSON-RPC error,
+// a non-positive size removes the limit.
+func WithStdioServerOptionMaxMessageSize(size int) StdioServerTransportOption {
+	return func(t *stdioServerTransport) {
+		t.maxMessageSize = size
+	}
+}
+
 type stdioServerTransport struct {
 	receiver serverReceiver
-	reader   io.ReadCloser
+	reader   io.Reader
 	writer   io.Writer
+	writeMu  sync.Mutex // responses of concurrent requests are written one message at a time
+	framing  StdioFraming
+
+	maxMessageSize int
+
+	interceptor Interceptor
 
 	sessionManager sessionManager
 	sessionID      string
@@ -35,11 +66,19 @@ type stdioServerTransport struct {
 }
 
 func NewStdioServerTransport(opts ...StdioServerTransportOption) ServerTransport {
+	return NewStdioServerTransportWithIO(os.Stdin, os.Stdout, opts...)
+}
+
+// NewStdioServerTransportWithIO returns transport reading messages from r and writing them to w instead of stdin and stdout,
+// r is closed on shutdown if it is an io.Closer
+func NewStdioServerTransportWithIO(r io.Reader, w io.Writer, opts ...StdioServerTransportOption) ServerTransport {
 	t := &stdioServerTransport{
-		reader: os.Stdin,
-		writer: os.Stdout,
+		reader: r,
+		writer: w,
 		logger: pkg.DefaultLogger,
 
+		maxMessageSize: DefaultMaxMessageSize,
+
 		receiveShutDone: make(chan struct{}),
 	}
 
@@ -62,7 +101,11 @@ func (t *stdioServerTransport) Run() error {
 }
 
 func (t *stdioServerTransport) Send(_ context.Context, _ string, msg Message) error {
-	if _, err := t.writer.Write(append(msg, mcpMessageDelimiter)); err != nil {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.interceptor.intercept(DirectionOutbound, msg)
+	if err := t.framing.writeMessage(t.writer, msg); err != nil {
 		return fmt.Errorf("failed to write: %w", err)
 	}
 	return nil
@@ -79,8 +122,10 @@ func (t *stdioServerTransport) SetSessionManager(m sessionManager) {
 func (t *stdioServerTransport) Shutdown(userCtx context.Context, serverCtx context.Context) error {
 	t.cancel()
 
-	if err := t.reader.Close(); err != nil {
-		return err
+	if closer, ok := t.reader.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
 	}
 
 	select {
@@ -97,26 +142,38 @@ func (t *stdioServerTransport) startReceive(ctx context.Context) {
 	s := bufio.NewReader(t.reader)
 
 	for {
-		line, err := s.ReadBytes('\n')
-		if err != nil {
-			if errors.Is(err, io.ErrClosedPipe) || // This error occurs during unit tests, suppressing it here
-				errors.Is(err, io.EOF) {
-				return
-			}
-			t.logger.Errorf("client receive unexpected error reading input: %v", err)
-		}
-		line = bytes.TrimRight(line, "\n")
+		line, err := t.framing.readMessage(s, t.maxMessageSize)
 
 		select {
 		case <-ctx.Done():
 			return
 		default:
-			t.receive(ctx, line)
 		}
+
+		if err != nil {
+			switch {
+			case errors.Is(err, errMessageTooLarge):
+				t.replyMessageTooLarge()
+				continue
+			case isFramingError(err):
+				t.logger.Errorf("client receive malformed message: %v", err)
+				continue
+			case errors.Is(err, io.ErrClosedPipe) || // This error occurs during unit tests, suppressing it here
+				errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed):
+				return
+			default:
+				// the input can't be read any further, e.g. a broken pipe
+				t.logger.Errorf("client receive unexpected error reading input: %v", err)
+				return
+			}
+		}
+
+		t.receive(ctx, line)
 	}
 }
 
 func (t *stdioServerTransport) receive(ctx context.Context, line []byte) {
+	t.interceptor.intercept(DirectionInbound, line)
 	outputMsgCh, err := t.receiver.Receive(ctx, t.sessionID, line)
 	if err != nil {
 		t.logger.Errorf("receiver failed: %v", err)
@@ -128,7 +185,7 @@ func (t *stdioServerTransport) receive(ctx context.Context, line []byte) {
 	}
 
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 
 		for msg := range outputMsgCh {
 			if e := t.Send(context.Background(), t.sessionID, msg); e != nil {
@@ -137,3 +194,18 @@ func (t *stdioServerTransport) receive(ctx context.Context, line []byte) {
 		}
 	}()
 }
+
+// replyMessageTooLarge answers a discarded oversized message, its id is unknown since it was never parsed
+func (t *stdioServerTransport) replyMessageTooLarge() {
+	message := fmt.Sprintf("message exceeds the maximum message size of %d bytes", t.maxMessageSize)
+	t.logger.Warnf("stdioServerTransport: %s", message)
+
+	resp, err := json.Marshal(protocol.NewJSONRPCErrorResponse(nil, protocol.InvalidRequest, message))
+	if err != nil {
+		t.logger.Errorf("stdioServerTransport: json.Marshal: %+v", err)
+		return
+	}
+	if err = t.Send(context.Background(), t.sessionID, resp); err != nil {
+		t.logger.Errorf("Failed to send message: %v", err)
+	}
+}
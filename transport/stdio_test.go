@@ -1,6 +1,10 @@
 package transport
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -8,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -89,3 +94,119 @@ func compileMockStdioServerTr(outputPath string) error {
 
 	return nil
 }
+
+func TestStdioFraming(t *testing.T) {
+	messages := []string{`{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{"jsonrpc":"2.0","id":1,"result":{}}`}
+
+	for _, framing := range []StdioFraming{StdioFramingNewline, StdioFramingContentLength} {
+		var buf bytes.Buffer
+		for _, msg := range messages {
+			if err := framing.writeMessage(&buf, []byte(msg)); err != nil {
+				t.Fatalf("framing %d: writeMessage: %v", framing, err)
+			}
+		}
+
+		r := bufio.NewReader(&buf)
+		for _, want := range messages {
+			msg, err := framing.readMessage(r, 0)
+			if err != nil {
+				t.Fatalf("framing %d: readMessage: %v", framing, err)
+			}
+			if string(msg) != want {
+				t.Fatalf("framing %d: got %s, want %s", framing, msg, want)
+			}
+		}
+		if _, err := framing.readMessage(r, 0); !errors.Is(err, io.EOF) {
+			t.Fatalf("framing %d: expected io.EOF at the end of the stream, got %v", framing, err)
+		}
+	}
+
+	r := bufio.NewReader(strings.NewReader("\n \t\r\n" + messages[0] + "\n\n"))
+	if msg, err := StdioFramingNewline.readMessage(r, 0); err != nil || string(msg) != messages[0] {
+		t.Fatalf("expected blank lines to be skipped, got %s, %v", msg, err)
+	}
+	if _, err := StdioFramingNewline.readMessage(r, 0); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF after a trailing blank line, got %v", err)
+	}
+
+	r = bufio.NewReader(strings.NewReader("Content-Type: application/json\r\n\r\n{}Content-Length: 2\r\n\r\n{}"))
+	if _, err := StdioFramingContentLength.readMessage(r, 0); !errors.Is(err, errMissingContentLength) {
+		t.Fatalf("expected errMissingContentLength, got %v", err)
+	}
+}
+
+func TestStdioServerTransportWithIO(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	server := NewStdioServerTransportWithIO(inReader, outWriter, WithStdioServerOptionFraming(StdioFramingContentLength))
+	server.SetSessionManager(newMockSessionManager())
+	server.SetReceiver(ServerReceiverF(func(_ context.Context, _ string, msg []byte) (<-chan []byte, error) {
+		msgCh := make(chan []byte, 1)
+		msgCh <- msg
+		close(msgCh)
+		return msgCh, nil
+	}))
+	go func() {
+		_ = server.Run()
+	}()
+	defer func() {
+		serverCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_ = server.Shutdown(context.Background(), serverCtx)
+	}()
+
+	msg := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	go func() {
+		_, _ = fmt.Fprintf(inWriter, "Content-Length: %d\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n%s", len(msg), msg)
+	}()
+
+	got, err := StdioFramingContentLength.readMessage(bufio.NewReader(outReader), 0)
+	if err != nil {
+		t.Fatalf("readMessage: %v", err)
+	}
+	if string(got) != msg {
+		t.Fatalf("got %s, want %s", got, msg)
+	}
+}
+
+func TestStdioServerTransportShutdownStopsReceive(t *testing.T) {
+	// os.Stdin is an *os.File, reading it after Close fails with os.ErrClosed
+	inReader, inWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	defer inWriter.Close()
+
+	server := NewStdioServerTransportWithIO(inReader, io.Discard)
+	server.SetSessionManager(newMockSessionManager())
+	received := make(chan struct{}, 1)
+	server.SetReceiver(ServerReceiverF(func(context.Context, string, []byte) (<-chan []byte, error) {
+		received <- struct{}{}
+		return nil, nil
+	}))
+
+	runDone := make(chan struct{})
+	go func() {
+		defer close(runDone)
+		_ = server.Run()
+	}()
+
+	// wait for Run to start receiving
+	if _, err = inWriter.Write([]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	<-received
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = server.Shutdown(ctx, ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	select {
+	case <-runDone:
+	case <-time.After(time.Second):
+		t.Fatal("the receive goroutine did not exit after Shutdown")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,7 +18,9 @@ import (
 
 const sessionIDHeader = "Mcp-Session-Id"
 
-// const eventIDHeader = "Last-Event-ID"
+const eventIDHeader = "Last-Event-ID"
+
+var errSSEStreamClosed = errors.New("SSE stream closed")
 
 type StreamableHTTPClientTransportOption func(*streamableHTTPClientTransport)
 
@@ -39,6 +42,30 @@ func WithStreamableHTTPClientOptionLogger(log pkg.Logger) StreamableHTTPClientTr
 	}
 }
 
+// WithStreamableHTTPClientOptionReconnect reconnects a lost GET stream up to maxRetries consecutive times, waiting
+// baseDelay doubled with every attempt and randomized by jitter in between. The stream is resumed with the session ID
+// and the Last-Event-ID, without initializing again. Sends don't depend on the stream and go on meanwhile.
+// Once the server no longer knows the session the stream waits for the client to initialize a new one.
+func WithStreamableHTTPClientOptionReconnect(maxRetries int, baseDelay time.Duration) StreamableHTTPClientTransportOption {
+	return func(t *streamableHTTPClientTransport) {
+		t.reconnect = newReconnector(maxRetries, baseDelay)
+	}
+}
+
+// WithStreamableHTTPClientOptionReconnectHandler observes the reconnect attempts enabled by WithStreamableHTTPClientOptionReconnect
+func WithStreamableHTTPClientOptionReconnectHandler(handler func(ReconnectEvent)) StreamableHTTPClientTransportOption {
+	return func(t *streamableHTTPClientTransport) {
+		t.onReconnect = handler
+	}
+}
+
+// WithStreamableHTTPClientOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithStreamableHTTPClientOptionInterceptor(interceptor Interceptor) StreamableHTTPClientTransportOption {
+	return func(t *streamableHTTPClientTransport) {
+		t.interceptor = interceptor
+	}
+}
+
 type streamableHTTPClientTransport struct {
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -47,10 +74,16 @@ type streamableHTTPClientTransport struct {
 	receiver  clientReceiver
 	sessionID *pkg.AtomicString
 
+	lastEventID *pkg.AtomicString // id of the last received event, sent as Last-Event-ID on reconnect
+
 	// options
 	logger         pkg.Logger
 	receiveTimeout time.Duration
 	client         *http.Client
+	interceptor    Interceptor
+
+	reconnect   *reconnector
+	onReconnect func(ReconnectEvent)
 
 	sseInFlyConnect sync.WaitGroup
 }
@@ -68,6 +101,7 @@ func NewStreamableHTTPClientTransport(serverURL string, opts ...StreamableHTTPCl
 		cancel:         cancel,
 		serverURL:      parsedURL,
 		sessionID:      pkg.NewAtomicString(),
+		lastEventID:    pkg.NewAtomicString(),
 		logger:         pkg.DefaultLogger,
 		receiveTimeout: time.Second * 30,
 		client:         http.DefaultClient,
@@ -77,6 +111,10 @@ func NewStreamableHTTPClientTransport(serverURL string, opts ...StreamableHTTPCl
 		opt(t)
 	}
 
+	if t.reconnect != nil {
+		t.reconnect.onReconnect = t.onReconnect
+	}
+
 	return t, nil
 }
 
@@ -84,7 +122,7 @@ func (t *streamableHTTPClientTransport) Start() error {
 	// Start a GET stream for server-initiated messages
 	t.sseInFlyConnect.Add(1)
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 		defer t.sseInFlyConnect.Done()
 
 		t.startSSEStream()
@@ -93,6 +131,7 @@ func (t *streamableHTTPClientTransport) Start() error {
 }
 
 func (t *streamableHTTPClientTransport) Send(ctx context.Context, msg Message) error {
+	t.interceptor.intercept(DirectionOutbound, msg)
 	req, err := http.NewRequestWithContext(t.ctx, http.MethodPost, t.serverURL.String(), bytes.NewReader(msg))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -138,7 +177,7 @@ func (t *streamableHTTPClientTransport) Send(ctx context.Context, msg Message) e
 	switch {
 	case contentType == "text/event-stream":
 		go func() {
-			defer pkg.Recover()
+			defer pkg.RecoverWithLogger(t.logger, nil)
 
 			t.sseInFlyConnect.Add(1)
 			defer t.sseInFlyConnect.Done()
@@ -151,6 +190,7 @@ func (t *streamableHTTPClientTransport) Send(ctx context.Context, msg Message) e
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
+		t.interceptor.intercept(DirectionInbound, body)
 		if err = t.receiver.Receive(ctx, body); err != nil {
 			return fmt.Errorf("failed to process response: %w", err)
 		}
@@ -164,53 +204,83 @@ func (t *streamableHTTPClientTransport) startSSEStream() {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
+	retryNow := false      // the backoff already waited, reconnect without waiting for the ticker
+	expiredSessionID := "" // the session the server no longer knows, the stream is reopened for the next one
 	for {
-		select {
-		case <-t.ctx.Done():
-			return
-		case <-ticker.C:
-			sessionID := t.sessionID.Load()
-			if sessionID == "" {
-				continue // Try again after 1 second, waiting for the POST request to initialize the SessionID to complete
-			}
-
-			req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.serverURL.String(), nil)
-			if err != nil {
-				t.logger.Errorf("failed to create SSE request: %v", err)
+		if !retryNow {
+			select {
+			case <-t.ctx.Done():
 				return
+			case <-ticker.C:
 			}
+		}
+		retryNow = false
 
-			req.Header.Set("Accept", "text/event-stream")
-			req.Header.Set(sessionIDHeader, sessionID)
+		sessionID := t.sessionID.Load()
+		if sessionID == "" || sessionID == expiredSessionID {
+			continue // Try again after 1 second, waiting for the POST request to initialize the SessionID to complete
+		}
 
-			resp, err := t.client.Do(req)
-			if err != nil {
-				select {
-				case <-t.ctx.Done():
+		req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.serverURL.String(), nil)
+		if err != nil {
+			t.logger.Errorf("failed to create SSE request: %v", err)
+			return
+		}
+
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(sessionIDHeader, sessionID)
+		if lastEventID := t.lastEventID.Load(); lastEventID != "" {
+			req.Header.Set(eventIDHeader, lastEventID)
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			select {
+			case <-t.ctx.Done():
+				return
+			default:
+			}
+			t.logger.Errorf("failed to connect to SSE stream: %v", err)
+			if t.reconnect != nil {
+				if !t.reconnect.backoff(t.ctx, err) {
 					return
-				default:
 				}
-				t.logger.Errorf("failed to connect to SSE stream: %v", err)
-				continue
+				retryNow = true
 			}
+			continue
+		}
 
-			if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-				resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			resp.Body.Close()
 
-				switch resp.StatusCode {
-				case http.StatusMethodNotAllowed:
-					t.logger.Infof("server does not support SSE streaming")
-					return
-				case http.StatusNotFound:
-					t.logger.Infof("%+v", pkg.ErrSessionClosed)
-					continue // Try again after 1 second, waiting for the POST request again to initialize the SessionID to complete
-				default:
-					t.logger.Infof("unexpected status code: %d, status: %s", resp.StatusCode, resp.Status)
+			switch resp.StatusCode {
+			case http.StatusMethodNotAllowed:
+				t.logger.Infof("server does not support SSE streaming")
+				return
+			case http.StatusNotFound:
+				t.logger.Warnf("SSE stream: %+v, sessionID=%s", pkg.ErrSessionClosed, sessionID)
+				// the missed events are gone with the session, start over with the latest ones
+				t.lastEventID.Store("")
+				expiredSessionID = sessionID
+				t.reconnect.sessionExpired()
+				continue // the next POST fails with pkg.ErrSessionClosed, the client initializes a new session
+			default:
+				t.logger.Infof("unexpected status code: %d, status: %s", resp.StatusCode, resp.Status)
+				if t.reconnect == nil || !t.reconnect.backoff(t.ctx, fmt.Errorf("unexpected status code: %d", resp.StatusCode)) {
 					return
 				}
+				retryNow = true
+				continue
 			}
+		}
 
-			t.handleSSEStream(resp.Body)
+		t.reconnect.connected()
+		t.handleSSEStream(resp.Body)
+		if t.reconnect != nil && t.ctx.Err() == nil {
+			if !t.reconnect.backoff(t.ctx, errSSEStreamClosed) {
+				return
+			}
+			retryNow = true
 		}
 	}
 }
@@ -253,6 +323,8 @@ func (t *streamableHTTPClientTransport) handleSSEStream(reader io.ReadCloser) {
 
 		if strings.HasPrefix(line, "data:") {
 			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		} else if strings.HasPrefix(line, "id:") {
+			t.lastEventID.Store(strings.TrimSpace(strings.TrimPrefix(line, "id:")))
 		}
 	}
 }
@@ -261,6 +333,7 @@ func (t *streamableHTTPClientTransport) processSSEEvent(data string) {
 	ctx, cancel := context.WithTimeout(t.ctx, t.receiveTimeout)
 	defer cancel()
 
+	t.interceptor.intercept(DirectionInbound, []byte(data))
 	if err := t.receiver.Receive(ctx, []byte(data)); err != nil {
 		t.logger.Errorf("Error processing SSE event: %v", err)
 	}
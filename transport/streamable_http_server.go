@@ -2,13 +2,14 @@ package transport
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hhfgeg/go-mcp/pkg"
@@ -48,6 +49,96 @@ func WithStreamableHTTPServerTransportOptionStateMode(mode StateMode) Streamable
 	}
 }
 
+// WithStreamableHTTPServerTransportOptionBearerTokenValidator requires every request to carry a bearer token accepted by validator
+func WithStreamableHTTPServerTransportOptionBearerTokenValidator(validator BearerTokenValidator) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.bearerTokenValidator = validator
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionTLSConfig serves HTTPS with the given TLS configuration
+func WithStreamableHTTPServerTransportOptionTLSConfig(config *tls.Config) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.tlsConfig = config
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionTLSCertFile serves HTTPS with the certificate and key loaded from the given files
+func WithStreamableHTTPServerTransportOptionTLSCertFile(certFile, keyFile string) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.certFile = certFile
+		t.keyFile = keyFile
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionCompression gzips responses for clients accepting it and decodes gzip encoded request bodies
+func WithStreamableHTTPServerTransportOptionCompression() StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.compression = true
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionErrorResponseFormat sets the body format of the HTTP error responses,
+// JSON-RPC by default
+func WithStreamableHTTPServerTransportOptionErrorResponseFormat(format ErrorResponseFormat) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.errorResponseFormat = format
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionEventBuffer buffers the latest size events sent on the GET stream of each session,
+// so that a client reconnecting with Last-Event-ID gets the events it missed. Clients resuming after older events
+// were dropped are told to re-initialize the session.
+func WithStreamableHTTPServerTransportOptionEventBuffer(size int) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.eventBufferSize = size
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionCORS answers preflight requests and sets the CORS headers of cross-origin requests
+func WithStreamableHTTPServerTransportOptionCORS(options CORSOptions) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.cors = &options
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithStreamableHTTPServerTransportOptionInterceptor(interceptor Interceptor) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionHealthEndpoints serves a liveness probe on the first path and a readiness probe
+// on the second one, /healthz and /readyz by default. The readiness probe fails until the server listens and
+// again once it shuts down, so that traffic drains.
+func WithStreamableHTTPServerTransportOptionHealthEndpoints(paths ...string) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.health = newHealthEndpoints(paths)
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionMaxMessageSize bounds the size of a single inbound message to size bytes,
+// DefaultMaxMessageSize by default. A larger request body is answered with 413 Request Entity Too Large
+// without being buffered, a non-positive size removes the limit.
+func WithStreamableHTTPServerTransportOptionMaxMessageSize(size int) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.maxMessageSize = size
+	}
+}
+
+// WithStreamableHTTPServerTransportOptionHTTPTimeouts sets the timeouts of the HTTP server, none by default except
+// for an idle timeout of one minute. read bounds reading a request including its body and write bounds writing
+// the responses that are no event stream, e.g. errors, so that slow clients can't hold connections forever.
+// Event streams, i.e. the GET stream and the answers of posted requests, are exempt from both once open,
+// so that long-lived streams aren't severed.
+// idle bounds how long a keep-alive connection waits for the next request, a non-positive duration keeps the default.
+func WithStreamableHTTPServerTransportOptionHTTPTimeouts(read, write, idle time.Duration) StreamableHTTPServerTransportOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.timeouts = httpTimeouts{read: read, write: write, idle: idle}
+	}
+}
+
 type StreamableHTTPServerTransportAndHandlerOption func(*streamableHTTPServerTransport)
 
 func WithStreamableHTTPServerTransportAndHandlerOptionLogger(logger pkg.Logger) StreamableHTTPServerTransportAndHandlerOption {
@@ -62,6 +153,60 @@ func WithStreamableHTTPServerTransportAndHandlerOptionStateMode(mode StateMode)
 	}
 }
 
+// WithStreamableHTTPServerTransportAndHandlerOptionBearerTokenValidator requires every request to carry a bearer token accepted by validator
+func WithStreamableHTTPServerTransportAndHandlerOptionBearerTokenValidator(validator BearerTokenValidator) StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.bearerTokenValidator = validator
+	}
+}
+
+// WithStreamableHTTPServerTransportAndHandlerOptionCompression gzips responses for clients accepting it and decodes gzip encoded request bodies
+func WithStreamableHTTPServerTransportAndHandlerOptionCompression() StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.compression = true
+	}
+}
+
+// WithStreamableHTTPServerTransportAndHandlerOptionErrorResponseFormat sets the body format of the HTTP error responses,
+// JSON-RPC by default
+func WithStreamableHTTPServerTransportAndHandlerOptionErrorResponseFormat(format ErrorResponseFormat) StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.errorResponseFormat = format
+	}
+}
+
+// WithStreamableHTTPServerTransportAndHandlerOptionEventBuffer buffers the latest size events sent on the GET stream of each
+// session, so that a client reconnecting with Last-Event-ID gets the events it missed. Clients resuming after older events
+// were dropped are told to re-initialize the session.
+func WithStreamableHTTPServerTransportAndHandlerOptionEventBuffer(size int) StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.eventBufferSize = size
+	}
+}
+
+// WithStreamableHTTPServerTransportAndHandlerOptionCORS answers preflight requests and sets the CORS headers of cross-origin requests
+func WithStreamableHTTPServerTransportAndHandlerOptionCORS(options CORSOptions) StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.cors = &options
+	}
+}
+
+// WithStreamableHTTPServerTransportAndHandlerOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithStreamableHTTPServerTransportAndHandlerOptionInterceptor(interceptor Interceptor) StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+// WithStreamableHTTPServerTransportAndHandlerOptionMaxMessageSize bounds the size of a single inbound message to size bytes,
+// DefaultMaxMessageSize by default. A larger request body is answered with 413 Request Entity Too Large
+// without being buffered, a non-positive size removes the limit.
+func WithStreamableHTTPServerTransportAndHandlerOptionMaxMessageSize(size int) StreamableHTTPServerTransportAndHandlerOption {
+	return func(t *streamableHTTPServerTransport) {
+		t.maxMessageSize = size
+	}
+}
+
 type streamableHTTPServerTransport struct {
 	// ctx is the context that controls the lifecycle of the server
 	ctx    context.Context
@@ -73,6 +218,9 @@ type streamableHTTPServerTransport struct {
 
 	inFlySend sync.WaitGroup
 
+	inFlyRequest int64 // POST requests being handled, accessed atomically
+	draining     int32 // set once Drain is called, accessed atomically
+
 	receiver serverReceiver
 
 	sessionManager sessionManager
@@ -80,6 +228,28 @@ type streamableHTTPServerTransport struct {
 	// options
 	logger      pkg.Logger
 	mcpEndpoint string // The single MCP endpoint path
+
+	bearerTokenValidator BearerTokenValidator
+
+	errorResponseFormat ErrorResponseFormat
+
+	eventBufferSize int
+	eventBuffers    pkg.SyncMap[*eventBuffer] // events sent on the GET stream of each session, for resumption
+	undelivered     pkg.SyncMap[[]byte]       // the message a GET stream failed to write, retried on the next stream
+
+	compression bool
+
+	maxMessageSize int
+
+	timeouts httpTimeouts // of the HTTP server the transport listens with, unused by the AndHandler variant
+
+	cors *CORSOptions
+
+	interceptor Interceptor
+
+	health *healthEndpoints // nil unless health endpoints are enabled
+
+	tlsOptions
 }
 
 type StreamableHTTPHandler struct {
@@ -106,10 +276,11 @@ func NewStreamableHTTPServerTransportAndHandler(
 	ctx, cancel := context.WithCancel(context.Background())
 
 	t := &streamableHTTPServerTransport{
-		ctx:       ctx,
-		cancel:    cancel,
-		stateMode: Stateless,
-		logger:    pkg.DefaultLogger,
+		ctx:            ctx,
+		cancel:         cancel,
+		stateMode:      Stateless,
+		logger:         pkg.DefaultLogger,
+		maxMessageSize: DefaultMaxMessageSize,
 	}
 
 	for _, opt := range opts {
@@ -128,6 +299,8 @@ func NewStreamableHTTPServerTransport(addr string, opts ...StreamableHTTPServerT
 		stateMode:   Stateless,
 		logger:      pkg.DefaultLogger,
 		mcpEndpoint: "/mcp", // Default MCP endpoint
+
+		maxMessageSize: DefaultMaxMessageSize,
 	}
 
 	for _, opt := range opts {
@@ -136,12 +309,9 @@ func NewStreamableHTTPServerTransport(addr string, opts ...StreamableHTTPServerT
 
 	mux := http.NewServeMux()
 	mux.HandleFunc(t.mcpEndpoint, t.handleMCPEndpoint)
+	t.health.register(mux)
 
-	t.httpSvr = &http.Server{
-		Addr:        addr,
-		Handler:     mux,
-		IdleTimeout: time.Minute,
-	}
+	t.httpSvr = newHTTPServer(addr, mux, t.timeouts)
 
 	return t
 }
@@ -152,9 +322,11 @@ func (t *streamableHTTPServerTransport) Run() error {
 		return nil
 	}
 
-	fmt.Printf("starting mcp server at http://%s%s\n", t.httpSvr.Addr, t.mcpEndpoint)
+	fmt.Printf("starting mcp server at %s://%s%s\n", t.scheme(), t.httpSvr.Addr, t.mcpEndpoint)
 
-	if err := t.httpSvr.ListenAndServe(); err != nil {
+	t.health.setReady(true)
+	defer t.health.setReady(false)
+	if err := t.listenAndServe(t.httpSvr); err != nil {
 		return fmt.Errorf("failed to start HTTP server: %w", err)
 	}
 	return nil
@@ -180,11 +352,36 @@ func (t *streamableHTTPServerTransport) SetSessionManager(manager sessionManager
 	t.sessionManager = manager
 }
 
+func (t *streamableHTTPServerTransport) SupportsStreaming() bool {
+	return true
+}
+
+func (t *streamableHTTPServerTransport) OnSessionClosed(sessionID string) {
+	t.eventBuffers.Delete(sessionID)
+	t.undelivered.Delete(sessionID)
+}
+
 func (t *streamableHTTPServerTransport) handleMCPEndpoint(w http.ResponseWriter, r *http.Request) {
-	defer pkg.RecoverWithFunc(func(_ any) {
+	defer pkg.RecoverWithLogger(t.logger, func(_ any) {
 		t.writeError(w, http.StatusInternalServerError, "Internal server error")
 	})
 
+	var exposedHeaders []string
+	if t.stateMode == Stateful {
+		exposedHeaders = append(exposedHeaders, sessionIDHeader)
+	}
+	if t.cors.handleCORS(w, r, exposedHeaders...) {
+		return
+	}
+
+	r, ok := authenticateBearer(w, r, t.bearerTokenValidator, t.logger, t.writeError)
+	if !ok {
+		return
+	}
+
+	w, closeCompression := compressResponse(t.compression, w, r)
+	defer closeCompression()
+
 	switch r.Method {
 	case http.MethodPost:
 		t.handlePost(w, r)
@@ -206,19 +403,32 @@ func (t *streamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *htt
 	}
 
 	// Read and process the message
-	bs, err := io.ReadAll(r.Body)
+	bs, err := readRequestBody(t.compression, t.maxMessageSize, r)
+	if errors.Is(err, errMessageTooLarge) {
+		writeMessageTooLarge(w, t.writeError, t.maxMessageSize)
+		return
+	}
 	if err != nil {
 		t.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request: %v", err))
 		return
 	}
 
-	ctx := r.Context()
+	atomic.AddInt64(&t.inFlyRequest, 1)
+	defer atomic.AddInt64(&t.inFlyRequest, -1)
+
+	if t.isDraining() && protocol.IsInitializedRequest(bs) {
+		t.writeError(w, http.StatusServiceUnavailable, "Server is draining, connect to another instance")
+		return
+	}
+
+	ctx := withTraceParentHeader(r)
 
 	// For InitializeRequest HTTP response
 	if t.stateMode == Stateful {
 		ctx = context.WithValue(ctx, SessionIDForReturnKey{}, &SessionIDForReturn{})
 	}
 
+	t.interceptor.intercept(DirectionInbound, bs)
 	outputMsgCh, err := t.receiver.Receive(ctx, r.Header.Get(sessionIDHeader), bs)
 	if err != nil {
 		if errors.Is(err, pkg.ErrSessionClosed) {
@@ -241,6 +451,8 @@ func (t *streamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *htt
 		return
 	}
 
+	exemptFromTimeouts(r)
+
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -251,6 +463,7 @@ func (t *streamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *htt
 		if t.stateMode == Stateful {
 			w.Header().Set(sessionIDHeader, ctx.Value(SessionIDForReturnKey{}).(*SessionIDForReturn).SessionID)
 		}
+		t.interceptor.intercept(DirectionOutbound, msg)
 		if _, err = fmt.Fprintf(w, "data: %s\n\n", msg); err != nil {
 			t.logger.Errorf("Failed to write message: %v", err)
 		}
@@ -262,7 +475,7 @@ func (t *streamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *htt
 	flusher.Flush()
 
 	go func() {
-		defer pkg.Recover()
+		defer pkg.RecoverWithLogger(t.logger, nil)
 
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
@@ -282,6 +495,7 @@ func (t *streamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *htt
 	}()
 
 	for msg := range outputMsgCh {
+		t.interceptor.intercept(DirectionOutbound, msg)
 		if _, err = fmt.Fprintf(w, "data: %s\n\n", msg); err != nil {
 			t.logger.Errorf("Failed to write message: %v", err)
 			continue
@@ -291,7 +505,7 @@ func (t *streamableHTTPServerTransport) handlePost(w http.ResponseWriter, r *htt
 }
 
 func (t *streamableHTTPServerTransport) handleGet(w http.ResponseWriter, r *http.Request) {
-	defer pkg.RecoverWithFunc(func(_ any) {
+	defer pkg.RecoverWithLogger(t.logger, func(_ any) {
 		t.writeError(w, http.StatusInternalServerError, "Internal server error")
 	})
 
@@ -305,6 +519,8 @@ func (t *streamableHTTPServerTransport) handleGet(w http.ResponseWriter, r *http
 		return
 	}
 
+	exemptFromTimeouts(r)
+
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -327,12 +543,55 @@ func (t *streamableHTTPServerTransport) handleGet(w http.ResponseWriter, r *http
 		flusher.Flush()
 		return
 	}
+
+	// With an event buffer every event carries an id, a client resuming the stream with Last-Event-ID
+	// first gets the buffered events it missed, or has to re-initialize if some were already dropped.
+	var (
+		buffer *eventBuffer
+		missed []bufferedEvent
+	)
+	if t.eventBufferSize > 0 {
+		buffer, _ = t.eventBuffers.LoadOrStore(sessionID, newEventBuffer(t.eventBufferSize))
+		if lastEventID := r.Header.Get(eventIDHeader); lastEventID != "" {
+			id, err := strconv.ParseUint(lastEventID, 10, 64)
+			ok := err == nil
+			if ok {
+				missed, ok = buffer.since(id)
+			}
+			if !ok {
+				t.writeError(w, http.StatusNotFound,
+					fmt.Sprintf("events after Last-Event-ID %s are no longer available, re-initialize the session", lastEventID))
+				return
+			}
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	flusher.Flush()
 
+	for _, event := range missed {
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", event.id, event.data); err != nil {
+			t.logger.Errorf("Failed to write message: %v", err)
+			return
+		}
+	}
+	// A message the previous stream failed to write is retried here, a client resuming with
+	// Last-Event-ID already got it among the missed events.
+	if msg, ok := t.undelivered.LoadAndDelete(sessionID); ok && (buffer == nil || r.Header.Get(eventIDHeader) == "") {
+		if !t.writeEvent(w, sessionID, buffer, msg) {
+			return
+		}
+	}
+	flusher.Flush()
+
+	// Closing the stream keeps the session and its subscriptions, notifications sent meanwhile
+	// are buffered in the session queue and delivered once the client reopens the stream.
 	for {
 		msg, err := t.sessionManager.DequeueMessageForSend(r.Context(), sessionID)
 		if err != nil {
+			if r.Context().Err() == nil {
+				t.OnSessionClosed(sessionID) // the session is gone
+			}
 			if errors.Is(err, pkg.ErrSendEOF) {
 				return
 			}
@@ -342,14 +601,31 @@ func (t *streamableHTTPServerTransport) handleGet(w http.ResponseWriter, r *http
 
 		t.logger.Debugf("Sending message: %s", string(msg))
 
-		if _, err = fmt.Fprintf(w, "data: %s\n\n", msg); err != nil {
-			t.logger.Errorf("Failed to write message: %v", err)
-			continue
+		t.interceptor.intercept(DirectionOutbound, msg)
+		if !t.writeEvent(w, sessionID, buffer, msg) {
+			return
 		}
 		flusher.Flush()
 	}
 }
 
+// writeEvent writes msg as an event of the GET stream of the session, a message that can't be written
+// is kept and retried once the client reopens the stream
+func (t *streamableHTTPServerTransport) writeEvent(w http.ResponseWriter, sessionID string, buffer *eventBuffer, msg []byte) bool {
+	var err error
+	if buffer != nil {
+		_, err = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", buffer.append(msg), msg)
+	} else {
+		_, err = fmt.Fprintf(w, "data: %s\n\n", msg)
+	}
+	if err != nil {
+		t.logger.Errorf("Failed to write message, retrying it on the next stream: %v, sessionID=%s", err, sessionID)
+		t.undelivered.Store(sessionID, msg)
+		return false
+	}
+	return true
+}
+
 func (t *streamableHTTPServerTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.Header.Get("Mcp-Session-Id")
 	if sessionID == "" {
@@ -358,6 +634,7 @@ func (t *streamableHTTPServerTransport) handleDelete(w http.ResponseWriter, r *h
 	}
 
 	t.sessionManager.CloseSession(sessionID)
+	t.OnSessionClosed(sessionID)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -368,21 +645,18 @@ func (t *streamableHTTPServerTransport) writeError(w http.ResponseWriter, code i
 		t.logger.Errorf("streamableHTTPServerTransport Error: code: %d, message: %s", code, message)
 	}
 
-	resp := protocol.NewJSONRPCErrorResponse(nil, protocol.InternalError, message)
-	bytes, err := json.Marshal(resp)
-	if err != nil {
-		t.logger.Errorf("streamableHTTPServerTransport writeError json.Marshal: %v", err)
-		return
+	format := t.errorResponseFormat
+	if format == ErrorResponseFormatDefault {
+		format = ErrorResponseFormatJSONRPC
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	if _, err := w.Write(bytes); err != nil {
-		t.logger.Errorf("streamableHTTPServerTransport writeError Write: %v", err)
+	if err := writeErrorResponse(w, format, code, message); err != nil {
+		t.logger.Errorf("streamableHTTPServerTransport writeError: %v", err)
 	}
 }
 
 func (t *streamableHTTPServerTransport) Shutdown(userCtx context.Context, serverCtx context.Context) error {
+	t.health.setReady(false)
+
 	shutdownFunc := func() {
 		<-serverCtx.Done()
 
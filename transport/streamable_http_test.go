@@ -1,8 +1,19 @@
 package transport
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/hhfgeg/go-mcp/pkg"
 )
 
 func TestStreamableHTTP(t *testing.T) {
@@ -29,3 +40,325 @@ func TestStreamableHTTP(t *testing.T) {
 
 	testTransport(t, client, svr)
 }
+
+func TestStreamableHTTPClientReconnect(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		gets        int
+		lastEventID string
+		postSession = "session"
+		expire      bool // the server no longer knows the first session
+	)
+	failStreams := true
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			mu.Lock()
+			sessionID := postSession
+			mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(sessionIDHeader, sessionID)
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+			return
+		}
+
+		mu.Lock()
+		gets++
+		n := gets
+		lastEventID = r.Header.Get(eventIDHeader)
+		fail := failStreams
+		expired := expire && r.Header.Get(sessionIDHeader) == "session"
+		mu.Unlock()
+
+		switch {
+		case expired:
+			w.WriteHeader(http.StatusNotFound)
+		case n == 1 && !expire:
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "id: 7\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/first\"}\n\n")
+		case n == 2 || fail:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "data: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/second\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	})
+
+	newClient := func(t *testing.T, maxRetries int) (ClientTransport, chan []byte, chan ReconnectEvent) {
+		httpServer := httptest.NewServer(handler)
+		t.Cleanup(httpServer.Close)
+
+		events := make(chan ReconnectEvent, 10)
+		client, err := NewStreamableHTTPClientTransport(httpServer.URL,
+			WithStreamableHTTPClientOptionReconnect(maxRetries, 10*time.Millisecond),
+			WithStreamableHTTPClientOptionReconnectHandler(func(event ReconnectEvent) { events <- event }))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTPClientTransport failed: %v", err)
+		}
+		received := make(chan []byte, 10)
+		client.SetReceiver(NewClientReceiver(func(_ context.Context, msg []byte) error {
+			received <- msg
+			return nil
+		}, func(error) {}))
+		if err = client.Start(); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+
+		// the POST assigns the session the GET stream is opened for
+		if err = client.Send(context.Background(), Message(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		<-received
+		return client, received, events
+	}
+
+	t.Run("restored", func(t *testing.T) {
+		mu.Lock()
+		gets, failStreams = 0, false
+		mu.Unlock()
+		client, received, events := newClient(t, 3)
+
+		for _, method := range []string{"notifications/first", "notifications/second"} {
+			select {
+			case msg := <-received:
+				if !strings.Contains(string(msg), method) {
+					t.Fatalf("expected %s, got %s", method, msg)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s not received", method)
+			}
+		}
+		mu.Lock()
+		if lastEventID != "7" {
+			t.Errorf("expected the stream to resume from event 7, got %q", lastEventID)
+		}
+		mu.Unlock()
+
+		for _, want := range []ReconnectEvent{{Attempt: 1}, {Attempt: 2}, {Attempt: 2, Reconnected: true}} {
+			event := <-events
+			if event.Attempt != want.Attempt || event.Reconnected != want.Reconnected || event.GaveUp {
+				t.Fatalf("expected %+v, got %+v", want, event)
+			}
+		}
+		if err := client.Send(context.Background(), Message(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)); err != nil {
+			t.Fatalf("Send after reconnect failed: %v", err)
+		}
+	})
+
+	t.Run("retry budget exhausted", func(t *testing.T) {
+		mu.Lock()
+		gets, failStreams = 0, true
+		mu.Unlock()
+		client, _, events := newClient(t, 2)
+
+		for event := range events {
+			if event.GaveUp {
+				break
+			}
+		}
+		// sends don't depend on the stream
+		if err := client.Send(context.Background(), Message(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)); err != nil {
+			t.Fatalf("Send after giving up failed: %v", err)
+		}
+	})
+
+	t.Run("session expired", func(t *testing.T) {
+		mu.Lock()
+		gets, failStreams, expire, postSession = 0, false, true, "session"
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			expire, postSession = false, "session"
+			mu.Unlock()
+		}()
+		client, received, events := newClient(t, 3)
+
+		select {
+		case event := <-events:
+			if !event.SessionExpired || !errors.Is(event.Err, pkg.ErrSessionClosed) {
+				t.Fatalf("expected the session to expire, got %+v", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("session expiry not reported")
+		}
+
+		// the client initializes a new session, the stream is reopened for it
+		mu.Lock()
+		postSession = "session2"
+		mu.Unlock()
+		if err := client.Send(context.Background(), Message(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`)); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case msg := <-received:
+				if strings.Contains(string(msg), "notifications/second") {
+					return
+				}
+			case <-timeout:
+				t.Fatal("the stream was not reopened for the new session")
+			}
+		}
+	})
+}
+
+func TestStreamableHTTPEventResumption(t *testing.T) {
+	tr, handler, err := NewStreamableHTTPServerTransportAndHandler(
+		WithStreamableHTTPServerTransportAndHandlerOptionStateMode(Stateful),
+		WithStreamableHTTPServerTransportAndHandlerOptionEventBuffer(2))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+	}
+	sessionManager := newMockSessionManager()
+	tr.SetSessionManager(sessionManager)
+	sessionID := sessionManager.CreateSession(context.Background())
+
+	httpServer := httptest.NewServer(handler.HandleMCP())
+	defer httpServer.Close()
+
+	openStream := func(lastEventID string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(sessionIDHeader, sessionID)
+		if lastEventID != "" {
+			req.Header.Set(eventIDHeader, lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	readEvents := func(scanner *bufio.Scanner, n int) []string {
+		var events []string
+		var id string
+		for len(events) < n && scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				events = append(events, id+"="+strings.TrimPrefix(line, "data: "))
+			}
+		}
+		return events
+	}
+
+	stream := openStream("")
+	scanner := bufio.NewScanner(stream.Body)
+	for _, msg := range []string{"m1", "m2", "m3"} {
+		if err = tr.Send(context.Background(), sessionID, Message(msg)); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if got := readEvents(scanner, 3); !reflect.DeepEqual(got, []string{"1=m1", "2=m2", "3=m3"}) {
+		t.Fatalf("expected events with increasing ids, got %v", got)
+	}
+	_ = stream.Body.Close()
+
+	// the client only processed event 1 before the disconnect
+	stream = openStream("1")
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("expected the stream to resume, got status %d", stream.StatusCode)
+	}
+	if got := readEvents(bufio.NewScanner(stream.Body), 2); !reflect.DeepEqual(got, []string{"2=m2", "3=m3"}) {
+		t.Fatalf("expected the missed events to be replayed, got %v", got)
+	}
+
+	// event 1 was dropped from the buffer
+	expired := openStream("0")
+	_ = expired.Body.Close()
+	if expired.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected resuming from a dropped event to require re-initialization, got status %d", expired.StatusCode)
+	}
+
+	// the session expires while the client is away, its buffer goes with it
+	_ = stream.Body.Close()
+	sessionManager.CloseSession(sessionID)
+	tr.(SessionClosedHandler).OnSessionClosed(sessionID)
+	if _, ok := tr.(*streamableHTTPServerTransport).eventBuffers.Load(sessionID); ok {
+		t.Fatal("expected the event buffer of the closed session to be released")
+	}
+}
+
+// streamWriter records a stream, opened is closed once the headers are flushed and the stream accepts messages
+type streamWriter struct {
+	*httptest.ResponseRecorder
+	opened   chan struct{}
+	once     sync.Once
+	writeErr error
+}
+
+func newStreamWriter(writeErr error) *streamWriter {
+	return &streamWriter{ResponseRecorder: httptest.NewRecorder(), opened: make(chan struct{}), writeErr: writeErr}
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if w.writeErr != nil {
+		return 0, w.writeErr
+	}
+	return w.ResponseRecorder.Write(p)
+}
+
+func (w *streamWriter) Flush() {
+	w.ResponseRecorder.Flush()
+	w.once.Do(func() { close(w.opened) })
+}
+
+func TestStreamableHTTPRetryUndeliveredMessage(t *testing.T) {
+	tr, handler, err := NewStreamableHTTPServerTransportAndHandler(
+		WithStreamableHTTPServerTransportAndHandlerOptionStateMode(Stateful))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPServerTransportAndHandler: %v", err)
+	}
+	sessionManager := newMockSessionManager()
+	tr.SetSessionManager(sessionManager)
+	sessionID := sessionManager.CreateSession(context.Background())
+
+	serveStream := func(ctx context.Context, w *streamWriter) <-chan struct{} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(sessionIDHeader, sessionID)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.HandleMCP().ServeHTTP(w, req)
+		}()
+		select {
+		case <-w.opened:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the stream was not opened")
+		}
+		return done
+	}
+
+	// the write of the message fails, the stream ends without losing it
+	done := serveStream(context.Background(), newStreamWriter(errors.New("connection reset")))
+	if err = tr.Send(context.Background(), sessionID, Message("m1")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stream to end after the failed write")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newStreamWriter(nil)
+	done = serveStream(ctx, stream)
+	if err = tr.Send(context.Background(), sessionID, Message("m2")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	cancel()
+	<-done
+	if body := stream.Body.String(); body != "data: m1\n\ndata: m2\n\n" {
+		t.Fatalf("expected the undelivered message before the next one, got %q", body)
+	}
+}
@@ -0,0 +1,48 @@
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// tlsOptions holds the TLS configuration shared by the HTTP server transports
+type tlsOptions struct {
+	tlsConfig *tls.Config
+	certFile  string
+	keyFile   string
+}
+
+func (o *tlsOptions) tlsEnabled() bool {
+	return o.tlsConfig != nil || o.certFile != "" || o.keyFile != ""
+}
+
+func (o *tlsOptions) scheme() string {
+	if o.tlsEnabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// listenAndServe serves HTTPS when TLS is configured and plaintext HTTP otherwise,
+// it fails before listening if the certificate or key can't be loaded
+func (o *tlsOptions) listenAndServe(svr *http.Server) error {
+	if !o.tlsEnabled() {
+		return svr.ListenAndServe()
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.tlsConfig != nil {
+		config = o.tlsConfig.Clone()
+	}
+	if o.certFile != "" || o.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	svr.TLSConfig = config
+
+	return svr.ListenAndServeTLS("", "")
+}
@@ -0,0 +1,114 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStreamableHTTPWithTLS(t *testing.T) {
+	certFile, keyFile, pool := writeTestCertificate(t)
+
+	port, err := getAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to get available port: %v", err)
+	}
+	serverAddr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	svr := NewStreamableHTTPServerTransport(serverAddr, WithStreamableHTTPServerTransportOptionTLSCertFile(certFile, keyFile))
+
+	client, err := NewStreamableHTTPClientTransport(fmt.Sprintf("https://%s/mcp", serverAddr),
+		WithStreamableHTTPClientOptionHTTPClient(&http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+		}))
+	if err != nil {
+		t.Fatalf("NewStreamableHTTPClientTransport failed: %v", err)
+	}
+
+	testTransport(t, client, svr)
+}
+
+func TestTLSCertFileLoadFailure(t *testing.T) {
+	port, err := getAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to get available port: %v", err)
+	}
+
+	svr, err := NewSSEServerTransport(fmt.Sprintf("127.0.0.1:%d", port),
+		WithSSEServerTransportOptionTLSCertFile(filepath.Join(t.TempDir(), "missing.crt"), filepath.Join(t.TempDir(), "missing.key")))
+	if err != nil {
+		t.Fatalf("NewSSEServerTransport failed: %v", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- svr.Run()
+	}()
+
+	select {
+	case err = <-errCh:
+		if err == nil {
+			t.Fatal("expected Run to fail when the certificate can't be loaded")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to fail fast when the certificate can't be loaded")
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and returns its files and a pool trusting it
+func writeTestCertificate(t *testing.T) (string, string, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-mcp test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
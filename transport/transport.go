@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/hhfgeg/go-mcp/pkg"
 )
@@ -82,6 +83,38 @@ type ServerTransport interface {
 	Shutdown(userCtx context.Context, serverCtx context.Context) error
 }
 
+// StreamingTransport is implemented by the server transports that can deliver each message of a request to the
+// client as soon as it is sent, over its own frame or event. The SSE, streamable HTTP and WebSocket transports
+// stream, a transport not implementing it is treated as plain request/response like stdio.
+type StreamingTransport interface {
+	SupportsStreaming() bool
+}
+
+var (
+	_ StreamingTransport = (*sseServerTransport)(nil)
+	_ StreamingTransport = (*streamableHTTPServerTransport)(nil)
+	_ StreamingTransport = (*webSocketServerTransport)(nil)
+)
+
+// SessionClosedHandler is implemented by the server transports keeping state per session, the server calls
+// OnSessionClosed once a session is closed, also when it expired from idle timeout rather than being closed
+// by the client, so that the transport releases what it keeps for the session
+type SessionClosedHandler interface {
+	OnSessionClosed(sessionID string)
+}
+
+var _ SessionClosedHandler = (*streamableHTTPServerTransport)(nil)
+
+// TraceParentKey is the context key HTTP server transports store the traceparent request header under
+type TraceParentKey struct{}
+
+func withTraceParentHeader(r *http.Request) context.Context {
+	if traceParent := r.Header.Get("traceparent"); traceParent != "" {
+		return context.WithValue(r.Context(), TraceParentKey{}, traceParent)
+	}
+	return r.Context()
+}
+
 type serverReceiver interface {
 	Receive(ctx context.Context, sessionID string, msg []byte) (<-chan []byte, error)
 }
@@ -79,6 +79,12 @@ func (m *mockSessionManager) CloseSession(sessionID string) {
 	close(ch)
 }
 
+func (m *mockSessionManager) RangeSessionIDs(f func(sessionID string) bool) {
+	m.Range(func(key string, _ chan []byte) bool {
+		return f(key)
+	})
+}
+
 func (m *mockSessionManager) CloseAllSessions() {
 	m.Range(func(key string, value chan []byte) bool {
 		m.Delete(key)
@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+const (
+	defaultWebSocketPingInterval = 30 * time.Second
+	webSocketWriteTimeout        = 10 * time.Second
+)
+
+// keepWebSocketReadDeadline expects a frame or a pong within two ping intervals
+func keepWebSocketReadDeadline(conn *websocket.Conn, pingInterval time.Duration) {
+	_ = conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+	})
+}
+
+func readWebSocketMessage(conn *websocket.Conn, pingInterval time.Duration) ([]byte, error) {
+	for {
+		typ, msg, err := conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+		if err = conn.SetReadDeadline(time.Now().Add(2 * pingInterval)); err != nil {
+			return nil, err
+		}
+		if typ == websocket.TextMessage {
+			return msg, nil
+		}
+	}
+}
+
+// writeWebSocketMessage must not be called concurrently for the same connection
+func writeWebSocketMessage(conn *websocket.Conn, msg []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+// pingWebSocket pings the peer until ctx is done, control frames may be written concurrently with messages
+func pingWebSocket(ctx context.Context, conn *websocket.Conn, interval time.Duration, logger pkg.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout)); err != nil {
+				logger.Debugf("websocket ping: %+v", err)
+				return
+			}
+		}
+	}
+}
+
+func closeWebSocket(conn *websocket.Conn) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(webSocketWriteTimeout))
+}
+
+func isWebSocketClosed(err error) bool {
+	return websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) || errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+type WebSocketClientTransportOption func(*webSocketClientTransport)
+
+func WithWebSocketClientOptionLogger(log pkg.Logger) WebSocketClientTransportOption {
+	return func(t *webSocketClientTransport) {
+		t.logger = log
+	}
+}
+
+// WithWebSocketClientOptionHeader sets extra headers of the opening handshake, e.g. Authorization
+func WithWebSocketClientOptionHeader(header http.Header) WebSocketClientTransportOption {
+	return func(t *webSocketClientTransport) {
+		t.header = header
+	}
+}
+
+// WithWebSocketClientOptionPingInterval sets how often the server is pinged, the connection is considered lost
+// when the server doesn't answer within two intervals
+func WithWebSocketClientOptionPingInterval(interval time.Duration) WebSocketClientTransportOption {
+	return func(t *webSocketClientTransport) {
+		t.pingInterval = interval
+	}
+}
+
+// WithWebSocketClientOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithWebSocketClientOptionInterceptor(interceptor Interceptor) WebSocketClientTransportOption {
+	return func(t *webSocketClientTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+type webSocketClientTransport struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	serverURL string
+	header    http.Header
+	dialer    *websocket.Dialer
+
+	conn     *websocket.Conn
+	writeMu  sync.Mutex // concurrent requests and responses are written one frame at a time
+	receiver clientReceiver
+
+	// options
+	logger       pkg.Logger
+	pingInterval time.Duration
+	interceptor  Interceptor
+
+	wg sync.WaitGroup
+}
+
+// NewWebSocketClientTransport returns transport connecting to the WebSocket server at serverURL, e.g. ws://127.0.0.1:8080/mcp
+func NewWebSocketClientTransport(serverURL string, opts ...WebSocketClientTransportOption) (ClientTransport, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &webSocketClientTransport{
+		ctx:          ctx,
+		cancel:       cancel,
+		serverURL:    serverURL,
+		dialer:       websocket.DefaultDialer,
+		logger:       pkg.DefaultLogger,
+		pingInterval: defaultWebSocketPingInterval,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
+}
+
+func (t *webSocketClientTransport) Start() error {
+	conn, resp, err := t.dialer.DialContext(t.ctx, t.serverURL, t.header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to connect to %s: status %d: %w", t.serverURL, resp.StatusCode, err)
+		}
+		return fmt.Errorf("failed to connect to %s: %w", t.serverURL, err)
+	}
+	t.conn = conn
+
+	t.wg.Add(2)
+	go func() {
+		defer pkg.RecoverWithLogger(t.logger, nil)
+		defer t.wg.Done()
+
+		t.startReceive()
+	}()
+	go func() {
+		defer pkg.RecoverWithLogger(t.logger, nil)
+		defer t.wg.Done()
+
+		pingWebSocket(t.ctx, conn, t.pingInterval, t.logger)
+	}()
+
+	return nil
+}
+
+func (t *webSocketClientTransport) Send(_ context.Context, msg Message) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.interceptor.intercept(DirectionOutbound, msg)
+	return writeWebSocketMessage(t.conn, msg)
+}
+
+func (t *webSocketClientTransport) SetReceiver(receiver clientReceiver) {
+	t.receiver = receiver
+}
+
+func (t *webSocketClientTransport) Close() error {
+	t.cancel()
+
+	if t.conn == nil {
+		return nil
+	}
+
+	closeWebSocket(t.conn)
+	err := t.conn.Close()
+
+	t.wg.Wait()
+
+	if err != nil {
+		return fmt.Errorf("failed to close connection: %w", err)
+	}
+	return nil
+}
+
+func (t *webSocketClientTransport) startReceive() {
+	keepWebSocketReadDeadline(t.conn, t.pingInterval)
+
+	for {
+		msg, err := readWebSocketMessage(t.conn, t.pingInterval)
+		if err != nil {
+			// the read fails once the transport is closed, that is not an interruption
+			if t.ctx.Err() != nil {
+				return
+			}
+			if !isWebSocketClosed(err) {
+				t.logger.Errorf("websocket read error: %+v", err)
+			}
+			t.receiver.Interrupt(fmt.Errorf("websocket read error: %w", err))
+			return
+		}
+
+		t.interceptor.intercept(DirectionInbound, msg)
+		if err = t.receiver.Receive(t.ctx, msg); err != nil {
+			t.logger.Errorf("receiver failed: %v", err)
+		}
+	}
+}
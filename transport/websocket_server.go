@@ -0,0 +1,267 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/hhfgeg/go-mcp/pkg"
+)
+
+type WebSocketServerTransportOption func(*webSocketServerTransport)
+
+func WithWebSocketServerTransportOptionLogger(logger pkg.Logger) WebSocketServerTransportOption {
+	return func(t *webSocketServerTransport) {
+		t.logger = logger
+	}
+}
+
+// WithWebSocketServerTransportOptionPingInterval sets how often connections are pinged, a connection that
+// doesn't answer within two intervals is closed
+func WithWebSocketServerTransportOptionPingInterval(interval time.Duration) WebSocketServerTransportOption {
+	return func(t *webSocketServerTransport) {
+		t.pingInterval = interval
+	}
+}
+
+// WithWebSocketServerTransportOptionCheckOrigin decides which origins may open a connection,
+// by default only same-origin requests and requests without an Origin header are accepted
+func WithWebSocketServerTransportOptionCheckOrigin(checkOrigin func(r *http.Request) bool) WebSocketServerTransportOption {
+	return func(t *webSocketServerTransport) {
+		t.upgrader.CheckOrigin = checkOrigin
+	}
+}
+
+// WithWebSocketServerTransportOptionInterceptor calls interceptor with every raw message the transport reads or writes
+func WithWebSocketServerTransportOptionInterceptor(interceptor Interceptor) WebSocketServerTransportOption {
+	return func(t *webSocketServerTransport) {
+		t.interceptor = interceptor
+	}
+}
+
+// WithWebSocketServerTransportOptionMaxMessageSize bounds the size of a single inbound message to size bytes,
+// DefaultMaxMessageSize by default. The connection of a client sending a larger message is closed,
+// a non-positive size removes the limit.
+func WithWebSocketServerTransportOptionMaxMessageSize(size int) WebSocketServerTransportOption {
+	return func(t *webSocketServerTransport) {
+		t.maxMessageSize = size
+	}
+}
+
+type webSocketServerTransport struct {
+	// ctx is canceled on shutdown to stop all ongoing send operations
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	httpSvr  *http.Server
+	upgrader websocket.Upgrader
+
+	inFlySend sync.WaitGroup
+
+	receiver serverReceiver
+
+	sessionManager sessionManager
+
+	// options
+	logger         pkg.Logger
+	path           string
+	pingInterval   time.Duration
+	interceptor    Interceptor
+	maxMessageSize int
+}
+
+// NewWebSocketServerTransport returns transport that will start an HTTP server accepting WebSocket connections on path,
+// each connection is a session and every text frame carries one JSON-RPC message
+func NewWebSocketServerTransport(addr, path string, opts ...WebSocketServerTransportOption) (ServerTransport, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &webSocketServerTransport{
+		ctx:          ctx,
+		cancel:       cancel,
+		logger:       pkg.DefaultLogger,
+		path:         path,
+		pingInterval: defaultWebSocketPingInterval,
+
+		maxMessageSize: DefaultMaxMessageSize,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(t.path, t.handleWebSocket)
+
+	t.httpSvr = &http.Server{
+		Addr:        addr,
+		Handler:     mux,
+		IdleTimeout: time.Minute,
+	}
+
+	return t, nil
+}
+
+func (t *webSocketServerTransport) Run() error {
+	fmt.Printf("starting mcp server at ws://%s%s\n", t.httpSvr.Addr, t.path)
+
+	if err := t.httpSvr.ListenAndServe(); err != nil {
+		return fmt.Errorf("failed to start HTTP server: %w", err)
+	}
+	return nil
+}
+
+func (t *webSocketServerTransport) Send(ctx context.Context, sessionID string, msg Message) error {
+	t.inFlySend.Add(1)
+	defer t.inFlySend.Done()
+
+	select {
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	default:
+		return t.sessionManager.EnqueueMessageForSend(ctx, sessionID, msg)
+	}
+}
+
+func (t *webSocketServerTransport) SetReceiver(receiver serverReceiver) {
+	t.receiver = receiver
+}
+
+func (t *webSocketServerTransport) SetSessionManager(manager sessionManager) {
+	t.sessionManager = manager
+}
+
+func (t *webSocketServerTransport) SupportsStreaming() bool {
+	return true
+}
+
+// handleWebSocket serves one connection, frames are read on this goroutine and written by a single writer
+// draining the session queue, so concurrent responses and notifications never interleave
+func (t *webSocketServerTransport) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	defer pkg.RecoverWithLogger(t.logger, nil)
+
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied with an HTTP error
+		t.logger.Errorf("webSocketServerTransport upgrade: %+v", err)
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(withTraceParentHeader(r))
+	defer cancel()
+
+	sessionID := t.sessionManager.CreateSession(ctx)
+	defer t.sessionManager.CloseSession(sessionID)
+
+	if err = t.sessionManager.OpenMessageQueueForSend(sessionID); err != nil {
+		t.logger.Errorf("handleWebSocket sessionID=%s OpenMessageQueueForSend fail: %v", sessionID, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer pkg.RecoverWithLogger(t.logger, nil)
+		defer wg.Done()
+
+		t.writeLoop(ctx, conn, sessionID)
+	}()
+	go func() {
+		defer pkg.RecoverWithLogger(t.logger, nil)
+		defer wg.Done()
+
+		pingWebSocket(ctx, conn, t.pingInterval, t.logger)
+	}()
+
+	t.readLoop(ctx, conn, sessionID)
+
+	cancel()
+	wg.Wait()
+}
+
+func (t *webSocketServerTransport) readLoop(ctx context.Context, conn *websocket.Conn, sessionID string) {
+	keepWebSocketReadDeadline(conn, t.pingInterval)
+	if t.maxMessageSize > 0 {
+		// a larger message fails the read and closes the connection
+		conn.SetReadLimit(int64(t.maxMessageSize))
+	}
+
+	for {
+		msg, err := readWebSocketMessage(conn, t.pingInterval)
+		if err != nil {
+			if !isWebSocketClosed(err) {
+				t.logger.Errorf("webSocketServerTransport read sessionID=%s: %+v", sessionID, err)
+			}
+			return
+		}
+
+		t.interceptor.intercept(DirectionInbound, msg)
+		outputMsgCh, err := t.receiver.Receive(ctx, sessionID, msg)
+		if err != nil {
+			t.logger.Errorf("receiver failed: %v", err)
+			continue
+		}
+		if outputMsgCh == nil {
+			continue
+		}
+
+		go func() {
+			defer pkg.RecoverWithLogger(t.logger, nil)
+
+			for msg := range outputMsgCh {
+				if e := t.Send(context.Background(), sessionID, msg); e != nil {
+					t.logger.Errorf("Failed to send message: %v", e)
+				}
+			}
+		}()
+	}
+}
+
+// writeLoop writes the queued messages of the session until the connection or the session is closed,
+// a closed session, e.g. on shutdown, ends the connection with a close frame
+func (t *webSocketServerTransport) writeLoop(ctx context.Context, conn *websocket.Conn, sessionID string) {
+	for {
+		msg, err := t.sessionManager.DequeueMessageForSend(ctx, sessionID)
+		if err != nil {
+			if errors.Is(err, pkg.ErrSendEOF) {
+				closeWebSocket(conn)
+				return
+			}
+			t.logger.Debugf("websocket dequeueMessage err: %+v, sessionID=%s", err.Error(), sessionID)
+			return
+		}
+
+		t.logger.Debugf("Sending message: %s", string(msg))
+
+		t.interceptor.intercept(DirectionOutbound, msg)
+		if err = writeWebSocketMessage(conn, msg); err != nil {
+			t.logger.Errorf("Failed to write message: %v", err)
+			return
+		}
+	}
+}
+
+func (t *webSocketServerTransport) Shutdown(userCtx context.Context, serverCtx context.Context) error {
+	shutdownFunc := func() {
+		<-serverCtx.Done()
+
+		t.cancel()
+
+		t.inFlySend.Wait()
+
+		t.sessionManager.CloseAllSessions()
+	}
+
+	// hijacked connections aren't tracked by the HTTP server, they are ended by closing their sessions
+	t.httpSvr.RegisterOnShutdown(shutdownFunc)
+
+	if err := t.httpSvr.Shutdown(userCtx); err != nil {
+		return fmt.Errorf("failed to shutdown HTTP server: %w", err)
+	}
+
+	return nil
+}
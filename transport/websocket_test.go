@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWebSocket(t *testing.T) {
+	port, err := getAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to get available port: %v", err)
+	}
+
+	serverAddr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	svr, err := NewWebSocketServerTransport(serverAddr, "/mcp")
+	if err != nil {
+		t.Fatalf("NewWebSocketServerTransport failed: %v", err)
+	}
+
+	client, err := NewWebSocketClientTransport(fmt.Sprintf("ws://%s/mcp", serverAddr))
+	if err != nil {
+		t.Fatalf("NewWebSocketClientTransport failed: %v", err)
+	}
+
+	testTransport(t, client, svr)
+}
+
+func TestWebSocketKeepAliveAndShutdown(t *testing.T) {
+	const pingInterval = 50 * time.Millisecond
+
+	port, err := getAvailablePort()
+	if err != nil {
+		t.Fatalf("Failed to get available port: %v", err)
+	}
+	serverAddr := fmt.Sprintf("127.0.0.1:%d", port)
+
+	svr, err := NewWebSocketServerTransport(serverAddr, "/mcp", WithWebSocketServerTransportOptionPingInterval(pingInterval))
+	if err != nil {
+		t.Fatalf("NewWebSocketServerTransport failed: %v", err)
+	}
+	svr.SetSessionManager(newMockSessionManager())
+	svr.SetReceiver(ServerReceiverF(func(_ context.Context, _ string, msg []byte) (<-chan []byte, error) {
+		msgCh := make(chan []byte, 1)
+		msgCh <- msg
+		close(msgCh)
+		return msgCh, nil
+	}))
+	go func() {
+		_ = svr.Run()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := NewWebSocketClientTransport(fmt.Sprintf("ws://%s/mcp", serverAddr), WithWebSocketClientOptionPingInterval(pingInterval))
+	if err != nil {
+		t.Fatalf("NewWebSocketClientTransport failed: %v", err)
+	}
+	received := make(chan string, 1)
+	interrupted := make(chan error, 1)
+	client.SetReceiver(NewClientReceiver(func(_ context.Context, msg []byte) error {
+		received <- string(msg)
+		return nil
+	}, func(err error) {
+		interrupted <- err
+	}))
+	if err = client.Start(); err != nil {
+		t.Fatalf("client.Start() failed: %v", err)
+	}
+	defer client.Close()
+
+	// an idle connection stays open as long as the pings are answered
+	time.Sleep(5 * pingInterval)
+	select {
+	case err = <-interrupted:
+		t.Fatalf("idle connection was closed: %v", err)
+	default:
+	}
+
+	if err = client.Send(context.Background(), Message("ping")); err != nil {
+		t.Fatalf("client.Send() failed: %v", err)
+	}
+	select {
+	case msg := <-received:
+		if msg != "ping" {
+			t.Fatalf("expected the echoed message, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+
+	serverCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = svr.Shutdown(context.Background(), serverCtx); err != nil {
+		t.Fatalf("server.Shutdown() failed: %v", err)
+	}
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second):
+		t.Fatal("client was not interrupted by the server shutdown")
+	}
+}